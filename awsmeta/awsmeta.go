@@ -0,0 +1,297 @@
+// Package awsmeta provides an envconfig source exposing the metadata of the ECS task or EC2 instance the program
+// runs on, so that fields like the region default to the ones of the platform when they aren't set:
+//
+//	source, err := awsmeta.New(awsmeta.Config{})
+//	...
+//	var conf struct {
+//		Region           string
+//		AvailabilityZone string `envconfig:"optional"`
+//	}
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{
+//		Source: envconfig.Chain{envconfig.EnvSource{}, source},
+//	})
+//
+// The metadata is read from the task metadata endpoint of ECS when ECS_CONTAINER_METADATA_URI_V4 is set,
+// and from the instance metadata service of EC2 (IMDSv2) otherwise, or when the task runs on EC2 instances.
+// Outside of AWS the endpoints can't be reached and the source has no key.
+//
+// The keys are the constants of this package, like REGION. With a prefix, set Config.Prefix to the one of
+// the options so that APP_REGION is found too.
+package awsmeta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The keys of the metadata.
+const (
+	// KeyRegion is the region, like eu-west-1.
+	KeyRegion = "REGION"
+	// KeyAvailabilityZone is the availability zone, like eu-west-1a.
+	KeyAvailabilityZone = "AVAILABILITY_ZONE"
+	// KeyTaskARN is the ARN of the ECS task.
+	KeyTaskARN = "TASK_ARN"
+	// KeyCluster is the ECS cluster of the task.
+	KeyCluster = "CLUSTER"
+	// KeyInstanceID is the ID of the EC2 instance.
+	KeyInstanceID = "INSTANCE_ID"
+	// KeyInstanceType is the type of the EC2 instance, like m5.large.
+	KeyInstanceType = "INSTANCE_TYPE"
+)
+
+// Config is the configuration of a Source.
+type Config struct {
+	// Prefix is the prefix of the keys, like the prefix of the envconfig options.
+	Prefix string
+
+	// ECSEndpoint is the task metadata endpoint of ECS, ECS_CONTAINER_METADATA_URI_V4 by default.
+	ECSEndpoint string
+	// IMDSEndpoint is the URL of the instance metadata service, AWS_EC2_METADATA_SERVICE_ENDPOINT or
+	// http://169.254.169.254 by default.
+	IMDSEndpoint string
+	// DisableIMDS disables the instance metadata service, for example when the hop limit of the instances
+	// prevents the containers from reaching it.
+	DisableIMDS bool
+
+	// Timeout bounds the requests to the endpoints, 1 second by default. The endpoints are local so
+	// a longer delay means that they can't be reached.
+	Timeout time.Duration
+
+	// HTTPClient is the client used to talk to the endpoints, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// Source exposes the metadata of the task or instance. It implements envconfig.Source, envconfig.ContextSource
+// and envconfig.Lister.
+type Source struct {
+	cfg Config
+
+	mu sync.Mutex
+	// values are the metadata by key, nil until they are read.
+	values map[string]string
+}
+
+// New returns a new source. The metadata is read on the first lookup.
+func New(cfg Config) (*Source, error) {
+	if cfg.ECSEndpoint == "" {
+		cfg.ECSEndpoint = os.Getenv("ECS_CONTAINER_METADATA_URI_V4")
+	}
+	if cfg.IMDSEndpoint == "" {
+		cfg.IMDSEndpoint = os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+	}
+	if cfg.IMDSEndpoint == "" {
+		cfg.IMDSEndpoint = "http://169.254.169.254"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Prefix = strings.ToUpper(cfg.Prefix)
+
+	return &Source{cfg: cfg}, nil
+}
+
+// String returns the name of the source used in errors.
+func (s *Source) String() string {
+	return "awsmeta"
+}
+
+// Lookup implements envconfig.Source.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, "")
+}
+
+// LookupContext implements envconfig.ContextSource, the metadata is read with ctx if it is not yet.
+func (s *Source) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	values, err := s.load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	key = strings.ToUpper(key)
+	if s.cfg.Prefix != "" {
+		key = strings.TrimPrefix(key, s.cfg.Prefix+"_")
+	}
+
+	v, ok := values[key]
+	return v, ok, nil
+}
+
+// Keys implements envconfig.Lister.
+func (s *Source) Keys() []string {
+	values, err := s.load(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if s.cfg.Prefix != "" {
+			k = s.cfg.Prefix + "_" + k
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// load returns the metadata, reading it if needed.
+func (s *Source) load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values != nil {
+		return s.values, nil
+	}
+
+	values := make(map[string]string)
+
+	onEC2 := true
+	if s.cfg.ECSEndpoint != "" {
+		launchType, err := s.readTask(ctx, values)
+		if err != nil {
+			return nil, err
+		}
+		onEC2 = launchType == "EC2"
+	}
+	if onEC2 && !s.cfg.DisableIMDS {
+		if err := s.readInstance(ctx, values); err != nil {
+			return nil, err
+		}
+	}
+
+	s.values = values
+	return values, nil
+}
+
+// readTask adds the metadata of the ECS task to values and returns its launch type.
+func (s *Source) readTask(ctx context.Context, values map[string]string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(s.cfg.ECSEndpoint, "/")+"/task", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var task struct {
+		Cluster          string
+		TaskARN          string
+		AvailabilityZone string
+		LaunchType       string
+	}
+	if err := s.do(ctx, req, &task); err != nil {
+		return "", fmt.Errorf("awsmeta: task metadata: %w", err)
+	}
+
+	set(values, KeyTaskARN, task.TaskARN)
+	set(values, KeyCluster, task.Cluster)
+	set(values, KeyAvailabilityZone, task.AvailabilityZone)
+	// arn:aws:ecs:REGION:ACCOUNT:task/...
+	if parts := strings.SplitN(task.TaskARN, ":", 6); len(parts) == 6 {
+		set(values, KeyRegion, parts[3])
+	}
+
+	return task.LaunchType, nil
+}
+
+// readInstance adds the metadata of the EC2 instance to values. The instance metadata service being
+// unreachable isn't an error: the program doesn't run on EC2.
+func (s *Source) readInstance(ctx context.Context, values map[string]string) error {
+	endpoint := strings.TrimSuffix(s.cfg.IMDSEndpoint, "/")
+
+	req, err := http.NewRequest(http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+
+	token, err := s.send(ctx, req)
+	if errors.Is(err, errUnreachable) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("awsmeta: instance metadata token: %w", err)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, endpoint+"/latest/dynamic/instance-identity/document", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+
+	var doc struct {
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+	}
+	if err := s.do(ctx, req, &doc); err != nil {
+		return fmt.Errorf("awsmeta: instance identity document: %w", err)
+	}
+
+	set(values, KeyRegion, doc.Region)
+	set(values, KeyAvailabilityZone, doc.AvailabilityZone)
+	set(values, KeyInstanceID, doc.InstanceID)
+	set(values, KeyInstanceType, doc.InstanceType)
+
+	return nil
+}
+
+// set sets the key to v if it is not empty and not set yet, the metadata of the task taking precedence.
+func set(values map[string]string, key, v string) {
+	if _, ok := values[key]; !ok && v != "" {
+		values[key] = v
+	}
+}
+
+// errUnreachable is returned by send when the endpoint can't be reached.
+var errUnreachable = errors.New("unreachable")
+
+// do sends the request and decodes the JSON response into v.
+func (s *Source) do(ctx context.Context, req *http.Request, v interface{}) error {
+	data, err := s.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+// send sends the request with the timeout and returns the body of the response.
+func (s *Source) send(ctx context.Context, req *http.Request) ([]byte, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	resp, err := s.cfg.HTTPClient.Do(req.WithContext(reqCtx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", errUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,106 @@
+package awsmeta_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/awsmeta"
+)
+
+func newIMDS(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.NotEmpty(t, r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+		w.Write([]byte("token"))
+	})
+	mux.HandleFunc("/latest/dynamic/instance-identity/document", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"region": "eu-west-1", "availabilityZone": "eu-west-1b", "instanceId": "i-1234", "instanceType": "m5.large"}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestInstance(t *testing.T) {
+	imds := newIMDS(t)
+	defer imds.Close()
+
+	source, err := awsmeta.New(awsmeta.Config{IMDSEndpoint: imds.URL, Prefix: "app"})
+	require.Nil(t, err)
+
+	var conf struct {
+		Region       string
+		InstanceType string
+		TaskARN      string `envconfig:"optional"`
+	}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{
+		Prefix: "APP",
+		Source: envconfig.Chain{envconfig.MapSource{"APP_REGION": "us-east-1"}, source},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "us-east-1", conf.Region)
+	require.Equal(t, "m5.large", conf.InstanceType)
+	require.Equal(t, "", conf.TaskARN)
+	require.Equal(t, []string{"APP_AVAILABILITY_ZONE", "APP_INSTANCE_ID", "APP_INSTANCE_TYPE", "APP_REGION"}, source.Keys())
+}
+
+func TestTask(t *testing.T) {
+	imds := newIMDS(t)
+	defer imds.Close()
+
+	launchType := "FARGATE"
+	ecs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v4/abcd/task", r.URL.Path)
+		w.Write([]byte(`{
+			"Cluster": "arn:aws:ecs:eu-west-3:123456789012:cluster/prod",
+			"TaskARN": "arn:aws:ecs:eu-west-3:123456789012:task/prod/1234",
+			"AvailabilityZone": "eu-west-3a",
+			"LaunchType": "` + launchType + `"
+		}`))
+	}))
+	defer ecs.Close()
+
+	source, err := awsmeta.New(awsmeta.Config{ECSEndpoint: ecs.URL + "/v4/abcd", IMDSEndpoint: imds.URL})
+	require.Nil(t, err)
+	require.Equal(t, []string{"AVAILABILITY_ZONE", "CLUSTER", "REGION", "TASK_ARN"}, source.Keys())
+
+	v, ok, err := source.Lookup(awsmeta.KeyRegion)
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, "eu-west-3", v)
+
+	// the tasks running on EC2 also have the metadata of their instance
+	launchType = "EC2"
+	source, err = awsmeta.New(awsmeta.Config{ECSEndpoint: ecs.URL + "/v4/abcd", IMDSEndpoint: imds.URL})
+	require.Nil(t, err)
+	require.Equal(t, []string{"AVAILABILITY_ZONE", "CLUSTER", "INSTANCE_ID", "INSTANCE_TYPE", "REGION", "TASK_ARN"}, source.Keys())
+
+	v, _, err = source.Lookup("availability_zone")
+	require.Nil(t, err)
+	require.Equal(t, "eu-west-3a", v)
+}
+
+func TestUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	source, err := awsmeta.New(awsmeta.Config{IMDSEndpoint: srv.URL})
+	require.Nil(t, err)
+
+	_, ok, err := source.Lookup(awsmeta.KeyRegion)
+	require.Nil(t, err)
+	require.False(t, ok)
+
+	source, err = awsmeta.New(awsmeta.Config{ECSEndpoint: srv.URL, DisableIMDS: true})
+	require.Nil(t, err)
+
+	_, _, err = source.Lookup(awsmeta.KeyRegion)
+	require.Contains(t, err.Error(), "awsmeta: task metadata: unreachable")
+}
@@ -0,0 +1,210 @@
+package envconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/vrischmann/envconfig/internal/lifecycle"
+)
+
+// CacheOptions are the options of a Cache.
+type CacheOptions struct {
+	// TTL is how long a value is cached. The values are cached until they are invalidated if it is zero.
+	TTL time.Duration
+
+	// KeyTTL returns the TTL of a key, overriding TTL if it is not zero.
+	KeyTTL func(key string) time.Duration
+
+	// StaleWhileRevalidate is how long an expired value is still returned while it is read again in the background.
+	// The expired values are read again before being returned if it is zero, or once the cache is shut down.
+	StaleWhileRevalidate time.Duration
+}
+
+// Cache is a source caching the values of another source, so that repeated calls to Init or the reloads of a Store
+// don't read each key from a remote backend again. The unset keys are cached too, the errors are not.
+// It implements Source, FieldSource, ContextSource and HealthChecker, and Lister if the cached source does.
+//
+// Call Shutdown to stop the reads in the background of StaleWhileRevalidate.
+type Cache struct {
+	source Source
+	opts   CacheOptions
+
+	// lifecycle tracks the revalidations in the background.
+	lifecycle lifecycle.Lifecycle
+
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+	// revalidateErr is the error of the last revalidation, nil if it succeeded.
+	revalidateErr error
+}
+
+// cacheKey identifies a cached value, the same key can be read differently for the struct tags of different fields.
+type cacheKey struct {
+	key string
+	tag reflect.StructTag
+}
+
+// cacheEntry is a value read from the source, found is false if the key is not set.
+type cacheEntry struct {
+	value        string
+	found        bool
+	fetched      time.Time
+	revalidating bool
+}
+
+// NewCache returns a new cache of source.
+func NewCache(source Source, opts CacheOptions) *Cache {
+	return &Cache{
+		source:  source,
+		opts:    opts,
+		entries: make(map[cacheKey]*cacheEntry),
+	}
+}
+
+// String returns the name of the source used in errors.
+func (c *Cache) String() string {
+	return "cache(" + sourceName(c.source) + ")"
+}
+
+// Lookup implements Source.
+func (c *Cache) Lookup(key string) (string, bool, error) {
+	return c.LookupContext(context.Background(), key, "")
+}
+
+// LookupField implements FieldSource, the tag is passed to the cached source if it implements FieldSource.
+func (c *Cache) LookupField(key string, tag reflect.StructTag) (string, bool, error) {
+	return c.LookupContext(context.Background(), key, tag)
+}
+
+// LookupContext implements ContextSource, the context is passed to the cached source if it implements ContextSource.
+// An expired value within StaleWhileRevalidate is returned at once and read again in the background,
+// without ctx since the read outlives the lookup. The read is canceled by Shutdown.
+func (c *Cache) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	k := cacheKey{key: key, tag: tag}
+	ttl := c.ttl(key)
+
+	c.mu.Lock()
+	entry, ok := c.entries[k]
+	if ok {
+		age := time.Since(entry.fetched)
+		switch {
+		case ttl == 0 || age < ttl:
+			c.mu.Unlock()
+			return entry.value, entry.found, nil
+		case age < ttl+c.opts.StaleWhileRevalidate:
+			if entry.revalidating {
+				c.mu.Unlock()
+				return entry.value, entry.found, nil
+			}
+			revalidateCtx, release, err := c.lifecycle.Start(context.Background())
+			if err == nil {
+				entry.revalidating = true
+				go c.revalidate(revalidateCtx, release, k, entry)
+				c.mu.Unlock()
+				return entry.value, entry.found, nil
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	return c.fetch(ctx, k)
+}
+
+// Keys implements Lister, it returns the keys of the cached source if it implements Lister. The keys are not cached.
+func (c *Cache) Keys() []string {
+	if lister, ok := c.source.(Lister); ok {
+		return lister.Keys()
+	}
+	return nil
+}
+
+// Health implements HealthChecker, it returns the error of the last revalidation in the background, if it failed,
+// or else the health of the cached source.
+func (c *Cache) Health() error {
+	c.mu.Lock()
+	err := c.revalidateErr
+	c.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("revalidation failed: %w", err)
+	}
+	return SourceHealth(c.source)
+}
+
+// Invalidate removes the keys from the cache, or all the keys if there are none, so that they are read again.
+func (c *Cache) Invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(keys) == 0 {
+		c.entries = make(map[cacheKey]*cacheEntry)
+		return
+	}
+
+	invalid := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		invalid[key] = struct{}{}
+	}
+	for k := range c.entries {
+		if _, ok := invalid[k.key]; ok {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// ttl returns the TTL of key.
+func (c *Cache) ttl(key string) time.Duration {
+	if c.opts.KeyTTL != nil {
+		if ttl := c.opts.KeyTTL(key); ttl != 0 {
+			return ttl
+		}
+	}
+	return c.opts.TTL
+}
+
+// fetch reads the key from the source and caches it.
+func (c *Cache) fetch(ctx context.Context, k cacheKey) (string, bool, error) {
+	value, found, err := sourceLookup(ctx, c.source, k.tag)(k.key)
+	if err != nil {
+		return "", false, err
+	}
+
+	c.mu.Lock()
+	c.entries[k] = &cacheEntry{value: value, found: found, fetched: time.Now()}
+	c.mu.Unlock()
+
+	return value, found, nil
+}
+
+// revalidate reads again the key of the expired entry with ctx, the context of the lifecycle, and calls release
+// when it is done. The entry is kept on error, so that it is read again by the next lookup once it is no longer stale.
+func (c *Cache) revalidate(ctx context.Context, release func(), k cacheKey, entry *cacheEntry) {
+	defer release()
+	value, found, err := sourceLookup(ctx, c.source, k.tag)(k.key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		entry.revalidating = false
+		// a revalidation canceled by Shutdown didn't fail
+		if ctx.Err() == nil {
+			c.revalidateErr = err
+		}
+		return
+	}
+	c.revalidateErr = nil
+	// the entry may have been invalidated or replaced meanwhile
+	if c.entries[k] == entry {
+		c.entries[k] = &cacheEntry{value: value, found: found, fetched: time.Now()}
+	}
+}
+
+// Shutdown cancels the reads in the background and waits for them to return, or for ctx to be done.
+// The cache keeps serving the values it read, the expired values are read again before being returned.
+func (c *Cache) Shutdown(ctx context.Context) error {
+	return c.lifecycle.Shutdown(ctx)
+}
@@ -0,0 +1,238 @@
+package envconfig_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+// countingSource is a MapSource counting the lookups of each key.
+type countingSource struct {
+	mu      sync.Mutex
+	values  envconfig.MapSource
+	lookups map[string]int
+}
+
+func newCountingSource(values envconfig.MapSource) *countingSource {
+	return &countingSource{values: values, lookups: make(map[string]int)}
+}
+
+func (s *countingSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups[key]++
+	return s.values.Lookup(key)
+}
+
+func (s *countingSource) set(key, value string) {
+	s.mu.Lock()
+	s.values[key] = value
+	s.mu.Unlock()
+}
+
+func (s *countingSource) count(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookups[key]
+}
+
+func TestCache(t *testing.T) {
+	var conf struct {
+		Name string
+		Port int `envconfig:"optional"`
+	}
+
+	source := newCountingSource(envconfig.MapSource{"NAME": "foobar"})
+	cache := envconfig.NewCache(source, envconfig.CacheOptions{})
+	opts := envconfig.Options{Source: cache}
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, envconfig.InitWithOptions(&conf, opts))
+		require.Equal(t, "foobar", conf.Name)
+	}
+	require.Equal(t, 1, source.count("NAME"))
+	// the unset keys are cached too
+	require.Equal(t, 1, source.count("PORT"))
+
+	source.set("NAME", "barbaz")
+	cache.Invalidate("NAME")
+
+	require.Nil(t, envconfig.InitWithOptions(&conf, opts))
+	require.Equal(t, "barbaz", conf.Name)
+	require.Equal(t, 2, source.count("NAME"))
+	require.Equal(t, 1, source.count("PORT"))
+
+	cache.Invalidate()
+
+	require.Nil(t, envconfig.InitWithOptions(&conf, opts))
+	require.Equal(t, 3, source.count("NAME"))
+	require.Equal(t, 2, source.count("PORT"))
+}
+
+func TestCacheTTL(t *testing.T) {
+	source := newCountingSource(envconfig.MapSource{"NAME": "foobar", "PORT": "80"})
+	cache := envconfig.NewCache(source, envconfig.CacheOptions{
+		TTL: 50 * time.Millisecond,
+		KeyTTL: func(key string) time.Duration {
+			if key == "PORT" {
+				return time.Hour
+			}
+			return 0
+		},
+	})
+
+	for _, key := range []string{"NAME", "PORT", "NAME", "PORT"} {
+		_, _, err := cache.Lookup(key)
+		require.Nil(t, err)
+	}
+	require.Equal(t, 1, source.count("NAME"))
+	require.Equal(t, 1, source.count("PORT"))
+
+	time.Sleep(100 * time.Millisecond)
+	source.set("NAME", "barbaz")
+
+	value, _, err := cache.Lookup("NAME")
+	require.Nil(t, err)
+	require.Equal(t, "barbaz", value)
+	require.Equal(t, 2, source.count("NAME"))
+
+	_, _, err = cache.Lookup("PORT")
+	require.Nil(t, err)
+	require.Equal(t, 1, source.count("PORT"))
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	source := newCountingSource(envconfig.MapSource{"NAME": "foobar"})
+	cache := envconfig.NewCache(source, envconfig.CacheOptions{
+		TTL:                  50 * time.Millisecond,
+		StaleWhileRevalidate: time.Hour,
+	})
+
+	_, _, err := cache.Lookup("NAME")
+	require.Nil(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	source.set("NAME", "barbaz")
+
+	// the stale value is returned while it is read again
+	value, ok, err := cache.Lookup("NAME")
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, "foobar", value)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		value, _, err = cache.Lookup("NAME")
+		require.Nil(t, err)
+		if value == "barbaz" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the value was not revalidated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, 2, source.count("NAME"))
+}
+
+func TestCacheShutdown(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	source := &blockingSource{
+		MapSource: envconfig.MapSource{"NAME": "foobar"},
+		block:     make(chan struct{}),
+		blocked:   make(chan struct{}, 1),
+	}
+	close(source.block)
+	cache := envconfig.NewCache(source, envconfig.CacheOptions{
+		TTL:                  10 * time.Millisecond,
+		StaleWhileRevalidate: time.Hour,
+	})
+
+	_, _, err := cache.Lookup("NAME")
+	require.Nil(t, err)
+
+	// the revalidation blocks until it is canceled
+	source.block = make(chan struct{})
+	time.Sleep(20 * time.Millisecond)
+	value, _, err := cache.Lookup("NAME")
+	require.Nil(t, err)
+	require.Equal(t, "foobar", value)
+	<-source.blocked
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.Nil(t, cache.Shutdown(ctx))
+	require.Nil(t, cache.Health())
+	requireNoLeak(t, goroutines)
+
+	// the expired value is read again before being returned
+	close(source.block)
+	source.MapSource["NAME"] = "barbaz"
+	value, _, err = cache.Lookup("NAME")
+	require.Nil(t, err)
+	require.Equal(t, "barbaz", value)
+	requireNoLeak(t, goroutines)
+}
+
+func TestCacheError(t *testing.T) {
+	cache := envconfig.NewCache(failingSource{}, envconfig.CacheOptions{})
+
+	var conf struct {
+		Name string
+	}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: cache})
+	require.Equal(t, "envconfig: unable to look up NAME (field Name): unreachable", err.Error())
+}
+
+// switchSource is a MapSource which fails while err is set.
+type switchSource struct {
+	mu     sync.Mutex
+	values envconfig.MapSource
+	err    error
+}
+
+func (s *switchSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", false, s.err
+	}
+	return s.values.Lookup(key)
+}
+
+func (s *switchSource) fail(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+func TestCacheHealth(t *testing.T) {
+	source := &switchSource{values: envconfig.MapSource{"NAME": "foobar"}}
+	cache := envconfig.NewCache(source, envconfig.CacheOptions{
+		TTL:                  10 * time.Millisecond,
+		StaleWhileRevalidate: time.Hour,
+	})
+
+	_, _, err := cache.Lookup("NAME")
+	require.Nil(t, err)
+	require.Nil(t, cache.Health())
+
+	source.fail(errUnreachable)
+	time.Sleep(20 * time.Millisecond)
+	_, _, err = cache.Lookup("NAME")
+	require.Nil(t, err)
+	require.Eventually(t, func() bool { return cache.Health() != nil }, time.Second, time.Millisecond)
+	require.Equal(t, "revalidation failed: unreachable", cache.Health().Error())
+
+	source.fail(nil)
+	require.Eventually(t, func() bool {
+		cache.Lookup("NAME")
+		return cache.Health() == nil
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,218 @@
+package envconfig
+
+import "sync"
+
+// OverflowPolicy decides what happens when a change is notified to a subscriber whose channel is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest drops the oldest change of the channel to make room for the new one. It is the default.
+	DropOldest OverflowPolicy = iota
+	// Coalesce keeps at most one change in the channel, the latest: a new change replaces the pending one.
+	Coalesce
+	// Block waits for the subscriber to receive the change. A slow subscriber then slows down the reloads,
+	// including Watch.
+	Block
+)
+
+// Change is the notification of a new configuration of a Store.
+type Change[T any] struct {
+	// Generation is the generation of the configuration, see StoreStats.
+	Generation uint64
+	// Config is the new configuration. It must not be modified.
+	Config *T
+	// Fields are the fields which differ from the previous configuration, see Diff.
+	// For a subscription to some fields, they are the fields which differ among these.
+	Fields []FieldChange
+	// Dropped is the number of changes dropped or replaced by the overflow policy since the last change sent,
+	// because the subscriber was too slow. The generations of the dropped changes are missing.
+	Dropped int
+}
+
+// Subscribe returns a channel receiving the changes of the configuration, with a buffer of size changes,
+// and a function to cancel the subscription. The policy decides what happens when the channel is full:
+// with DropOldest and Coalesce, a slow subscriber never slows down the reloads but the latest change is
+// always delivered.
+//
+// The channel is closed when the subscription is canceled or when the store is shut down.
+func (s *Store[T]) Subscribe(size int, policy OverflowPolicy) (changes <-chan Change[T], cancel func()) {
+	return s.SubscribeFields(size, policy)
+}
+
+// SubscribeFields is like Subscribe, but the channel only receives the changes of the fields under the paths,
+// with only these fields in Change.Fields. A path selects a field, like Log.Level, or all the fields of a struct,
+// like Log. A subsystem can then subscribe to the part of the configuration it cares about:
+//
+//	changes, cancel := store.SubscribeFields(1, envconfig.Coalesce, "Log")
+func (s *Store[T]) SubscribeFields(size int, policy OverflowPolicy, paths ...string) (changes <-chan Change[T], cancel func()) {
+	if size < 1 || policy == Coalesce {
+		size = 1
+	}
+
+	sub := &subscription[T]{
+		policy: policy,
+		paths:  paths,
+		ch:     make(chan Change[T], size),
+		done:   make(chan struct{}),
+	}
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if s.closed {
+		sub.close()
+		return sub.ch, func() {}
+	}
+
+	s.subscriptions = append(s.subscriptions, sub)
+
+	return sub.ch, func() {
+		s.unsubscribe(sub)
+		sub.close()
+	}
+}
+
+// unsubscribe removes a subscription. The slice is copied since notify iterates over it without the lock.
+func (s *Store[T]) unsubscribe(sub *subscription[T]) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	subscriptions := make([]*subscription[T], 0, len(s.subscriptions))
+	for _, other := range s.subscriptions {
+		if other != sub {
+			subscriptions = append(subscriptions, other)
+		}
+	}
+	s.subscriptions = subscriptions
+}
+
+// OnFieldChange registers fn to be called when the value of the field at path changes, with its old and new values
+// formatted like Marshal does, and returns a function to unregister it. For a struct, fn is called for each field of
+// the struct which changed. The values of the secret fields are redacted, see FieldChange.
+//
+//	cancel := store.OnFieldChange("Log.Level", func(old, new string) { logger.SetLevel(new) })
+//
+// fn is called by the reload which changed the field, so it must not block, and the changes are reported in order.
+// The functions are unregistered when the store is shut down.
+func (s *Store[T]) OnFieldChange(path string, fn func(old, new string)) (cancel func()) {
+	hook := &fieldHook{path: path, fn: fn}
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	if s.closed {
+		return func() {}
+	}
+
+	s.fieldHooks = append(s.fieldHooks, hook)
+
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+
+		// the slice is copied since notify iterates over it without the lock
+		hooks := make([]*fieldHook, 0, len(s.fieldHooks))
+		for _, other := range s.fieldHooks {
+			if other != hook {
+				hooks = append(hooks, other)
+			}
+		}
+		s.fieldHooks = hooks
+	}
+}
+
+// fieldHook is a function registered with OnFieldChange.
+type fieldHook struct {
+	path string
+	fn   func(old, new string)
+}
+
+// notify sends a change to the subscribers and calls the functions of the fields which changed,
+// notifyMu must be locked.
+func (s *Store[T]) notify(change Change[T]) {
+	s.subMu.Lock()
+	subscriptions := s.subscriptions
+	hooks := s.fieldHooks
+	s.subMu.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.notify(change)
+	}
+	for _, hook := range hooks {
+		for _, field := range underPaths(change.Fields, []string{hook.path}) {
+			hook.fn(field.Old, field.New)
+		}
+	}
+}
+
+// subscription is the subscription to the changes of a store.
+type subscription[T any] struct {
+	policy OverflowPolicy
+	// paths are the paths of the fields subscribed to, all of them if it is empty.
+	paths []string
+
+	// mu serializes the notifications and protects dropped and ch against close.
+	mu      sync.Mutex
+	ch      chan Change[T]
+	dropped int
+
+	// done is closed by close to abort a blocked notification.
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// notify sends a change according to the overflow policy.
+func (s *subscription[T]) notify(change Change[T]) {
+	if len(s.paths) > 0 {
+		change.Fields = underPaths(change.Fields, s.paths)
+		if len(change.Fields) == 0 {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	change.Dropped = s.dropped
+	if s.policy == Block {
+		select {
+		case s.ch <- change:
+		case <-s.done:
+		}
+		return
+	}
+
+	for {
+		select {
+		case s.ch <- change:
+			s.dropped = 0
+			return
+		default:
+		}
+
+		// the channel is full: drop the oldest change, unless the subscriber received it in the meantime
+		select {
+		case old := <-s.ch:
+			s.dropped += old.Dropped + 1
+			change.Dropped = s.dropped
+		default:
+		}
+	}
+}
+
+// close closes the channel, aborting a blocked notification.
+func (s *subscription[T]) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		close(s.ch)
+	})
+}
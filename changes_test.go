@@ -0,0 +1,234 @@
+package envconfig_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func newTestStore(t *testing.T) *envconfig.Store[storeConfig] {
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: envconfig.MapSource{"NAME": "foo", "PORT": "80"}},
+	})
+	require.Nil(t, err)
+	return store
+}
+
+func receive(t *testing.T, changes <-chan envconfig.Change[storeConfig]) (uint64, int) {
+	select {
+	case change := <-changes:
+		require.Equal(t, &storeConfig{Name: "foo", Port: 80}, change.Config)
+		return change.Generation, change.Dropped
+	case <-time.After(time.Second):
+		t.Fatal("no change")
+		return 0, 0
+	}
+}
+
+func TestSubscribeDropOldest(t *testing.T) {
+	store := newTestStore(t)
+
+	changes, cancel := store.Subscribe(2, envconfig.DropOldest)
+	for i := 0; i < 4; i++ {
+		require.Nil(t, store.Reload())
+	}
+
+	// generations 2 and 3 are dropped
+	generation, dropped := receive(t, changes)
+	require.Equal(t, uint64(4), generation)
+	require.Equal(t, 1, dropped)
+	generation, dropped = receive(t, changes)
+	require.Equal(t, uint64(5), generation)
+	require.Equal(t, 1, dropped)
+
+	require.Nil(t, store.Reload())
+	generation, dropped = receive(t, changes)
+	require.Equal(t, uint64(6), generation)
+	require.Equal(t, 0, dropped)
+
+	cancel()
+	_, ok := <-changes
+	require.False(t, ok)
+	require.Nil(t, store.Reload())
+}
+
+func TestSubscribeCoalesce(t *testing.T) {
+	store := newTestStore(t)
+
+	changes, cancel := store.Subscribe(10, envconfig.Coalesce)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		require.Nil(t, store.Reload())
+	}
+
+	generation, dropped := receive(t, changes)
+	require.Equal(t, uint64(4), generation)
+	require.Equal(t, 2, dropped)
+
+	select {
+	case <-changes:
+		t.Fatal("unexpected change")
+	default:
+	}
+}
+
+func TestSubscribeBlock(t *testing.T) {
+	store := newTestStore(t)
+
+	changes, cancel := store.Subscribe(1, envconfig.Block)
+
+	require.Nil(t, store.Reload())
+	reloaded := make(chan error)
+	go func() { reloaded <- store.Reload() }()
+
+	// the second reload waits for the first change to be received
+	select {
+	case <-reloaded:
+		t.Fatal("Reload didn't block")
+	case <-time.After(10 * time.Millisecond):
+	}
+	generation, _ := receive(t, changes)
+	require.Equal(t, uint64(2), generation)
+	require.Nil(t, <-reloaded)
+
+	// cancelling the subscription unblocks the reloads
+	go func() { reloaded <- store.Reload() }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.Nil(t, <-reloaded)
+}
+
+func TestSubscribeShutdown(t *testing.T) {
+	store := newTestStore(t)
+
+	changes, _ := store.Subscribe(1, envconfig.DropOldest)
+	require.Nil(t, store.Shutdown(context.Background()))
+
+	_, ok := <-changes
+	require.False(t, ok)
+
+	changes, _ = store.Subscribe(1, envconfig.DropOldest)
+	_, ok = <-changes
+	require.False(t, ok)
+}
+
+func TestSubscribeConcurrentReloads(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, policy := range []envconfig.OverflowPolicy{envconfig.DropOldest, envconfig.Coalesce, envconfig.Block} {
+		changes, cancel := store.Subscribe(2, policy)
+
+		received := make(chan []uint64)
+		go func() {
+			var generations []uint64
+			for change := range changes {
+				generations = append(generations, change.Generation)
+			}
+			received <- generations
+		}()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					require.Nil(t, store.Reload())
+				}
+			}()
+		}
+		wg.Wait()
+		cancel()
+
+		// the generations are received in order, and the last one is never dropped
+		generations := <-received
+		require.NotEmpty(t, generations)
+		for i := 1; i < len(generations); i++ {
+			require.Less(t, generations[i-1], generations[i])
+		}
+		require.Equal(t, store.Stats().Generation, generations[len(generations)-1])
+	}
+}
+
+func TestSubscribeFields(t *testing.T) {
+	type config struct {
+		Log struct {
+			Level  string
+			Format string `envconfig:"optional"`
+		}
+		Port int
+	}
+
+	source := envconfig.MapSource{"LOG_LEVEL": "info", "PORT": "80"}
+	store, err := envconfig.NewStore[config](envconfig.StoreOptions{Options: envconfig.Options{Source: source}})
+	require.Nil(t, err)
+
+	all, cancelAll := store.Subscribe(10, envconfig.DropOldest)
+	defer cancelAll()
+	logs, cancelLogs := store.SubscribeFields(10, envconfig.DropOldest, "Log")
+	defer cancelLogs()
+
+	source["PORT"] = "8080"
+	require.Nil(t, store.Reload())
+	source["LOG_LEVEL"] = "debug"
+	source["PORT"] = "80"
+	require.Nil(t, store.Reload())
+
+	change := <-all
+	require.Equal(t, []envconfig.FieldChange{{Path: "Port", Key: "PORT", Old: "80", New: "8080"}}, change.Fields)
+	change = <-all
+	require.Len(t, change.Fields, 2)
+
+	// the change of the port is not received
+	change = <-logs
+	require.Equal(t, uint64(3), change.Generation)
+	require.Equal(t, []envconfig.FieldChange{{Path: "Log.Level", Key: "LOG_LEVEL", Old: "info", New: "debug"}}, change.Fields)
+	require.Equal(t, "debug", change.Config.Log.Level)
+	require.Equal(t, 0, len(logs))
+}
+
+func TestOnFieldChange(t *testing.T) {
+	type config struct {
+		Log struct {
+			Level  string
+			Format string `envconfig:"optional"`
+		}
+		Password string `envconfig:",secret"`
+	}
+
+	source := envconfig.MapSource{"LOG_LEVEL": "info", "PASSWORD": "foo"}
+	store, err := envconfig.NewStore[config](envconfig.StoreOptions{Options: envconfig.Options{Source: source}})
+	require.Nil(t, err)
+
+	var levels, logs, passwords []string
+	store.OnFieldChange("Log.Level", func(old, new string) { levels = append(levels, old+"->"+new) })
+	cancel := store.OnFieldChange("Log", func(old, new string) { logs = append(logs, old+"->"+new) })
+	store.OnFieldChange("Password", func(old, new string) { passwords = append(passwords, old+"->"+new) })
+
+	source["LOG_LEVEL"] = "debug"
+	source["LOG_FORMAT"] = "json"
+	require.Nil(t, store.Reload())
+	require.Nil(t, store.Reload())
+
+	require.Equal(t, []string{"info->debug"}, levels)
+	require.Equal(t, []string{"->json", "info->debug"}, logs)
+	require.Empty(t, passwords)
+
+	cancel()
+	source["LOG_LEVEL"] = "warn"
+	source["PASSWORD"] = "bar"
+	require.Nil(t, store.Reload())
+
+	require.Equal(t, []string{"info->debug", "debug->warn"}, levels)
+	require.Len(t, logs, 2)
+	// the values of the secret fields are redacted
+	require.Equal(t, []string{"->"}, passwords)
+
+	require.Nil(t, store.Shutdown(context.Background()))
+	store.OnFieldChange("Log.Level", func(old, new string) { t.Fatal("called after the shutdown") })
+}
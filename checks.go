@@ -0,0 +1,129 @@
+package envconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Check is the outcome of a validator or a probe run by Init.
+type Check struct {
+	// Name is the name of the check: the names of the validators of a field, like iso3166, the name of a probe,
+	// like tcp, or validate for a struct implementing Validator.
+	Name string
+	// Field is the path of the field in the config struct, it is empty for the config struct itself.
+	Field string
+	// Key is the key the value was read from. It is empty for a struct or a default value.
+	Key      string
+	Duration time.Duration
+	// Err is the error of the check, nil if it passed.
+	Err error
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c Check) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Name     string `json:"name"`
+		Field    string `json:"field,omitempty"`
+		Key      string `json:"key,omitempty"`
+		Duration string `json:"duration"`
+		OK       bool   `json:"ok"`
+		Error    string `json:"error,omitempty"`
+	}{
+		Name:     c.Name,
+		Field:    c.Field,
+		Key:      c.Key,
+		Duration: c.Duration.String(),
+		OK:       c.Err == nil,
+	}
+	if c.Err != nil {
+		v.Error = c.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+func (c Check) String() string {
+	target := c.Field
+	if c.Key != "" {
+		target = c.Key
+	}
+	if target == "" {
+		target = "config"
+	}
+
+	if c.Err != nil {
+		return fmt.Sprintf("%s %s: FAIL in %s: %v", c.Name, target, c.Duration, c.Err)
+	}
+	return fmt.Sprintf("%s %s: OK in %s", c.Name, target, c.Duration)
+}
+
+// Checks are the outcomes of the validators and the probes run by Init, see InitWithChecks.
+type Checks []Check
+
+// OK returns true if all the checks passed.
+func (c Checks) OK() bool {
+	for _, check := range c {
+		if check.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks which failed.
+func (c Checks) Failed() Checks {
+	var res Checks
+	for _, check := range c {
+		if check.Err != nil {
+			res = append(res, check)
+		}
+	}
+	return res
+}
+
+// Err returns the errors of the checks which failed joined, or nil if they all passed.
+func (c Checks) Err() error {
+	return joinErrors(c.errors())
+}
+
+func (c Checks) errors() []error {
+	var errs []error
+	for _, check := range c {
+		if check.Err != nil {
+			errs = append(errs, check.Err)
+		}
+	}
+	return errs
+}
+
+// String returns a line for each check, for startup logs.
+func (c Checks) String() string {
+	lines := make([]string, len(c))
+	for i, check := range c {
+		lines[i] = check.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ServeHTTP serves the checks as JSON, with the status 503 Service Unavailable if a check failed.
+// It makes the checks of the last Init usable as a readiness endpoint.
+func (c Checks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusOK
+	if !c.OK() {
+		status = http.StatusServiceUnavailable
+	}
+
+	checks := c
+	if checks == nil {
+		checks = Checks{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		OK     bool   `json:"ok"`
+		Checks Checks `json:"checks"`
+	}{c.OK(), checks})
+}
@@ -0,0 +1,59 @@
+package envconfig_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestInitWithChecks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var conf struct {
+		Country string `envconfig:",iso3166"`
+		API     string `envconfig:"probe=http"`
+		Name    string
+	}
+
+	source := envconfig.MapSource{"COUNTRY": "fr", "API": srv.URL, "NAME": "foobar"}
+	checks, err := envconfig.InitWithChecks(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.True(t, checks.OK())
+	require.Len(t, checks, 2)
+	require.Equal(t, "iso3166", checks[0].Name)
+	require.Equal(t, "COUNTRY", checks[0].Key)
+	require.Equal(t, "http", checks[1].Name)
+	require.Equal(t, "API", checks[1].Key)
+
+	source["COUNTRY"] = "XX"
+	checks, err = envconfig.InitWithChecks(&conf, envconfig.Options{Source: source})
+	require.NotNil(t, err)
+	require.False(t, checks.OK())
+	require.Len(t, checks.Failed(), 1)
+	require.Equal(t, err, checks.Err())
+
+	rec := httptest.NewRecorder()
+	checks.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var resp struct {
+		OK     bool
+		Checks []struct {
+			Name  string
+			Key   string
+			OK    bool
+			Error string
+		}
+	}
+	require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.False(t, resp.OK)
+	require.Equal(t, "iso3166", resp.Checks[0].Name)
+	require.False(t, resp.Checks[0].OK)
+	require.Equal(t, err.Error(), resp.Checks[0].Error)
+	require.True(t, resp.Checks[1].OK)
+}
@@ -0,0 +1,308 @@
+// Package consul provides an envconfig source reading the values from the KV store of HashiCorp Consul.
+//
+// The keys are mapped to paths under Config.Prefix: the key MYSQL_MASTER_ADDRESS is read from
+// config/myapp/mysql/master/address with the prefix config/myapp:
+//
+//	source, err := consul.New(consul.Config{Prefix: "config/myapp"})
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+//
+// All the values under the prefix are read with a single request and kept by the source. Watch uses
+// blocking queries to detect changes, for example to reload a store:
+//
+//	go source.Watch(ctx, func() { store.Reload() })
+//
+// Shutdown stops the calls to Watch and waits for them to return, when the application stops.
+// Health returns the error which stopped the last call to Watch, for the health endpoint of the application.
+//
+// The source only talks to the HTTP API of Consul, it doesn't depend on the Consul client.
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/internal/lifecycle"
+)
+
+// Config is the configuration of a Source.
+type Config struct {
+	// Address is the address of the Consul agent, CONSUL_HTTP_ADDR or http://127.0.0.1:8500 by default.
+	Address string
+	// Token is the ACL token, CONSUL_HTTP_TOKEN by default.
+	Token string
+	// Datacenter is the datacenter to read from, the one of the agent by default.
+	Datacenter string
+
+	// Prefix is the path under which the keys are read, like config/myapp.
+	Prefix string
+
+	// WaitTime is the maximum duration of a blocking query of Watch, 5 minutes by default.
+	WaitTime time.Duration
+
+	// HTTPClient is the client used to talk to Consul, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// Source reads the values from Consul KV. It implements envconfig.Source, envconfig.ContextSource and envconfig.Lister.
+type Source struct {
+	cfg Config
+
+	mu sync.Mutex
+	// values are the values under the prefix by key, nil until they are read.
+	values map[string]string
+	index  uint64
+
+	// lifecycle tracks the calls to Watch.
+	lifecycle lifecycle.Lifecycle
+}
+
+// New returns a new source. The values are read on the first lookup.
+func New(cfg Config) (*Source, error) {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if cfg.Address == "" {
+		cfg.Address = "http://127.0.0.1:8500"
+	}
+	if !strings.Contains(cfg.Address, "://") {
+		cfg.Address = "http://" + cfg.Address
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if cfg.WaitTime == 0 {
+		cfg.WaitTime = 5 * time.Minute
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	// the prefix ends with a slash so that config/myapp doesn't match config/myapp2
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	if cfg.Prefix != "" {
+		cfg.Prefix += "/"
+	}
+
+	return &Source{cfg: cfg}, nil
+}
+
+// String returns the name of the source used in errors.
+func (s *Source) String() string {
+	return "consul " + s.cfg.Address
+}
+
+// Lookup implements envconfig.Source.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, "")
+}
+
+// LookupContext implements envconfig.ContextSource, the values are read with ctx if they are not yet.
+func (s *Source) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	values, err := s.load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	v, ok := values[keyPath(key)]
+	return v, ok, nil
+}
+
+// Keys implements envconfig.Lister, it returns the keys of the values under the prefix, like MYSQL_MASTER_ADDRESS.
+func (s *Source) Keys() []string {
+	values, err := s.load(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for path := range values {
+		keys = append(keys, strings.ToUpper(strings.ReplaceAll(path, "/", "_")))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Refresh reads the values again.
+func (s *Source) Refresh() error {
+	return s.refresh(context.Background())
+}
+
+func (s *Source) refresh(ctx context.Context) error {
+	values, index, err := s.fetch(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.values, s.index = values, index
+	s.mu.Unlock()
+	s.lifecycle.Recover()
+
+	return nil
+}
+
+// Watch waits for changes of the values under the prefix with blocking queries, and calls onChange
+// once the new values are read. It returns when ctx is done or when a query fails,
+// and envconfig.ErrShutdown once the source is shut down.
+func (s *Source) Watch(ctx context.Context, onChange func()) error {
+	watchCtx, release, err := s.lifecycle.Start(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = s.watch(watchCtx, onChange)
+	if ctx.Err() == nil && s.lifecycle.Stopped() {
+		return envconfig.ErrShutdown
+	}
+	if ctx.Err() == nil {
+		s.lifecycle.Fail(err)
+	}
+	return err
+}
+
+// Health implements envconfig.HealthChecker, it returns the error which stopped the last call to Watch,
+// until the values are read again: a blocking query of a new call to Watch returns within the wait time.
+func (s *Source) Health() error {
+	return s.lifecycle.Err()
+}
+
+func (s *Source) watch(ctx context.Context, onChange func()) error {
+	if _, err := s.load(ctx); err != nil {
+		return err
+	}
+
+	for {
+		s.mu.Lock()
+		index := s.index
+		s.mu.Unlock()
+
+		values, newIndex, err := s.fetch(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		// without an index the queries wouldn't block and Watch would query the agent in a loop
+		if newIndex == 0 {
+			return fmt.Errorf("consul: GET kv/%s: no X-Consul-Index in the response, blocking queries are not supported", s.cfg.Prefix)
+		}
+
+		// the index can go backwards, for example after a snapshot restore: start over
+		if newIndex < index {
+			newIndex = 0
+		}
+
+		s.mu.Lock()
+		s.values, s.index = values, newIndex
+		s.mu.Unlock()
+		s.lifecycle.Recover()
+
+		if newIndex != index {
+			onChange()
+		}
+	}
+}
+
+// Shutdown stops the calls to Watch and waits for them to return, or for ctx to be done.
+// The source keeps serving the values it read, but Watch can't be called anymore.
+func (s *Source) Shutdown(ctx context.Context) error {
+	return s.lifecycle.Shutdown(ctx)
+}
+
+// load returns the values, reading them if needed.
+func (s *Source) load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	values := s.values
+	s.mu.Unlock()
+
+	if values != nil {
+		return values, nil
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values, nil
+}
+
+// keyPath returns the path of a key relative to the prefix, like mysql/master/address for MYSQL_MASTER_ADDRESS.
+func keyPath(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "/"))
+}
+
+// fetch reads all the values under the prefix, by path relative to the prefix. With a non-zero index,
+// it is a blocking query which returns once the index changes or the wait time is exceeded.
+func (s *Source) fetch(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if s.cfg.Datacenter != "" {
+		query.Set("dc", s.cfg.Datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%ds", int(s.cfg.WaitTime/time.Second)))
+	}
+
+	u := strings.TrimSuffix(s.cfg.Address, "/") + "/v1/kv/" + (&url.URL{Path: s.cfg.Prefix}).EscapedPath() + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", s.cfg.Token)
+	}
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+
+	newIndex, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	values := make(map[string]string)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// nothing under the prefix
+		return values, newIndex, nil
+	default:
+		return nil, 0, fmt.Errorf("consul: GET kv/%s: %s", s.cfg.Prefix, resp.Status)
+	}
+
+	var entries []struct {
+		Key   string
+		Value []byte
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("consul: invalid response: %w", err)
+	}
+
+	for _, e := range entries {
+		path := strings.TrimPrefix(e.Key, s.cfg.Prefix)
+		// folders have no value
+		if path == "" || e.Value == nil {
+			continue
+		}
+		values[strings.ToLower(path)] = string(e.Value)
+	}
+
+	return values, newIndex, nil
+}
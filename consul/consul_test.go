@@ -0,0 +1,149 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/consul"
+)
+
+type kvServer struct {
+	mu      sync.Mutex
+	index   int
+	values  map[string]string
+	changed chan struct{}
+}
+
+func (s *kvServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// like Consul, a recursive read returns all the keys starting with the path
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	if !strings.HasPrefix("config/myapp/", prefix) || r.URL.Query().Get("recurse") != "true" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	// blocking query: wait for a change
+	if r.URL.Query().Get("index") != "" {
+		select {
+		case <-s.changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []map[string]interface{}
+	entries = append(entries, map[string]interface{}{"Key": "config/myapp/", "Value": nil})
+	for k, v := range s.values {
+		entries = append(entries, map[string]interface{}{"Key": "config/myapp/" + k, "Value": []byte(v)})
+	}
+	if strings.HasPrefix("config/myapp2/name", prefix) {
+		entries = append(entries, map[string]interface{}{"Key": "config/myapp2/name", "Value": []byte("other")})
+	}
+
+	if s.index > 0 {
+		w.Header().Set("X-Consul-Index", strconv.Itoa(s.index))
+	}
+	json.NewEncoder(w).Encode(entries)
+}
+
+func TestSource(t *testing.T) {
+	kv := &kvServer{
+		index:   1,
+		values:  map[string]string{"name": "foo", "mysql/master/address": "db:3306"},
+		changed: make(chan struct{}),
+	}
+	srv := httptest.NewServer(kv)
+	defer srv.Close()
+
+	source, err := consul.New(consul.Config{Address: srv.URL, Prefix: "config/myapp"})
+	require.Nil(t, err)
+
+	var conf struct {
+		Name  string
+		MySQL struct {
+			Master struct {
+				Address string
+			}
+		}
+	}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, "db:3306", conf.MySQL.Master.Address)
+	require.Equal(t, []string{"MYSQL_MASTER_ADDRESS", "NAME"}, source.Keys())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- source.Watch(ctx, func() { changes <- struct{}{} })
+	}()
+
+	kv.mu.Lock()
+	kv.index = 2
+	kv.values["name"] = "bar"
+	kv.mu.Unlock()
+	kv.changed <- struct{}{}
+
+	<-changes
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "bar", conf.Name)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-done)
+}
+
+func TestWatchShutdown(t *testing.T) {
+	kv := &kvServer{index: 1, values: map[string]string{"name": "foo"}, changed: make(chan struct{})}
+	server := httptest.NewServer(kv)
+	defer server.Close()
+
+	source, err := consul.New(consul.Config{Address: server.URL, Prefix: "config/myapp"})
+	require.Nil(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- source.Watch(context.Background(), func() {})
+	}()
+
+	require.Eventually(t, func() bool { return len(source.Keys()) > 0 }, time.Second, time.Millisecond)
+
+	err = source.Shutdown(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, envconfig.ErrShutdown, <-done)
+	require.Equal(t, envconfig.ErrShutdown, source.Watch(context.Background(), func() {}))
+	require.Nil(t, source.Health())
+}
+
+func TestWatchNoIndex(t *testing.T) {
+	kv := &kvServer{values: map[string]string{"name": "foo"}, changed: make(chan struct{})}
+	server := httptest.NewServer(kv)
+	defer server.Close()
+
+	source, err := consul.New(consul.Config{Address: server.URL, Prefix: "config/myapp"})
+	require.Nil(t, err)
+
+	err = source.Watch(context.Background(), func() {})
+	require.NotNil(t, err)
+	require.Equal(t, "consul: GET kv/config/myapp/: no X-Consul-Index in the response, blocking queries are not supported", err.Error())
+	require.Equal(t, err, source.Health())
+	require.Equal(t, "consul "+server.URL+": "+err.Error(), envconfig.Chain{envconfig.EnvSource{}, source}.Health().Error())
+
+	// the source is healthy again once the values are read
+	require.Nil(t, source.Refresh())
+	require.Nil(t, source.Health())
+}
@@ -0,0 +1,97 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SameSite is the SameSite attribute of a cookie. It is parsed from default, lax, strict or none.
+type SameSite http.SameSite
+
+// Unmarshal implements Unmarshaler.
+func (s *SameSite) Unmarshal(str string) error {
+	switch strings.ToLower(str) {
+	case "default":
+		*s = SameSite(http.SameSiteDefaultMode)
+	case "lax":
+		*s = SameSite(http.SameSiteLaxMode)
+	case "strict":
+		*s = SameSite(http.SameSiteStrictMode)
+	case "none":
+		*s = SameSite(http.SameSiteNoneMode)
+	default:
+		return fmt.Errorf("invalid SameSite value %q, expected default, lax, strict or none", str)
+	}
+	return nil
+}
+
+// String returns the value in the format accepted by Unmarshal.
+func (s SameSite) String() string {
+	switch http.SameSite(s) {
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "none"
+	default:
+		return "default"
+	}
+}
+
+// CookieConfig holds the settings of a cookie, typically a session cookie.
+// Use it as a field of your config struct:
+//
+//	var conf struct {
+//		Session envconfig.CookieConfig
+//	}
+//
+// With that struct, the keys are SESSION_NAME, SESSION_DOMAIN, SESSION_PATH, SESSION_SECURE, SESSION_HTTP_ONLY,
+// SESSION_SAME_SITE and SESSION_MAX_AGE. Only the name is required.
+//
+// The settings are validated to reject the combinations browsers ignore, like SameSite=None without Secure.
+type CookieConfig struct {
+	Name     string
+	Domain   string        `envconfig:"optional"`
+	Path     string        `envconfig:"default=/"`
+	Secure   bool          `envconfig:"default=true"`
+	HTTPOnly bool          `envconfig:"default=true"`
+	SameSite SameSite      `envconfig:"default=lax"`
+	MaxAge   time.Duration `envconfig:"optional"`
+}
+
+// Validate implements Validator.
+func (c *CookieConfig) Validate() error {
+	switch {
+	case c.Name == "":
+		return errors.New("cookie name is empty")
+	case strings.ContainsAny(c.Name, "()<>@,;:\\\"/[]?={} \t"):
+		return fmt.Errorf("cookie name %q contains invalid characters", c.Name)
+	case c.MaxAge < 0:
+		return errors.New("cookie max age is negative")
+	case http.SameSite(c.SameSite) == http.SameSiteNoneMode && !c.Secure:
+		return errors.New("cookie with SameSite=None must be secure")
+	case strings.HasPrefix(c.Name, "__Secure-") && !c.Secure:
+		return errors.New("cookie with the __Secure- prefix must be secure")
+	case strings.HasPrefix(c.Name, "__Host-") && (!c.Secure || c.Domain != "" || c.Path != "/"):
+		return errors.New("cookie with the __Host- prefix must be secure, have no domain and the path /")
+	}
+	return nil
+}
+
+// Cookie returns a new cookie with the value and these settings.
+func (c *CookieConfig) Cookie(value string) *http.Cookie {
+	return &http.Cookie{
+		Name:     c.Name,
+		Value:    value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HTTPOnly,
+		SameSite: http.SameSite(c.SameSite),
+		MaxAge:   int(c.MaxAge / time.Second),
+	}
+}
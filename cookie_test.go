@@ -0,0 +1,57 @@
+package envconfig_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestCookieConfig(t *testing.T) {
+	var conf struct {
+		Session envconfig.CookieConfig
+	}
+
+	os.Setenv("SESSION_NAME", "__Host-sid")
+	os.Setenv("SESSION_SAME_SITE", "strict")
+	os.Setenv("SESSION_MAX_AGE", "1h")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, &http.Cookie{
+		Name:     "__Host-sid",
+		Value:    "foobar",
+		Path:     "/",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   3600,
+	}, conf.Session.Cookie("foobar"))
+	require.Equal(t, time.Hour, conf.Session.MaxAge)
+
+	os.Setenv("SESSION_DOMAIN", "example.com")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, "envconfig: invalid config (field Session): cookie with the __Host- prefix must be secure, have no domain and the path /", err.Error())
+
+	os.Setenv("SESSION_NAME", "sid")
+	os.Setenv("SESSION_SAME_SITE", "none")
+	os.Setenv("SESSION_SECURE", "false")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, "envconfig: invalid config (field Session): cookie with SameSite=None must be secure", err.Error())
+
+	os.Setenv("SESSION_SAME_SITE", "loose")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse SESSION_SAME_SITE (field Session.SameSite): invalid SameSite value "loose", expected default, lax, strict or none`, err.Error())
+
+	os.Setenv("SESSION_NAME", "")
+	os.Setenv("SESSION_DOMAIN", "")
+	os.Setenv("SESSION_SAME_SITE", "")
+	os.Setenv("SESSION_SECURE", "")
+	os.Setenv("SESSION_MAX_AGE", "")
+}
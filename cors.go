@@ -0,0 +1,118 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CORSConfig holds a CORS policy. Use it as a field of your config struct:
+//
+//	var conf struct {
+//		CORS envconfig.CORSConfig
+//	}
+//
+// With that struct, the keys are CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS, CORS_ALLOWED_HEADERS, CORS_EXPOSED_HEADERS,
+// CORS_MAX_AGE and CORS_ALLOW_CREDENTIALS. Only the allowed origins are required.
+//
+// An origin is either *, a full origin like https://example.com or an origin with a wildcard subdomain
+// like https://*.example.com.
+// The policy is validated to reject insecure combinations, like allowing credentials with the wildcard origin.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string      `envconfig:"optional"`
+	AllowedHeaders   []string      `envconfig:"optional"`
+	ExposedHeaders   []string      `envconfig:"optional"`
+	MaxAge           time.Duration `envconfig:"optional"`
+	AllowCredentials bool          `envconfig:"default=false"`
+}
+
+// defaultCORSMethods are the allowed methods when none are configured.
+var defaultCORSMethods = []string{"GET", "HEAD", "POST"}
+
+// Validate implements Validator.
+func (c *CORSConfig) Validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return errors.New("no allowed origin")
+	}
+
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return errors.New("credentials can't be allowed with the wildcard origin")
+			}
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+			return fmt.Errorf("invalid origin %q, expected scheme://host[:port]", origin)
+		}
+		if strings.Contains(strings.TrimPrefix(u.Host, "*."), "*") {
+			return fmt.Errorf("invalid origin %q, only a leading wildcard subdomain is allowed", origin)
+		}
+	}
+
+	for _, method := range c.AllowedMethods {
+		if method == "" || strings.ToUpper(method) != method || !isToken(method) {
+			return fmt.Errorf("invalid method %q", method)
+		}
+	}
+
+	for _, headers := range [][]string{c.AllowedHeaders, c.ExposedHeaders} {
+		for _, header := range headers {
+			if header != "*" && !isToken(header) {
+				return fmt.Errorf("invalid header %q", header)
+			}
+		}
+	}
+
+	if c.MaxAge < 0 {
+		return errors.New("max age is negative")
+	}
+
+	return nil
+}
+
+// AllowsOrigin returns true if the origin is allowed by the policy.
+func (c *CORSConfig) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		switch {
+		case allowed == "*":
+			return true
+		case strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin):
+			return true
+		case strings.Contains(allowed, "://*."):
+			i := strings.Index(allowed, "*")
+			prefix, suffix := strings.ToLower(allowed[:i]), strings.ToLower(strings.TrimSuffix(allowed[i+1:], "/"))
+			o := strings.ToLower(origin)
+			if strings.HasPrefix(o, prefix) && strings.HasSuffix(o, suffix) && len(o) > len(prefix)+len(suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Methods returns the allowed methods, or GET, HEAD and POST if none are configured.
+func (c *CORSConfig) Methods() []string {
+	if len(c.AllowedMethods) == 0 {
+		return defaultCORSMethods
+	}
+	return c.AllowedMethods
+}
+
+// isToken returns true if s is a valid HTTP token as defined by RFC 7230.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
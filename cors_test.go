@@ -0,0 +1,55 @@
+package envconfig_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestCORSConfig(t *testing.T) {
+	var conf struct {
+		CORS envconfig.CORSConfig
+	}
+
+	os.Setenv("CORS_ALLOWED_ORIGINS", "https://example.com,https://*.example.org")
+	os.Setenv("CORS_ALLOWED_HEADERS", "Authorization,Content-Type")
+	os.Setenv("CORS_MAX_AGE", "10m")
+	os.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, []string{"Authorization", "Content-Type"}, conf.CORS.AllowedHeaders)
+	require.Equal(t, 10*time.Minute, conf.CORS.MaxAge)
+	require.True(t, conf.CORS.AllowCredentials)
+	require.Equal(t, []string{"GET", "HEAD", "POST"}, conf.CORS.Methods())
+
+	require.True(t, conf.CORS.AllowsOrigin("https://example.com"))
+	require.True(t, conf.CORS.AllowsOrigin("https://api.example.org"))
+	require.False(t, conf.CORS.AllowsOrigin("https://example.org"))
+	require.False(t, conf.CORS.AllowsOrigin("http://example.com"))
+	require.False(t, conf.CORS.AllowsOrigin("https://evil.com"))
+
+	os.Setenv("CORS_ALLOWED_ORIGINS", "*")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, "envconfig: invalid config (field CORS): credentials can't be allowed with the wildcard origin", err.Error())
+
+	os.Setenv("CORS_ALLOW_CREDENTIALS", "")
+	os.Setenv("CORS_ALLOWED_METHODS", "get")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: invalid config (field CORS): invalid method "get"`, err.Error())
+
+	os.Setenv("CORS_ALLOWED_METHODS", "")
+	os.Setenv("CORS_ALLOWED_ORIGINS", "example.com/foo")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: invalid config (field CORS): invalid origin "example.com/foo", expected scheme://host[:port]`, err.Error())
+
+	os.Setenv("CORS_ALLOWED_ORIGINS", "")
+	os.Setenv("CORS_ALLOWED_HEADERS", "")
+	os.Setenv("CORS_MAX_AGE", "")
+}
@@ -0,0 +1,150 @@
+package envconfig
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// csvColumn is a field of the struct elements of a slice read with the csv option.
+type csvColumn struct {
+	index int
+	name  string
+	// customName is the name of the envconfig tag of the field, if any.
+	customName string
+}
+
+// matches returns true if the column is named name, case insensitively.
+func (c csvColumn) matches(name string) bool {
+	if strings.EqualFold(name, c.name) {
+		return true
+	}
+	if c.customName == "" {
+		return false
+	}
+	for _, key := range customKeys(c.customName) {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// csvColumns returns the exported fields of the struct type typ, or an error if typ isn't a struct.
+func csvColumns(typ reflect.Type) ([]csvColumn, error) {
+	if typ.Kind() != reflect.Struct || isUnmarshaler(typ) {
+		return nil, fmt.Errorf("csv option unsupported on a slice of %v, the elements must be structs", typ)
+	}
+
+	var columns []csvColumn
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		t := ParseTag(field.Tag.Get("envconfig"))
+		if t.Skip {
+			continue
+		}
+		columns = append(columns, csvColumn{index: i, name: field.Name, customName: t.Name})
+	}
+	return columns, nil
+}
+
+// setCSVField sets the slice of structs value from the CSV rows of str. The first row is the header naming
+// the fields, by their name or the name of their envconfig tag, case insensitively. The fields without a column
+// and the empty cells keep their zero value.
+func setCSVField(value reflect.Value, str string, ctx *fieldContext) error {
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("csv option unsupported on a %v, the field must be a slice of structs", value.Type())
+	}
+	elType := value.Type().Elem()
+	columns, err := csvColumns(elType)
+	if err != nil {
+		return err
+	}
+
+	r := csv.NewReader(strings.NewReader(str))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New("no CSV header")
+	}
+
+	indexes := make([]int, len(records[0]))
+	for i, name := range records[0] {
+		indexes[i] = -1
+		for _, c := range columns {
+			if c.matches(name) {
+				indexes[i] = c.index
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return fmt.Errorf("unknown CSV column %q", name)
+		}
+	}
+
+	slice := reflect.MakeSlice(value.Type(), 0, len(records)-1)
+	for n, record := range records[1:] {
+		el := reflect.New(elType).Elem()
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			if err := parseValue(el.Field(indexes[i]), cell, ctx); err != nil {
+				return fmt.Errorf("CSV row %d, column %s: %w", n+1, records[0][i], err)
+			}
+		}
+		slice = reflect.Append(slice, el)
+	}
+
+	value.Set(slice)
+
+	return nil
+}
+
+// marshalCSV returns the CSV rows of the slice of structs v, with a header naming all the fields.
+func marshalCSV(v reflect.Value, ctx *fieldContext) (string, error) {
+	if v.Kind() != reflect.Slice {
+		return "", fmt.Errorf("csv option unsupported on a %v, the field must be a slice of structs", v.Type())
+	}
+	columns, err := csvColumns(v.Type().Elem())
+	if err != nil {
+		return "", err
+	}
+	if v.Len() == 0 {
+		return "", nil
+	}
+
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		record[i] = c.name
+		if c.customName != "" {
+			record[i] = customKeys(c.customName)[0]
+		}
+	}
+	w.Write(record)
+
+	for i := 0; i < v.Len(); i++ {
+		for j, c := range columns {
+			cell, err := marshalValue(v.Index(i).Field(c.index), ctx)
+			if err != nil {
+				return "", err
+			}
+			record[j] = cell
+		}
+		w.Write(record)
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n"), w.Error()
+}
@@ -0,0 +1,67 @@
+package envconfig_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type csvBackend struct {
+	Name    string
+	Weight  int `envconfig:"w"`
+	Timeout time.Duration
+	Tags    string
+}
+
+func TestCSV(t *testing.T) {
+	var conf struct {
+		Backends []csvBackend `envconfig:",csv"`
+	}
+
+	source := envconfig.MapSource{"BACKENDS": "name, w, timeout\nfoo,10,1s\n\"bar,baz\",,2m\n"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, []csvBackend{
+		{Name: "foo", Weight: 10, Timeout: time.Second},
+		{Name: "bar,baz", Timeout: 2 * time.Minute},
+	}, conf.Backends)
+
+	vars, err := envconfig.Marshal(&conf)
+	require.Nil(t, err)
+	require.Equal(t, "Name,w,Timeout,Tags\nfoo,10,1s,\n\"bar,baz\",0,2m0s,", vars["BACKENDS"])
+
+	source["BACKENDS"] = vars["BACKENDS"]
+	conf.Backends = nil
+	require.Nil(t, envconfig.InitWithOptions(&conf, envconfig.Options{Source: source}))
+	require.Equal(t, "bar,baz", conf.Backends[1].Name)
+}
+
+func TestCSVError(t *testing.T) {
+	var conf struct {
+		Backends []csvBackend `envconfig:",csv"`
+	}
+
+	testCases := []struct {
+		value string
+		err   string
+	}{
+		{"name,port\nfoo,80", `unknown CSV column "port"`},
+		{"name,w\nfoo,ten", `CSV row 1, column w: strconv.ParseInt: parsing "ten": invalid syntax`},
+		{"name,w\nfoo", "record on line 2: wrong number of fields"},
+	}
+
+	for _, tc := range testCases {
+		err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{"BACKENDS": tc.value}})
+		require.NotNil(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+
+	var invalid struct {
+		Names []string `envconfig:",csv"`
+	}
+	err := envconfig.InitWithOptions(&invalid, envconfig.Options{Source: envconfig.MapSource{"NAMES": "a,b"}})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "csv option unsupported on a slice of string, the elements must be structs")
+}
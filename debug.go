@@ -0,0 +1,49 @@
+package envconfig
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// DebugHandler is an http.Handler serving the effective configuration as JSON, with the values of the secret fields
+// masked like Dump does, along with the Report of how it was read. It is meant for an internal admin port:
+//
+//	report, err := envconfig.InitWithReport(&conf, opts)
+//	...
+//	admin.Handle("/debug/config", envconfig.DebugHandler{
+//		Config:  func() interface{} { return &conf },
+//		Report:  report,
+//		Options: opts,
+//	})
+//
+// The response is an object with the values by key in config and the report in report, if any.
+type DebugHandler struct {
+	// Config returns the configuration to serve, called on each request. With a Store, return its Get method.
+	Config func() interface{}
+	// Report is the report of the configuration, if any.
+	Report *Report
+	// Options are the options the configuration was read with, for the keys of the fields.
+	Options Options
+}
+
+// ServeHTTP implements http.Handler.
+func (h DebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fields, err := marshalFields(h.Config(), h.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f.key] = maskValue(f.secret, f.redact, f.value)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct {
+		Config map[string]string `json:"config"`
+		Report *Report           `json:"report,omitempty"`
+	}{values, h.Report})
+}
@@ -0,0 +1,46 @@
+package envconfig_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestDebugHandler(t *testing.T) {
+	var conf struct {
+		Name     string
+		Password string `envconfig:",secret"`
+	}
+
+	opts := envconfig.Options{Prefix: "APP", Source: envconfig.MapSource{"APP_NAME": "foo", "APP_PASSWORD": "foobar"}}
+	report, err := envconfig.InitWithReport(&conf, opts)
+	require.Nil(t, err)
+
+	handler := envconfig.DebugHandler{
+		Config:  func() interface{} { return &conf },
+		Options: opts,
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"config": {"APP_NAME": "foo", "APP_PASSWORD": "***"}}`, rec.Body.String())
+
+	conf.Name = "bar"
+	handler.Report = report
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	require.Contains(t, rec.Body.String(), `"APP_NAME": "bar"`)
+	require.Contains(t, rec.Body.String(), `"report": {`)
+	require.Contains(t, rec.Body.String(), `"path": "Password",
+        "key": "APP_PASSWORD",
+        "origin": "source",
+        "source": "envconfig.MapSource",
+        "value": "***",
+        "secret": true`)
+	require.NotContains(t, rec.Body.String(), "foobar")
+}
@@ -0,0 +1,49 @@
+package envconfig
+
+import (
+	"context"
+	"fmt"
+)
+
+// Decryptor decrypts the values of the fields with the encrypted option, so that the environment only holds
+// ciphertexts, for example encrypted with a KMS:
+//
+//	var conf struct {
+//		DBPassword string `envconfig:",encrypted"`
+//	}
+//	envconfig.InitWithOptions(&conf, envconfig.Options{Decryptor: kms})
+//
+// The values are decrypted after being read from a file with the fromFile option and trimmed with the trim option,
+// and before being decoded with the base64 option. A byte slice holds the decrypted value as is.
+// The encrypted fields are secret, but Marshal returns their decrypted values.
+type Decryptor interface {
+	// Decrypt returns the plaintext of the ciphertext, which is the value of the key, empty for a default value.
+	// ctx is the context of InitContext.
+	Decrypt(ctx context.Context, key, ciphertext string) (string, error)
+}
+
+// DecryptorFunc is a function implementing Decryptor.
+type DecryptorFunc func(ctx context.Context, key, ciphertext string) (string, error)
+
+// Decrypt implements Decryptor.
+func (f DecryptorFunc) Decrypt(ctx context.Context, key, ciphertext string) (string, error) {
+	return f(ctx, key, ciphertext)
+}
+
+// decrypt decrypts the value of the field read from key with the Decryptor of the options.
+func decrypt(ctx *fieldContext, key, ciphertext string) (string, error) {
+	if ctx.opts.Decryptor == nil {
+		return "", fmt.Errorf("%w (field %s)", ErrNoDecryptor, ctx.path)
+	}
+
+	lookupCtx := ctx.lookupCtx
+	if lookupCtx == nil {
+		lookupCtx = context.Background()
+	}
+
+	plaintext, err := ctx.opts.Decryptor.Decrypt(lookupCtx, key, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("envconfig: unable to decrypt %s (field %s): %w", keyOrDefault(key), ctx.path, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,75 @@
+package envconfig_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+// rot13 decrypts the values prefixed with rot13:.
+var rot13 = envconfig.DecryptorFunc(func(ctx context.Context, key, ciphertext string) (string, error) {
+	s, ok := strings.CutPrefix(ciphertext, "rot13:")
+	if !ok {
+		return "", errors.New("invalid ciphertext")
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return 'a' + (r-'a'+13)%26
+		case r >= 'A' && r <= 'Z':
+			return 'A' + (r-'A'+13)%26
+		}
+		return r
+	}, s), nil
+})
+
+func TestEncrypted(t *testing.T) {
+	var conf struct {
+		Name     string
+		Password string `envconfig:",encrypted"`
+		Key      []byte `envconfig:",encrypted"`
+		Token    string `envconfig:",encrypted,base64"`
+		Region   string `envconfig:",encrypted,default=rot13:rh-jrfg-1"`
+	}
+
+	source := envconfig.MapSource{
+		"NAME":     "foo",
+		"PASSWORD": "rot13:frperg",
+		"KEY":      "rot13:xrl",
+		// rot13 of the base64 of token
+		"TOKEN": "rot13:qT9eMJ4=",
+	}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: rot13})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, "secret", conf.Password)
+	require.Equal(t, []byte("key"), conf.Key)
+	require.Equal(t, "token", conf.Token)
+	require.Equal(t, "eu-west-1", conf.Region)
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.True(t, errors.Is(err, envconfig.ErrNoDecryptor))
+	require.Contains(t, err.Error(), "envconfig: no Decryptor for the encrypted field (field Password)")
+
+	source["PASSWORD"] = "secret"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: rot13})
+	require.Equal(t, "envconfig: unable to decrypt PASSWORD (field Password): invalid ciphertext", err.Error())
+}
+
+func TestEncryptedParseError(t *testing.T) {
+	var conf struct {
+		Port int `envconfig:",encrypted"`
+	}
+
+	source := envconfig.MapSource{"PORT": "rot13:rvtugl"}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: rot13})
+	// the decrypted value is secret
+	require.NotNil(t, err)
+	require.NotContains(t, err.Error(), "eighty")
+}
@@ -0,0 +1,193 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// fieldInfo describes a field of a config struct as seen by Init, without reading its value.
+type fieldInfo struct {
+	path string
+	// key is the canonical key of the field, keys are all the keys looked up.
+	key        string
+	keys       []string
+	typ        reflect.Type
+	defaultVal string
+	optional   bool
+	// redact is the partial redaction policy of a secret field, if any.
+	secret      bool
+	redact      string
+	description string
+	// durationFormat and validators are the options of the tag affecting the format of the value.
+	durationFormat string
+	validators     []string
+	// example is the example value of the field, the example option of its tag or the one of its type (see DocValuer).
+	example string
+	// group is the group of the field, if any.
+	group string
+	// rest is true for a field with the rest tag, key is then a pattern like APP_*.
+	rest bool
+	// serial is true if the keys of the field must not be looked up concurrently with others.
+	serial bool
+	// tag is the struct tag of the field, used by the sources implementing FieldSource.
+	tag reflect.StructTag
+}
+
+// describe returns the description of all the fields of conf, in the order Init reads them.
+func describe(conf interface{}, opts Options) ([]fieldInfo, error) {
+	opts = withProvidedOptions(conf, opts)
+	typ := reflect.TypeOf(conf)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return nil, ErrNotAPointer
+	}
+
+	typ = typ.Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, ErrInvalidValueKind
+	}
+
+	var res []fieldInfo
+	err := describeStruct(typ, &fieldContext{
+		name:     opts.Prefix,
+		optional: opts.AllOptional,
+		secret:   opts.RedactValues,
+		state:    &state{opts: opts},
+	}, &res)
+
+	return res, err
+}
+
+func describeStruct(typ reflect.Type, ctx *fieldContext, res *[]fieldInfo) error {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := field.Name
+
+		tag, err := parseTag(field, ctx.opts)
+		if err != nil {
+			return fmt.Errorf("%w (field %s)", err, combineName(ctx.path, name))
+		}
+		unexported := field.PkgPath != "" && !(isEmbeddedStruct(field) && field.Type.Kind() == reflect.Struct)
+		if tag.skip || unexported {
+			if unexported && !ctx.opts.AllowUnexported {
+				return fmt.Errorf("%w (field %s)", ErrUnexportedField, combineName(ctx.path, name))
+			}
+			continue
+		}
+
+		if tag.rest {
+			key := "*"
+			if prefixes := keyPrefixes(ctx.name); ctx.name != "" && len(prefixes) > 0 {
+				key = prefixes[0] + "*"
+			}
+			*res = append(*res, fieldInfo{
+				path:        combineName(ctx.path, name),
+				key:         key,
+				keys:        []string{key},
+				typ:         field.Type,
+				optional:    true,
+				description: tag.description,
+				rest:        true,
+			})
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		inSecret := isSecretType(fieldType)
+		if inSecret {
+			fieldType = secretElem(fieldType)
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+		}
+		if m := ctx.opts.mapping(fieldType); m != nil {
+			if m.err != nil {
+				return m.err
+			}
+			// the mapped fields are described instead
+			fieldType = m.fields
+		}
+
+		fieldCtx := &fieldContext{
+			name:           combineName(ctx.name, name),
+			path:           combineName(ctx.path, name),
+			customName:     tag.customName,
+			optional:       ctx.optional || tag.optional,
+			secret:         ctx.secret || tag.secret || tag.redact != "" || tag.encrypted || inSecret,
+			redact:         tag.redact,
+			defaultVal:     tag.defaultVal,
+			durationFormat: tag.durationFormat,
+			validators:     tag.validators,
+			description:    tag.description,
+			state:          ctx.state,
+		}
+
+		if fieldType.Kind() == reflect.Struct && !isUnmarshaler(fieldType) {
+			fieldCtx.customName = ""
+			fieldCtx.name = tag.structName(ctx.name, name)
+			if err := describeStruct(fieldType, fieldCtx, res); err != nil {
+				return err
+			}
+			continue
+		}
+
+		example := tag.example
+		if example == "" {
+			example = docValue(fieldType)
+		}
+
+		*res = append(*res, fieldInfo{
+			path:           fieldCtx.path,
+			key:            canonicalKey(fieldCtx),
+			keys:           fieldKeys(fieldCtx, tag.deprecated),
+			typ:            fieldType,
+			defaultVal:     fieldCtx.defaultVal,
+			optional:       fieldCtx.optional,
+			secret:         fieldCtx.secret,
+			redact:         fieldCtx.redact,
+			description:    fieldCtx.description,
+			durationFormat: fieldCtx.durationFormat,
+			validators:     fieldCtx.validators,
+			example:        example,
+			group:          tag.group,
+			serial:         tag.isSerial(ctx.opts),
+			tag:            field.Tag,
+		})
+	}
+
+	return nil
+}
+
+// fieldKeys returns the keys of the field looked up in order, ending with its deprecated key if any.
+func fieldKeys(ctx *fieldContext, deprecated string) []string {
+	keys := makeAllPossibleKeys(ctx)
+	if deprecated != "" {
+		keys = append(keys, deprecated)
+	}
+	return keys
+}
+
+// docValue returns the example value of a type implementing DocValuer, or an empty string.
+func docValue(typ reflect.Type) string {
+	if v, ok := reflect.New(typ).Interface().(DocValuer); ok {
+		return v.DocValue()
+	}
+	return ""
+}
+
+// documentation returns the description of the field followed by its example value, if any.
+func (f fieldInfo) documentation() string {
+	switch {
+	case f.example == "":
+		return f.description
+	case f.description == "":
+		return "e.g. " + f.example
+	default:
+		return f.description + ", e.g. " + f.example
+	}
+}
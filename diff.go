@@ -0,0 +1,108 @@
+package envconfig
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldChange is a field whose value differs between two configurations, see Diff.
+type FieldChange struct {
+	// Path is the path of the field in the struct, like Log.Level.
+	Path string
+	// Key is the canonical key of the field.
+	Key string
+	// Old and New are the values formatted like Marshal does, empty for an unset pointer.
+	// If Secret is true, they are empty or redacted by the redact option of the field.
+	Old, New string
+	Secret   bool
+}
+
+// Diff returns the fields whose values differ between the configurations old and new, sorted by path.
+// old and new must be structs of the same type, or pointers to them.
+func Diff(old, new interface{}) ([]FieldChange, error) {
+	return DiffWithOptions(old, new, Options{})
+}
+
+// DiffWithOptions is like Diff, with the keys of the fields built with opts like MarshalWithOptions does.
+// The option RedactValues makes all fields secret.
+func DiffWithOptions(old, new interface{}, opts Options) ([]FieldChange, error) {
+	oldFields, err := marshalFields(old, opts)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := marshalFields(new, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []FieldChange
+	for path, n := range newFields {
+		o, ok := oldFields[path]
+		if ok && o.value == n.value {
+			continue
+		}
+		res = append(res, newFieldChange(n, o.value, n.value))
+	}
+	for path, o := range oldFields {
+		if _, ok := newFields[path]; !ok && o.value != "" {
+			res = append(res, newFieldChange(o, o.value, ""))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+
+	return res, nil
+}
+
+// newFieldChange returns the change of the field f from old to new, redacted if f is secret.
+func newFieldChange(f marshaledField, old, new string) FieldChange {
+	if f.secret {
+		old, new = redactValue(f.redact, old), redactValue(f.redact, new)
+	}
+	return FieldChange{Path: f.path, Key: f.key, Old: old, New: new, Secret: f.secret}
+}
+
+// marshalFields returns the formatted fields of conf by path.
+func marshalFields(conf interface{}, opts Options) (map[string]marshaledField, error) {
+	opts = withProvidedOptions(conf, opts)
+	value := reflect.ValueOf(conf)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, ErrInvalidValueKind
+	}
+
+	res := make(map[string]marshaledField)
+	err := marshalStruct(value, &fieldContext{
+		name:   opts.Prefix,
+		secret: opts.RedactValues,
+		state:  &state{opts: opts},
+	}, func(f marshaledField) {
+		res[f.path] = f
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// underPaths returns the changes of the fields under one of the paths, all of them if there are no paths.
+// A path selects the field with this path and the fields of the struct with this path.
+func underPaths(changes []FieldChange, paths []string) []FieldChange {
+	if len(paths) == 0 {
+		return changes
+	}
+
+	var res []FieldChange
+	for _, c := range changes {
+		for _, path := range paths {
+			if c.Path == path || strings.HasPrefix(c.Path, path+".") {
+				res = append(res, c)
+				break
+			}
+		}
+	}
+	return res
+}
@@ -76,6 +76,59 @@ And, if that is not good enough for you, you always have the option to use a cus
 
 Now envconfig will only ever checks the environment variable _cassandraMyName_.
 
+Likewise, the prefix option of a nested struct replaces the chain of its fields, including the prefix of the
+options, and the noprefix option removes it, so that a struct shared by several services reads the same keys:
+
+    var conf struct {
+        Primary Database `envconfig:"prefix=DB"` // DB_HOST, DB_PORT
+        Shared  Shared   `envconfig:",noprefix"` // DATABASE_URL
+    }
+
+The fields of an embedded struct are flattened into its parent, as if they were declared by the parent,
+unless the embedded field has the prefixed option:
+
+    var conf struct {
+        HTTPConfig                         // ADDR
+        TLSConfig `envconfig:",prefixed"`  // TLS_CONFIG_CERT
+    }
+
+The option NoFlatten prefixes the fields of the embedded structs with their name instead, like the other nested
+structs. Whatever the option, the prefixed option, also spelled noflatten, keeps the prefix of a field and the squash
+option flattens any nested struct into its parent:
+
+    var conf struct {
+        HTTPConfig `envconfig:",squash"`  // ADDR, even with NoFlatten
+        Log        struct {
+            Level string
+        } `envconfig:",squash"`           // LEVEL
+    }
+
+KeyFor returns the keys of a field chain, the canonical one first, so that other tools can compute them exactly like envconfig:
+
+    envconfig.KeyFor("Cassandra", "SSLCert") // [CASSANDRA_SSLCERT CASSANDRA_SSL_CERT cassandra_ssl_cert cassandra_sslcert]
+
+The option ValidateKeys makes Init check the keys of all the fields before reading them, and fail if one of them
+isn't a portable name of environment variable, like the keys derived from field names with accented letters.
+The option MaxKeyLength makes it check the length of the keys, with or without ValidateKeys.
+
+The canonical key, shown by Usage and written by Marshal, concatenates the words of the names, like CASSANDRA_SSLCERT.
+The option SnakeCase splits them instead, like CASSANDRA_SSL_CERT, and looks up that key first.
+The common acronyms like API, HTTP, ID or TLS are kept as single words, and the option Acronyms adds others:
+
+    opts := envconfig.Options{SnakeCase: true, Acronyms: []string{"OIDC"}} // OIDCAPIKey is OIDC_API_KEY
+
+The option StrictCase looks up the canonical key only, without the lower case and other variants of the default
+naming scheme.
+
+The option KeyNames replaces the built-in naming with the keys of a convention, computed from the chain of names of a field:
+
+    opts := envconfig.Options{
+        Prefix: "APP",
+        KeyNames: func(path []string) []string {
+            return []string{strings.ToUpper(strings.Join(path, "__"))} // APP__DATABASE__HOST
+        },
+    }
+
 
 Content of the variables
 
@@ -104,6 +157,15 @@ Example of a valid struct value:
 Example of a valid slice of struct values:
     {foobar,10,120s},{barbaz,20,50s}
 
+With the csv option, a slice of structs is read from CSV rows instead, the first row naming the fields,
+which is more readable for tables. The columns can be in any order and the missing or empty cells keep
+their zero value:
+    Backends []MyStruct `envconfig:",fromFile,csv"`
+
+    name,timeout,id
+    foobar,120s,10
+    barbaz,50s,20
+
 Special case for bytes slices
 
 For bytes slices, you generally don't want to type out a comma-separated list of byte values.
@@ -131,6 +193,38 @@ Sometimes you don't absolutely need a value. Here's how we tell envconfig a valu
 
 The two syntax are equivalent.
 
+A value which is not optional is required, and Init fails when it is missing. MissingExports then returns
+a line like `export NAME=<string>` for each missing key, ready to be pasted in a shell and completed.
+
+Secret values
+
+Fields holding secrets like passwords can be marked as such:
+
+    var conf struct {
+        Password string `envconfig:",secret"`
+    }
+
+The value of a secret field is never included in error messages. The option RedactValues does the same for all fields.
+
+For URLs and DSNs, the redact=url option only hides the credentials, so that the host still appears in error messages:
+
+    var conf struct {
+        DatabaseURL string `envconfig:"redact=url"`
+    }
+
+With that struct, postgres://app:secret@db/app is shown as postgres://app:xxxxx@db/app. RedactURL is the redactor used.
+
+The tag only protects the value inside envconfig. To also keep it out of logs and dumps of the configuration,
+use the Secret type, whose String, Format and MarshalJSON methods return *** instead of the value:
+
+    var conf struct {
+        Password envconfig.Secret[string]
+    }
+
+    db.Connect(conf.Password.Value())
+
+A Secret[T] field is read like a field of type T and is always secret.
+
 Default values
 
 Often times you have configuration keys which almost never changes, but you still want to be able to change them.
@@ -143,6 +237,332 @@ Here's to do this with envconfig:
         Timeout time.Duration `envconfig:"default=1m"`
     }
 
+Duration format
+
+By default durations are parsed with time.ParseDuration. Values produced by other ecosystems often use the ISO 8601 format instead,
+which you can accept like this:
+
+    var conf struct {
+        Timeout time.Duration `envconfig:"duration=iso8601"`
+    }
+
+With that struct, PT1H30M is parsed as 1h30m0s. Years and months are rejected because they don't have a fixed length.
+
+Validators
+
+String fields, or slices of strings, can be validated by adding the name of a validator to the tag:
+
+    var conf struct {
+        Country  string `envconfig:",iso3166"`
+        Currency string `envconfig:",iso4217"`
+    }
+
+The available validators are:
+ - iso3166: an ISO 3166-1 alpha-2 country code, like FR
+ - iso4217: an ISO 4217 currency code, like EUR
+ - mimetype: a media type, like text/html; charset=utf-8
+ - extension: a common file extension, like .png, from a list which doesn't depend on the system
+
+A validator also canonicalizes the value: country and currency codes are stored in upper case, media types are
+formatted with mime.FormatMediaType and file extensions are stored in lower case with a leading dot.
+The envconfig.CountryCode, envconfig.CurrencyCode and envconfig.MediaType types do the same validation without a tag.
+
+Probes
+
+The probe option checks that the endpoint configured in a field is reachable before the service starts serving traffic:
+
+    var conf struct {
+        DatabaseAddr string   `envconfig:"probe=tcp"`
+        Brokers      []string `envconfig:"probe=dns"`
+        AuthURL      string   `envconfig:"probe=http"`
+    }
+
+The dns probe resolves the host, the tcp probe opens a connection and the http probe sends a GET request, which must not
+fail with a server error. A value can be a URL, a host:port address or a host. The probes run in parallel once all the fields
+are read, each one with the timeout Options.ProbeTimeout, and Init fails with a *ProbeError for each failure.
+The dsn probe calls the function Options.DSNProbe, which typically opens a connection to the database,
+so that invalid credentials are reported at startup along with the other probes.
+The option SkipProbes disables them.
+
+InitWithChecks also returns the outcome of each validator and probe as Checks, with their durations, to be logged
+at startup. Checks implements http.Handler to be served as a readiness endpoint.
+
+Remaining variables
+
+A field of type map[string]string with the rest tag receives all the variables of its struct which are not consumed by
+another field, without the prefix. It requires a prefix when used in the config struct itself:
+
+    var conf struct {
+        Name  string
+        Extra map[string]string `envconfig:",rest"`
+    }
+
+    envconfig.InitWithPrefix(&conf, "APP")
+
+With APP_NAME=foo and APP_TIMEOUT=1m, conf.Extra is map[TIMEOUT:1m].
+
+The option Strict makes Init fail instead when a variable starting with the prefix doesn't match any field.
+
+Descriptions
+
+A field can be described with the desc option. The description is used by Usage and in error messages.
+It must be the last option of the tag, so that it can contain commas:
+
+    var conf struct {
+        Addr string `envconfig:"default=:8080,desc=Listen address of the HTTP server, like :8080"`
+    }
+
+Usage, WriteUsage, WriteMarkdown and WriteEnvTemplate document the keys of a config struct, including their descriptions.
+WriteMarkdown renders them as a Markdown table, ready to be included in a README or a runbook.
+WriteJSONSchema writes a JSON Schema of the environment, to validate deployments before rolling them out.
+WriteParameterSpec writes the same description as a list of OpenAPI parameters keyed by variable, with the types
+of the fields, for the platforms validating deployment manifests.
+GenerateEnvSpec writes it to a file from a go generate program, so that it is embedded into the binary,
+and HandleEnvSpecFlag prints it when the program runs with the --print-env-spec flag.
+A type implementing DocValuer provides the example value shown by these functions, for custom types whose
+zero value says nothing about the expected format.
+The example option sets the example value of a field instead, and VerifyExamples checks from a test that the example
+of each field can be parsed, so that the documentation never shows a value which would be rejected:
+
+    var conf struct {
+        Timeout time.Duration `envconfig:"example=30s"`
+    }
+Doctor checks the environment against a config struct and reports the missing keys, the values which can't be parsed
+and the suspicious ones, like empty values, and optionally probes the URLs. It is meant to back a doctor subcommand.
+
+Groups of fields
+
+Fields can be gathered in groups which are alternatives to each other: at least one group of the struct must be complete.
+This is useful for services accepting multiple authentication mechanisms:
+
+    var conf struct {
+        Auth struct {
+            User     string `envconfig:"group=basic"`
+            Password string `envconfig:"group=basic"`
+            Token    string `envconfig:"group=token"`
+        }
+    }
+
+With that struct, either AUTH_USER and AUTH_PASSWORD or AUTH_TOKEN must be set. Use a struct per set of alternatives.
+
+Marshaling
+
+Marshal does the opposite of Init: it returns the variables which Init would read into a config struct, by key.
+Environ turns them into the KEY=value form, for example to configure a child process:
+
+    vars, err := envconfig.Marshal(&conf)
+    cmd.Env = append(os.Environ(), envconfig.Environ(vars)...)
+
+AppendEnviron does the same in one call, replacing the inherited variables with the same keys:
+
+    cmd.Env, err = envconfig.AppendEnviron(os.Environ(), &conf.Worker, envconfig.Options{Prefix: "WORKER"})
+
+Types implementing Unmarshaler can implement Marshaler to control how they are marshaled, otherwise their String method is used.
+
+Diff compares two config structs and returns the fields whose values differ, with the values formatted like
+Marshal does. The values of the secret fields are redacted.
+
+Dump writes the effective configuration as KEY="value" lines sorted by key, with the values of the secret fields
+replaced by ***, so that operators can see and compare what an instance runs with:
+
+    envconfig.Dump(&conf, os.Stderr)
+
+InitWithReport also returns a Report telling for each field which key and source provided its value, or whether
+it is a default value or an optional value left unset, along with the keys looked up. DebugHandler serves the masked configuration and its report as JSON on an admin port.
+
+The report also holds the time of the load, the number of default values used and a checksum of the configuration.
+The option OnLoad receives the report of each load, including the reloads of a Store. LastReport keeps the last one
+and implements expvar.Var, so that a fleet can be audited for configuration drift:
+
+    var last envconfig.LastReport
+    expvar.Publish("config", &last)
+    err := envconfig.InitWithOptions(&conf, envconfig.Options{OnLoad: last.Set})
+
+The option Trace is called for each key looked up, with the source which answered, and for the resolution of each
+field, with its masked value or the default value used, to debug why a field doesn't have the expected value.
+The option Logger logs the same diagnostics, and the optional structs disabled, to a structured logger
+like a *slog.Logger.
+
+The option Metrics receives the number of values read and of defaults used by each load, the latency of the
+lookups of each source and the result of the reloads of a Store, to export them to Prometheus or another
+monitoring system.
+
+The deprecated option keeps the former key of a renamed field working: it is read when none of the keys
+of the field is set, and the option OnDeprecated and the Logger are told so that the variable can be renamed
+before the option is removed:
+
+    var conf struct {
+        LogLevel string `envconfig:"APP_LOG_LEVEL,deprecated=APP_LOGLEVEL"`
+    }
+
+FunctionalOptions converts a config struct into the functional options of another library, with a table mapping
+the fields to the constructors of the options:
+
+    opts, err := envconfig.FunctionalOptions(&conf.Redis, map[string]envconfig.OptionFunc[redis.Option]{
+        "Addr": envconfig.Option(redis.WithAddr),
+    })
+
+When a library takes an options struct instead, the option Mappings reads the fields of that struct type, which
+can't be tagged, as if they were struct fields with the given names and tags, and passes their values to setters:
+
+    envconfig.InitWithOptions(&conf, envconfig.Options{
+        Mappings: []envconfig.Mapping{
+            envconfig.MapStruct(
+                envconfig.MapField("Addr", "default=localhost:6379", func(o *redis.Options, v string) { o.Addr = v }),
+            ),
+        },
+    })
+
+WriteEnvrc and WriteDotenv write the values of a config struct as a direnv .envrc file or a .env file,
+using the default values for the fields left empty.
+WritePowerShell and WriteFish write the same values as PowerShell and fish scripts.
+WriteKubernetesManifests writes them as a Kubernetes ConfigMap, with the values of the secret fields in a Secret.
+WriteHelmEnv writes the env of a container as a Helm template instead: the fields with a default value are
+written as is, the secret fields reference the Secret and the others are read from .Values.env.
+
+A PodInfo field reads the identity of the pod from POD_NAME, POD_NAMESPACE, NODE_NAME and POD_IP, and validates it.
+WritePodInfoEnv writes the env of the container setting these variables with the Downward API.
+
+Files
+
+With the option FileKeys, a key which is not set is read from the file named by the same key with the _FILE suffix,
+which is the convention of Docker secrets. With DB_PASSWORD_FILE=/run/secrets/db_password, DB_PASSWORD is
+the content of /run/secrets/db_password without its trailing line break.
+
+The fromFile option makes the value of a single field the path of a file holding the actual value,
+which is useful for certificates and long keys. The trim option removes the leading and trailing
+whitespaces of the value and the base64 option decodes it:
+
+    var conf struct {
+        TLSCert []byte `envconfig:",fromFile"`
+        APIKey  string `envconfig:",fromFile,trim,base64"`
+    }
+
+A byte slice read from a file holds the content of the file, it is only decoded from base64 with the base64 option.
+
+The encrypted option decrypts the value with the Decryptor of the options, so that the environment only holds
+the ciphertext. The encrypted fields are secret:
+
+    var conf struct {
+        DBPassword string `envconfig:",encrypted"`
+    }
+
+    envconfig.InitWithOptions(&conf, envconfig.Options{Decryptor: decryptor})
+
+The subpackage kms provides the decryptors of the values encrypted with AWS KMS and GCP Cloud KMS.
+
+Sources
+
+By default the values are read from the environment. The option Source reads them from somewhere else,
+like a map with MapSource:
+
+    envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{"NAME": "foobar"}})
+
+Chain resolves each key through several sources in order of priority, the first non-empty value winning and
+the default values applying last:
+
+    envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.Chain{envconfig.EnvSource{}, dotenv, remote}})
+
+The errors of the members of a Chain are *SourceError, naming the member which failed. By default a failing member
+fails Init. With the option TolerateSourceFailures it is skipped instead, the fields falling back to the next members
+and to their default values, and only a required field which isn't found fails. OnSourceFailure is called, and the
+Logger warns, for each member skipped.
+
+A source implementing FieldSource also gets the struct tag of each field, to map fields to its own namespace.
+The subpackage vault provides a source reading the secrets from HashiCorp Vault, with a vault tag mapping
+fields to secrets. The subpackage secretsmanager does the same with AWS Secrets Manager, and caches the secrets.
+The subpackage consul reads the values from Consul KV under a prefix, MYSQL_MASTER_ADDRESS being read from
+PREFIX/mysql/master/address, and watches them for changes with blocking queries.
+The subpackage etcd does the same with etcd v3.
+The subpackage httpjson reads the values from a JSON document served over HTTP, flattened into keys.
+The subpackage sops reads the dotenv, YAML and JSON files encrypted with SOPS, decrypting their data key
+with AWS KMS or with the library of another type of key, like age.
+The subpackage awsmeta exposes the metadata of the ECS task or EC2 instance, like REGION and AVAILABILITY_ZONE,
+so that chained after EnvSource the fields default to the values of the platform.
+
+NewCache wraps a source in a Cache, keeping the values for a TTL so that repeated calls to Init or the reloads of
+a Store don't hammer a remote backend. With StaleWhileRevalidate an expired value is still returned while it is
+read again in the background, and Invalidate drops keys from the cache:
+
+    cache := envconfig.NewCache(remote, envconfig.CacheOptions{TTL: time.Minute, StaleWhileRevalidate: time.Minute})
+
+The sources with background components, like a Cache revalidating its values or the watches of the consul and etcd
+sources, implement HealthChecker. A Chain reports the health of its members, so that the health endpoint of an
+application can tell an unhealthy configuration backend apart from its own state:
+
+    if err := envconfig.SourceHealth(source); err != nil {
+        http.Error(w, "config backend: "+err.Error(), http.StatusServiceUnavailable)
+    }
+
+The option Timeout bounds the resolution of the keys: when a source is unreachable, Init fails fast
+with a *DeadlineError reporting the keys already resolved and the key still pending.
+InitContext does the same with the deadline of a context, and gives up when it is canceled. The sources
+implementing ContextSource, like the ones of the subpackages, get the context to abort their requests.
+
+The keys are looked up one at a time. With the option Parallelism, the keys of several fields are looked up
+concurrently, so that the startup latency of a large struct read from a remote source doesn't grow with the
+number of fields.
+
+The serial option of a field makes its keys looked up, and its probe run, while no other field is, for a backend
+requiring a serialized access. The option Serial does the same for all the fields, except the ones with the parallel option.
+
+Services reading their configuration very frequently, like per-tenant overlays or per-request contexts,
+can set the option ReuseBuffers so that Init reuses its internal buffers across the calls instead of
+allocating them each time.
+
+ReadEnvironmentFile reads a file in the format of the EnvironmentFile= setting of systemd units,
+with its own quoting and continuation rules, and WriteEnvironmentFile writes one.
+
+Snapshots
+
+SaveSnapshot saves the values of a config struct to a file and InitFromSnapshot reads them back,
+so that a service can boot from its last known good configuration when its config backend is unreachable.
+WriteSnapshot and ReadSnapshot do the same with an io.Writer and an io.Reader, and can encrypt the snapshot.
+
+Reloading
+
+A Store holds a configuration which can be reloaded with Reload, or periodically with Watch:
+
+    store, err := envconfig.NewStore[Config](envconfig.StoreOptions{
+        OnReloadError: func(err error, failures int) { log.Println(err) },
+    })
+    go store.Watch(ctx, time.Minute)
+
+    conf := store.Get()
+
+A reload producing an invalid configuration never replaces the current one: the store keeps serving the last good
+configuration, or the initial one with the KeepInitial strategy. Stats returns statistics to export as metrics.
+AddCanary registers hooks evaluating a reloaded configuration, for example against live dependencies,
+before it replaces the current one.
+
+Subscribe returns a channel receiving the new configurations. Its overflow policy decides what happens when the
+subscriber is too slow: DropOldest and Coalesce drop older changes but always deliver the latest one, and never
+slow down the reloads, while Block waits for the subscriber:
+
+    changes, cancel := store.Subscribe(1, envconfig.Coalesce)
+    defer cancel()
+    for change := range changes {
+        apply(change.Config)
+    }
+
+Each change carries the fields which differ from the previous configuration in Change.Fields. SubscribeFields
+only receives the changes of some fields, so that a subsystem subscribes to the part of the configuration it
+cares about:
+
+    changes, cancel := store.SubscribeFields(1, envconfig.Coalesce, "Log")
+
+OnFieldChange calls a function with the old and new values of a field when it changes:
+
+    store.OnFieldChange("Log.Level", func(old, new string) { logger.SetLevel(new) })
+
+Shutdown stops the calls to Watch and Reload, canceling the reads of a running reload, and waits for them to return, so that no goroutine is left behind when the
+application stops. The sources watching for changes, like those of the consul and etcd packages, have the same method.
+
+Load, Provide and ProvideStore are constructors for dependency injection frameworks like uber/fx and google/wire,
+and Hooks returns the lifecycle hooks starting and stopping the Watch of a store:
+
+    fx.Provide(envconfig.Provide[Config]("APP"))
+
 Combining options
 
 You can of course combine multiple options. The syntax is simple enough, separate each option with a comma.
@@ -155,6 +575,43 @@ For example:
 
 This would give you the default timeout of 1 minute, and lookup the myTimeout environment variable.
 
+A tag starting with a word other than optional or - names the key, even if the word is an option like secret.
+Start the tag with a comma to use such an option without a custom name, like `envconfig:",secret"`.
+
+A custom name can list alternative keys separated by |, tried in order, so that a field follows the conventions
+of several platforms without duplicate fields. The first key is the canonical one, used by Usage and Marshal:
+
+    var conf struct {
+        Addr string `envconfig:"ADDR|ADDRESS|HOST"`
+    }
+
+A config type implementing OptionsProvider provides its own options, like its prefix and its source, which are
+merged with the options passed to Init, Usage or Marshal, so that a config package encapsulates them:
+
+    func (*Config) EnvconfigOptions() envconfig.Options {
+        return envconfig.Options{Prefix: "APP"}
+    }
+
+The option Variant selects a struct tag overriding the envconfig tags, so that a single struct can carry different
+defaults for different builds or environments:
+
+    var conf struct {
+        Timeout time.Duration `envconfig:"default=1s" onprem:"default=10s"`
+    }
+
+    envconfig.InitWithOptions(&conf, envconfig.Options{Variant: "onprem"})
+
+Code generators can build tags with the Tag type instead of concatenating strings, and parse them with ParseTag:
+
+    envconfig.Tag{Name: "myTimeout", Default: "1m"}.StructTag() // envconfig:"myTimeout,default=1m"
+
+TemplateFuncs returns template functions deriving the keys, default values and descriptions of fields with the same rules as Init.
+
+GeneratePlan writes the plan of a config struct, the parsed tags of its fields, as Go code registering it with RegisterPlan.
+Init then reads the tags from the plan instead of parsing the struct tags, which stay the single source of truth:
+a plan out of date is ignored, and CheckPlan, run in a test, returns an error if the plan is out of date.
+The plan only saves the parsing of the tags, the struct is still walked with reflection.
+
 Supported types
 
 envconfig supports the following list of types:
@@ -165,6 +622,12 @@ envconfig supports the following list of types:
  - uintX
  - floatX
  - time.Duration
+ - envconfig.Window, a recurring weekly time window like "Mon-Fri 09:00-17:00 Europe/Paris"
+ - http.Header, from a comma-separated list of Key:value pairs like "X-Api-Key:foobar,Accept:text/html"
+ - envconfig.Listener, an address to listen on like "tcp://0.0.0.0:8080", "unix:///tmp/app.sock" or "systemd://"
+ - envconfig.Placement, a region or zone of AWS, GCP or Azure like "us-east-1a", "europe-west1" or "eastus2"
+ - envconfig.Range[T], a range of integers, floats or durations like "8000-8080" or "100ms-2s"
+ - envconfig.Globs, a list of glob patterns matching hosts or paths like "*.internal,10.0.*,/api/**"
  - pointers to all of the above types
 
 Notably, we don't (yet) support complex types simply because I had no use for it yet.
@@ -195,5 +658,22 @@ You do this by implementing Unmarshaler on your type. Here's an example:
         return nil
     }
 
+Validation
+
+A struct implementing Validator is validated once all of its fields are read. This is how the helper structs provided
+by envconfig, like CookieConfig, reject invalid combinations of settings.
+
+    type Database struct {
+        URL      string
+        PoolSize int `envconfig:"default=10"`
+    }
+
+    func (d *Database) Validate() error {
+        if d.PoolSize <= 0 {
+            return errors.New("pool size must be positive")
+        }
+        return nil
+    }
+
 */
 package envconfig
@@ -0,0 +1,238 @@
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DoctorOptions are the options of Doctor.
+type DoctorOptions struct {
+	// Options are the options used to read the configuration.
+	Options Options
+
+	// Probe makes Doctor send a request to the http and https URLs to check that they are reachable.
+	Probe bool
+	// ProbeTimeout is the timeout of each probe, 5 seconds by default.
+	ProbeTimeout time.Duration
+	// HTTPClient is the client used by the probes, http.DefaultClient by default.
+	HTTPClient *http.Client
+
+	// Color colors the report with ANSI escape codes, for terminals.
+	Color bool
+}
+
+// doctorLevel is the level of a line of the report of Doctor.
+type doctorLevel int
+
+const (
+	doctorOK doctorLevel = iota
+	doctorWarn
+	doctorError
+)
+
+func (l doctorLevel) String() string {
+	switch l {
+	case doctorWarn:
+		return "WARN"
+	case doctorError:
+		return "ERROR"
+	default:
+		return "OK"
+	}
+}
+
+func (l doctorLevel) color() string {
+	switch l {
+	case doctorWarn:
+		return "\x1b[33m"
+	case doctorError:
+		return "\x1b[31m"
+	default:
+		return "\x1b[32m"
+	}
+}
+
+// Doctor checks the environment against the conf object and writes a report to w, with a line for each key:
+// missing keys and values which can't be parsed are errors, values set but empty or surrounded by spaces are
+// warnings. With the option Probe, the http and https URLs are probed and the unreachable ones are errors.
+// conf must be a pointer, it is not modified. The values of the secret fields are never written.
+//
+// It returns false if there is an error. It is meant to be wired as a subcommand of a program:
+//
+//	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+//		ok, err := envconfig.Doctor(os.Stdout, &conf, envconfig.DoctorOptions{Probe: true, Color: true})
+//		...
+//	}
+func Doctor(w io.Writer, conf interface{}, opts DoctorOptions) (bool, error) {
+	fields, err := describe(conf, opts.Options)
+	if err != nil {
+		return false, err
+	}
+
+	// read the configuration in a new value to collect the errors
+	fresh := reflect.New(reflect.TypeOf(conf).Elem())
+	initErr := InitWithOptions(fresh.Interface(), opts.Options)
+
+	fieldErrs := make(map[string]error)
+	var otherErrs []error
+	for _, err := range unjoin(initErr) {
+		var missing *MissingKeyError
+		var parse *ParseError
+		switch {
+		case errors.As(err, &missing):
+			fieldErrs[missing.Field] = err
+		case errors.As(err, &parse):
+			fieldErrs[parse.Field] = err
+		default:
+			otherErrs = append(otherErrs, err)
+		}
+	}
+
+	st := &state{opts: opts.Options}
+	source := st.source()
+
+	healthy := true
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	line := func(level doctorLevel, key, msg string) {
+		if level == doctorError {
+			healthy = false
+		}
+		label := level.String()
+		if opts.Color {
+			label = level.color() + label + "\x1b[0m"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", label, key, msg)
+	}
+
+	for _, f := range fields {
+		if f.rest {
+			continue
+		}
+
+		if err, ok := fieldErrs[f.path]; ok {
+			line(doctorError, f.key, strings.TrimPrefix(err.Error(), "envconfig: "))
+			continue
+		}
+
+		value, key, empty := doctorLookup(sourceLookup(context.Background(), source, f.tag), f.keys)
+		display := value
+		if f.secret {
+			display = redactValue(f.redact, value)
+			if display == "" {
+				display = "(secret)"
+			}
+		}
+
+		switch {
+		case value == "" && f.defaultVal != "":
+			line(doctorOK, f.key, "default "+f.defaultVal)
+		case value == "" && empty != "":
+			line(doctorWarn, empty, "set but empty, it is ignored")
+		case value == "":
+			line(doctorOK, f.key, "not set")
+		case strings.TrimSpace(value) != value:
+			line(doctorWarn, key, "has leading or trailing spaces")
+		default:
+			if opts.Probe {
+				if probeErr := probeURL(value, opts); probeErr != nil {
+					line(doctorError, key, "unreachable: "+redactURLError(probeErr, value, display, f.secret))
+					continue
+				}
+			}
+			line(doctorOK, key, display)
+		}
+	}
+
+	for _, err := range otherErrs {
+		line(doctorError, "-", strings.TrimPrefix(err.Error(), "envconfig: "))
+	}
+
+	return healthy, tw.Flush()
+}
+
+// unjoin returns the errors joined in err, or err alone.
+func unjoin(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(interface{ Unwrap() []error }); ok {
+		return e.Unwrap()
+	}
+	return []error{err}
+}
+
+// doctorLookup returns the first value found for keys with lookup and its key,
+// along with the first key set to an empty value if any.
+func doctorLookup(lookup func(key string) (string, bool, error), keys []string) (value, key, empty string) {
+	for _, k := range keys {
+		v, ok, err := lookup(k)
+		switch {
+		case err != nil:
+		case v != "":
+			return v, k, empty
+		case ok && empty == "":
+			empty = k
+		}
+	}
+	return "", "", empty
+}
+
+// probeURL sends a request to the value if it is an http or https URL.
+// It returns an error if the server is not reachable or replies with a server error.
+func probeURL(value string, opts DoctorOptions) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil
+	}
+
+	timeout := opts.ProbeTimeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, value, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return errors.New(resp.Status)
+	}
+
+	return nil
+}
+
+// redactURLError returns the message of an error of a probe, with the URL replaced by display if it is a secret.
+func redactURLError(err error, value, display string, secret bool) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	msg := err.Error()
+	if secret {
+		msg = strings.ReplaceAll(msg, value, display)
+	}
+	return msg
+}
@@ -0,0 +1,104 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestDoctor(t *testing.T) {
+	var conf struct {
+		Name     string
+		Port     int
+		Timeout  string `envconfig:"optional"`
+		Region   string `envconfig:"default=eu-west-1"`
+		Host     string
+		Password string `envconfig:",secret"`
+		Token    string
+	}
+
+	source := envconfig.MapSource{
+		"APP_NAME":     "foobar",
+		"APP_PORT":     "80a",
+		"APP_TIMEOUT":  "",
+		"APP_HOST":     " localhost",
+		"APP_PASSWORD": "hunter2",
+	}
+
+	var buf bytes.Buffer
+	ok, err := envconfig.Doctor(&buf, &conf, envconfig.DoctorOptions{
+		Options: envconfig.Options{Prefix: "APP", Source: source},
+	})
+	require.Nil(t, err)
+	require.False(t, ok)
+
+	exp := `OK     APP_NAME      foobar
+ERROR  APP_PORT      unable to parse APP_PORT (field Port): strconv.ParseInt: parsing "80a": invalid syntax
+WARN   APP_TIMEOUT   set but empty, it is ignored
+OK     APP_REGION    default eu-west-1
+WARN   APP_HOST      has leading or trailing spaces
+OK     APP_PASSWORD  (secret)
+ERROR  APP_TOKEN     keys APP_TOKEN, app_token not found (field Token)
+`
+	require.Equal(t, exp, buf.String())
+}
+
+func TestDoctorProbe(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	var conf struct {
+		Up   string
+		Down string
+	}
+
+	var buf bytes.Buffer
+	ok, err := envconfig.Doctor(&buf, &conf, envconfig.DoctorOptions{
+		Options: envconfig.Options{Source: envconfig.MapSource{"UP": up.URL, "DOWN": down.URL}},
+		Probe:   true,
+		Color:   true,
+	})
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.Contains(t, buf.String(), "\x1b[32mOK\x1b[0m")
+	require.Contains(t, buf.String(), "DOWN  unreachable: 502 Bad Gateway")
+}
+
+// tagSource maps the fields with a store tag to the keys of the tag, like the vault and secretsmanager sources.
+type tagSource envconfig.MapSource
+
+func (s tagSource) Lookup(key string) (string, bool, error) {
+	v, ok := s[key]
+	return v, ok, nil
+}
+
+func (s tagSource) LookupField(key string, tag reflect.StructTag) (string, bool, error) {
+	if k, ok := tag.Lookup("store"); ok {
+		key = k
+	}
+	return s.Lookup(key)
+}
+
+func TestDoctorFieldSource(t *testing.T) {
+	var conf struct {
+		Name     string
+		Password string `envconfig:",secret" store:"db/password"`
+	}
+
+	var buf bytes.Buffer
+	ok, err := envconfig.Doctor(&buf, &conf, envconfig.DoctorOptions{
+		Options: envconfig.Options{Source: tagSource{"NAME": "foobar", "db/password": "hunter2"}},
+	})
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, "OK  NAME      foobar\nOK  PASSWORD  (secret)\n", buf.String())
+}
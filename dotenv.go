@@ -0,0 +1,81 @@
+package envconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteEnvTemplate writes a commented .env template for the conf object and opts to w.
+// conf must be a pointer.
+//
+// Each key is preceded by its description, its type and whether it is required.
+// Required keys are left empty for you to fill, optional keys are commented out with their default value:
+//
+//	# Listen address of the HTTP server
+//	# string, optional
+//	# APP_ADDR=:8080
+//
+//	# string, required
+//	APP_NAME=
+func WriteEnvTemplate(w io.Writer, conf interface{}, opts Options) error {
+	fields, err := describe(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	first := true
+	for _, f := range fields {
+		if f.rest {
+			continue
+		}
+
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+
+		if f.description != "" {
+			fmt.Fprintf(bw, "# %s\n", f.description)
+		}
+
+		typ := f.typ.String()
+		if f.example != "" {
+			typ += " like " + f.example
+		}
+
+		if f.optional || f.defaultVal != "" {
+			fmt.Fprintf(bw, "# %s, optional\n", typ)
+			fmt.Fprintf(bw, "# %s=%s\n", f.key, quoteDotenv(f.defaultVal))
+		} else {
+			fmt.Fprintf(bw, "# %s, required\n", typ)
+			fmt.Fprintf(bw, "%s=\n", f.key)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// WriteDotenv writes the values of the conf object with opts to w as a .env file, for example to export
+// the resolved configuration of a running service. conf must be a pointer.
+//
+// The keys are written in the order Init reads them, preceded by their description if any.
+// The fields left empty get their default value. Values are quoted when needed to be read back.
+func WriteDotenv(w io.Writer, conf interface{}, opts Options) error {
+	return writeExport(w, conf, opts, func(key, value string) string {
+		return key + "=" + quoteDotenv(value)
+	})
+}
+
+// quoteDotenv quotes the value if needed to be read back from a .env file.
+func quoteDotenv(s string) string {
+	if s == "" || !strings.ContainsAny(s, " \t\n\r\"'#$\\`") {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, `$`, `\$`, "`", "\\`")
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,33 @@
+package envconfig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteDotenv(t *testing.T) {
+	require.Equal(t, "", quoteDotenv(""))
+	require.Equal(t, "foobar", quoteDotenv("foobar"))
+	require.Equal(t, "postgres://localhost:5432/db?sslmode=disable", quoteDotenv("postgres://localhost:5432/db?sslmode=disable"))
+	require.Equal(t, `"foo bar"`, quoteDotenv("foo bar"))
+	require.Equal(t, `"a \"b\" \$HOME\nc"`, quoteDotenv("a \"b\" $HOME\nc"))
+	require.Equal(t, `"#comment"`, quoteDotenv("#comment"))
+}
+
+func TestWriteDotenv(t *testing.T) {
+	conf := exportConfig{
+		Addr: "localhost:8080",
+		Name: "foo bar",
+	}
+
+	var buf bytes.Buffer
+	err := WriteDotenv(&buf, &conf, Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, `# Listen address of the HTTP server
+APP_ADDR=localhost:8080
+APP_NAME="foo bar"
+APP_DEBUG=false
+`, buf.String())
+}
@@ -0,0 +1,55 @@
+package envconfig
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Dump writes the configuration conf to w, one KEY="value" line per field sorted by key, so that the effective
+// configuration of an instance can be logged, served on a debug endpoint or compared with another one with diff.
+// The values are formatted like Marshal does and quoted like Go strings.
+//
+// The values of the secret fields are replaced by ***, or redacted by the redact option of the field,
+// and the empty ones are written as "". Unset pointers are left out.
+func Dump(conf interface{}, w io.Writer) error {
+	return DumpWithOptions(conf, w, Options{})
+}
+
+// DumpWithOptions is like Dump, with the keys of the fields built with opts like MarshalWithOptions does.
+// The option RedactValues makes all fields secret.
+func DumpWithOptions(conf interface{}, w io.Writer, opts Options) error {
+	fields, err := marshalFields(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]marshaledField, 0, len(fields))
+	for _, f := range fields {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].key != sorted[j].key {
+			return sorted[i].key < sorted[j].key
+		}
+		return sorted[i].path < sorted[j].path
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, f := range sorted {
+		bw.WriteString(f.key)
+		bw.WriteByte('=')
+		switch {
+		case f.secret && f.redact != "":
+			bw.WriteString(strconv.Quote(redactValue(f.redact, f.value)))
+		case f.secret && f.value != "":
+			bw.WriteString(secretMask)
+		default:
+			bw.WriteString(strconv.Quote(f.value))
+		}
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
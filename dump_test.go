@@ -0,0 +1,57 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestDump(t *testing.T) {
+	type logConfig struct {
+		Level string
+	}
+	conf := struct {
+		Name        string
+		Timeout     time.Duration
+		Log         logConfig
+		Password    string `envconfig:",secret"`
+		Token       envconfig.Secret[string]
+		APIKey      string `envconfig:",secret"`
+		DatabaseURL string `envconfig:"redact=url"`
+		Ports       []int
+		Proxy       *string
+	}{
+		Name:        "foo \"bar\"",
+		Timeout:     10 * time.Second,
+		Log:         logConfig{Level: "debug"},
+		Password:    "foobar",
+		Token:       envconfig.NewSecret("token"),
+		DatabaseURL: "postgres://app:secret@db/app",
+		Ports:       []int{80, 443},
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.Dump(conf, &buf)
+	require.Nil(t, err)
+	require.Equal(t, `APIKEY=""
+DATABASEURL="postgres://app:xxxxx@db/app"
+LOG_LEVEL="debug"
+NAME="foo \"bar\""
+PASSWORD=***
+PORTS="80,443"
+TIMEOUT="10s"
+TOKEN=***
+`, buf.String())
+
+	buf.Reset()
+	err = envconfig.DumpWithOptions(&conf, &buf, envconfig.Options{Prefix: "APP", RedactValues: true})
+	require.Nil(t, err)
+	require.Contains(t, buf.String(), "APP_NAME=***\n")
+	require.Contains(t, buf.String(), "APP_APIKEY=\"\"\n")
+
+	err = envconfig.Dump("foo", &buf)
+	require.Equal(t, envconfig.ErrInvalidValueKind, err)
+}
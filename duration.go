@@ -0,0 +1,142 @@
+package envconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	durationFormatGo      = ""
+	durationFormatISO8601 = "iso8601"
+)
+
+// parseISO8601Duration parses a duration in the ISO 8601 format, like PT1H30M or P1DT12H.
+//
+// Years and months don't have a fixed length so they are rejected, a day is always 24 hours
+// and a week 7 days. Only the smallest unit may have a fraction, like PT1.5S.
+// Each unit appears at most once, from the largest to the smallest.
+func parseISO8601Duration(str string) (time.Duration, error) {
+	s := str
+
+	var neg bool
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", str)
+	}
+	s = s[1:]
+
+	var (
+		d        float64
+		inTime   bool
+		fraction bool
+		// last is the previous unit, the units must be strictly decreasing
+		last time.Duration
+	)
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", str)
+			}
+			inTime = true
+			s = s[1:]
+			continue
+		}
+
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if i <= 0 || fraction {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", str)
+		}
+
+		number := strings.Replace(s[:i], ",", ".", 1)
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", str)
+		}
+		fraction = strings.Contains(number, ".")
+
+		var unit time.Duration
+		switch {
+		case s[i] == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case s[i] == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case s[i] == 'H' && inTime:
+			unit = time.Hour
+		case s[i] == 'M' && inTime:
+			unit = time.Minute
+		case s[i] == 'S' && inTime:
+			unit = time.Second
+		case s[i] == 'Y' || s[i] == 'M':
+			return 0, fmt.Errorf("ISO 8601 duration %q uses years or months which have no fixed length", str)
+		default:
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q", str)
+		}
+		if last != 0 && unit >= last {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q, the units must appear once from the largest to the smallest", str)
+		}
+
+		d += n * float64(unit)
+		last = unit
+		s = s[i+1:]
+	}
+
+	if last == 0 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", str)
+	}
+
+	if neg {
+		d = -d
+	}
+
+	return time.Duration(d), nil
+}
+
+// formatISO8601Duration formats a duration in the ISO 8601 format accepted by parseISO8601Duration,
+// like PT1H30M or P1DT12H.
+func formatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+		d -= minutes * time.Minute
+	}
+	if d > 0 {
+		b.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+		b.WriteByte('S')
+	}
+
+	return b.String()
+}
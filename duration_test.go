@@ -0,0 +1,67 @@
+package envconfig
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseISO8601Duration(t *testing.T) {
+	testCases := []struct {
+		str string
+		exp time.Duration
+	}{
+		{"PT1H30M", time.Hour + 30*time.Minute},
+		{"PT0.5S", 500 * time.Millisecond},
+		{"PT1,5S", 1500 * time.Millisecond},
+		{"P1DT12H", 36 * time.Hour},
+		{"P2W", 14 * 24 * time.Hour},
+		{"PT90S", 90 * time.Second},
+		{"-PT10M", -10 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		d, err := parseISO8601Duration(tc.str)
+		require.Nil(t, err, tc.str)
+		require.Equal(t, tc.exp, d, tc.str)
+	}
+
+	for _, str := range []string{"", "P", "PT", "1H", "PT1D", "P1H", "PT1.5H30M", "P1Y", "P1M", "PTM", "P1DT"} {
+		_, err := parseISO8601Duration(str)
+		require.NotNil(t, err, str)
+	}
+
+	for _, str := range []string{"PT1H1H", "PT1S1H", "PT1M1H", "P1D1D", "P1D1W", "P1DT1H1M1S1S"} {
+		_, err := parseISO8601Duration(str)
+		require.NotNil(t, err, str)
+		require.Equal(t, fmt.Sprintf("invalid ISO 8601 duration %q, the units must appear once from the largest to the smallest", str), err.Error())
+	}
+
+	d, err := parseISO8601Duration("P1W1DT1H1M1S")
+	require.Nil(t, err)
+	require.Equal(t, 8*24*time.Hour+time.Hour+time.Minute+time.Second, d)
+}
+
+func TestFormatISO8601Duration(t *testing.T) {
+	testCases := []struct {
+		d   time.Duration
+		exp string
+	}{
+		{0, "PT0S"},
+		{time.Hour + 30*time.Minute, "PT1H30M"},
+		{500 * time.Millisecond, "PT0.5S"},
+		{36 * time.Hour, "P1DT12H"},
+		{-10 * time.Minute, "-PT10M"},
+	}
+
+	for _, tc := range testCases {
+		str := formatISO8601Duration(tc.d)
+		require.Equal(t, tc.exp, str)
+
+		d, err := parseISO8601Duration(str)
+		require.Nil(t, err)
+		require.Equal(t, tc.d, d)
+	}
+}
@@ -1,7 +1,7 @@
 package envconfig
 
 import (
-	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -10,12 +10,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 )
 
 var (
 	// ErrUnexportedField is the error returned by the Init* functions when a field of the config struct is not exported and the option AllowUnexported is not used.
+	// The returned error wraps it to include the path of the field, use errors.Is to check for it.
 	ErrUnexportedField = errors.New("envconfig: unexported field")
 	// ErrNotAPointer is the error returned by the Init* functions when the configuration object is not a pointer.
 	ErrNotAPointer = errors.New("envconfig: value is not a pointer")
@@ -23,16 +25,75 @@ var (
 	ErrInvalidValueKind = errors.New("envconfig: invalid value kind, only works on structs")
 	// ErrDefaultUnsupportedOnSlice is the error returned by the Init* functions when there is a default tag on a slice.
 	// The `default` tag is unsupported on slices because slice parsing uses , as the separator, as does the envconfig tags separator.
+	// The returned error wraps it to include the path of the field, use errors.Is to check for it.
 	ErrDefaultUnsupportedOnSlice = errors.New("envconfig: default tag unsupported on slice")
+	// ErrNoDecryptor is the error returned by the Init* functions when a field has the encrypted option
+	// and the option Decryptor is not set. The returned error wraps it to include the path of the field.
+	ErrNoDecryptor = errors.New("envconfig: no Decryptor for the encrypted field")
 )
 
-type context struct {
-	name               string
-	customName         string
-	defaultVal         string
-	parents            []reflect.Value
-	optional, leaveNil bool
-	allowUnexported    bool
+// fieldContext holds what is known about the field being read, inherited from its parents.
+type fieldContext struct {
+	name           string
+	path           string
+	customName     string
+	defaultVal     string
+	durationFormat string
+	validators     []string
+	probe          string
+	redact         string
+	description    string
+	deprecated     string
+	parents        []reflect.Value
+	optional       bool
+	secret         bool
+	fromFile       bool
+	trim           bool
+	decodeBase64   bool
+	encrypted      bool
+	csv            bool
+	// serial is set if the lookups and the probe of the field must not run concurrently with others.
+	serial bool
+	// structTag is the struct tag of the field, for the sources implementing FieldSource.
+	structTag reflect.StructTag
+	// origin is the source which provided the value of the field, set by readValue.
+	// fileKey is set if the value was read from the file named by the key with the _FILE suffix.
+	origin  Source
+	fileKey bool
+	// missing collects the keys not found in an optional struct, which would be required otherwise.
+	missing *[]string
+	*state
+}
+
+// state is shared by all the contexts of a single Init call.
+type state struct {
+	opts Options
+	errs []error
+	// keys are all the keys looked up.
+	keys map[string]struct{}
+	rest []restField
+	// lookupCtx is the context of the lookups, abortErr is set once it is done.
+	// resolved are the keys found.
+	lookupCtx context.Context
+	abortErr  error
+	resolved  []string
+	// probes are the probes to run once the fields are read.
+	probes []probe
+	// checks are the outcomes of the validators and the probes.
+	checks Checks
+	// report collects how the fields are read, if it is not nil.
+	report *Report
+	// prefetched are the results of the lookups done by prefetch, by key and struct tag.
+	prefetchMu sync.Mutex
+	prefetched map[cacheKey]lookupResult
+	// scratch are the buffers reused across the reads, nil unless Options.ReuseBuffers is set.
+	scratch *scratch
+	// failures are the errors of the sources skipped by the lookups, see readValue.
+	failures []*SourceError
+	// failed are the failed members of the Chain, skipped by the next lookups, nil unless TolerateSourceFailures is set.
+	failed *failedSources
+	// plan are the tags of the registered plan of the config struct by path, nil if there is none or it is stale.
+	plan map[string]Tag
 }
 
 // Unmarshaler is the interface implemented by objects that can unmarshal
@@ -41,6 +102,96 @@ type Unmarshaler interface {
 	Unmarshal(s string) error
 }
 
+// Marshaler is the interface implemented by objects that can marshal themselves
+// to an environment variable string, the inverse of Unmarshaler.
+//
+// Marshal uses the String method of an Unmarshaler which doesn't implement Marshaler.
+type Marshaler interface {
+	Marshal() (string, error)
+}
+
+// DocValuer is the interface implemented by types which provide an example value for the documentation,
+// like Usage or WriteMarkdown. It is useful for types whose zero value says nothing about the expected format.
+type DocValuer interface {
+	DocValue() string
+}
+
+// Validator is the interface implemented by structs which can validate themselves.
+// Validate is called once all fields of the struct were read without error.
+type Validator interface {
+	Validate() error
+}
+
+// OptionsProvider is the interface implemented by config types which provide their own options, like their prefix
+// and their source, so that the callers of Init don't have to repeat them:
+//
+//	func (*Config) EnvconfigOptions() envconfig.Options {
+//		return envconfig.Options{Prefix: "APP", FileKeys: true}
+//	}
+//
+// The options are merged with those passed to Init, Usage or Marshal: an option set by the caller takes precedence.
+type OptionsProvider interface {
+	EnvconfigOptions() Options
+}
+
+// withProvidedOptions returns opts completed with the options provided by conf, if it implements OptionsProvider.
+// A boolean option can't be disabled by the caller once conf enables it.
+func withProvidedOptions(conf interface{}, opts Options) Options {
+	p, ok := conf.(OptionsProvider)
+	if !ok {
+		return opts
+	}
+
+	provided := reflect.ValueOf(p.EnvconfigOptions())
+	res := reflect.ValueOf(&opts).Elem()
+	for i := 0; i < res.NumField(); i++ {
+		if res.Field(i).IsZero() {
+			res.Field(i).Set(provided.Field(i))
+		}
+	}
+	return opts
+}
+
+// Disabled describes an optional struct which is disabled because some of its fields are missing.
+// See Options.OnDisabled.
+type Disabled struct {
+	// Field is the path of the struct in the config struct, for example Tracing.
+	Field string
+	// Keys are the canonical keys of the missing fields.
+	Keys []string
+}
+
+func (d Disabled) String() string {
+	return fmt.Sprintf("%s disabled: %s not set", d.Field, strings.Join(d.Keys, ", "))
+}
+
+// DeprecatedKey describes a field read from its deprecated key. See Options.OnDeprecated.
+type DeprecatedKey struct {
+	// Field is the path of the field in the config struct, for example Log.Level.
+	Field string
+	// Key is the deprecated key and Replacement the canonical key of the field.
+	Key         string
+	Replacement string
+}
+
+func (d DeprecatedKey) String() string {
+	return fmt.Sprintf("%s is deprecated, use %s instead", d.Key, d.Replacement)
+}
+
+// SourceFailure describes a member of a Chain which failed while reading a field, and was skipped.
+// See Options.TolerateSourceFailures.
+type SourceFailure struct {
+	// Field is the path of the field in the config struct, for example Database.Password.
+	Field string
+	// Source is the name of the member of the Chain and Err its error.
+	Source string
+	Err    error
+}
+
+func (f SourceFailure) String() string {
+	return fmt.Sprintf("%s skipped for field %s: %v", f.Source, f.Field, f.Err)
+}
+
 // Options is used to customize the behavior of envconfig. Use it with InitWithOptions.
 type Options struct {
 	// Prefix allows specifying a prefix for each key.
@@ -74,6 +225,162 @@ type Options struct {
 
 	// AllowUnexported allows unexported fields to be present in the passed config.
 	AllowUnexported bool
+
+	// ExistingAsDefaults makes the values already present in the passed config act as defaults.
+	// A field holding a non-zero value is only overwritten if its key is found, and is never
+	// reported as missing. This allows defining defaults in Go code instead of in the struct tags.
+	//
+	//	conf := Config{Timeout: time.Minute}
+	//	envconfig.InitWithOptions(&conf, Options{ExistingAsDefaults: true})
+	ExistingAsDefaults bool
+
+	// Strict makes Init fail if an environment variable starting with the prefix doesn't match any field.
+	// This catches stale and misspelled variables. It has no effect without a prefix.
+	Strict bool
+
+	// OnDisabled is called for each optional struct which is disabled because some of its
+	// fields, which would be required otherwise, are missing. Use it to log degraded functionality:
+	//
+	//	Options{OnDisabled: func(d Disabled) { log.Println(d) }}
+	OnDisabled func(d Disabled)
+
+	// TolerateSourceFailures makes Init skip a member of a Chain which fails, like a remote source which is down,
+	// instead of failing: the keys are looked up in the next members, and the field falls back to its default
+	// value if it has one or is optional. A required field which isn't found fails with the error of the source.
+	TolerateSourceFailures bool
+
+	// OnSourceFailure is called for each member of a Chain skipped while reading a field, once per field
+	// and source, with the option TolerateSourceFailures.
+	//
+	//	Options{OnSourceFailure: func(f SourceFailure) { log.Println(f) }}
+	OnSourceFailure func(f SourceFailure)
+
+	// OnDeprecated is called for each field read from the deprecated key of its tag, because none of its keys
+	// is set. Use it to warn about the variables to rename:
+	//
+	//	var conf struct {
+	//		LogLevel string `envconfig:"LOG_LEVEL,deprecated=LOGLEVEL"`
+	//	}
+	//	envconfig.InitWithOptions(&conf, Options{OnDeprecated: func(d DeprecatedKey) { log.Println(d) }})
+	OnDeprecated func(d DeprecatedKey)
+
+	// Mappings describe how to read the struct types which can't be tagged, see MapStruct.
+	Mappings []Mapping
+
+	// RedactValues makes all fields behave as if they had the secret tag: their values
+	// are never included in error messages.
+	RedactValues bool
+
+	// Source is where the values are read from instead of the environment, see MapSource.
+	Source Source
+
+	// Timeout is the maximum duration of the resolution of the keys. When it is exceeded, Init fails fast
+	// with a *DeadlineError reporting the keys resolved and the one still pending, instead of waiting
+	// for a source which is unreachable.
+	Timeout time.Duration
+
+	// Parallelism is the number of fields whose keys are looked up concurrently, so that the startup latency
+	// of a large struct read from a remote source doesn't grow with the number of fields.
+	// The keys are looked up one at a time if it is less than 2. The source must be safe for concurrent use.
+	Parallelism int
+
+	// SnakeCase makes the canonical key of a field split the words of the names, like MAX_RETRIES for MaxRetries
+	// or HTTP_SERVER for HTTPServer, instead of concatenating them, like MAXRETRIES. Both keys are looked up,
+	// the canonical one first.
+	SnakeCase bool
+
+	// ValidateKeys makes Init check the keys of all the fields before reading any of them, and fail with
+	// a *KeyNameError for each key which isn't a portable name of environment variable: ASCII letters, digits
+	// and underscores, not starting with a digit. It catches the field names which can't be set from the
+	// environment, like the ones with accented letters.
+	ValidateKeys bool
+
+	// MaxKeyLength makes Init check the length of the keys of all the fields before reading any of them, if positive,
+	// and fail with a *KeyNameError for each key longer than it. It is checked with or without ValidateKeys.
+	MaxKeyLength int
+
+	// Acronyms are acronyms kept as single words by SnakeCase, in addition to the common ones like API, HTTP, ID
+	// or TLS, so that GRPCAPIKey gives GRPC_API_KEY. They are written in upper case, like OIDC.
+	Acronyms []string
+
+	// StrictCase makes Init look up the canonical key of each field only, like APP_NAME, and not its lower case
+	// and other variants like app_name. It halves the lookups and avoids matching unintended variables on the
+	// platforms where the environment is case insensitive. The custom names of the tags are all looked up.
+	StrictCase bool
+
+	// KeyNames returns the keys of a field, tried in order, from the chain of names of the prefix, its parents
+	// and the field itself, like [APP Section Key]. The first key is the canonical one. It replaces the built-in
+	// naming, for conventions like APP__SECTION__KEY, except for the fields with a custom name. The built-in
+	// naming applies if it returns no key.
+	KeyNames func(path []string) []string
+
+	// Serial makes the lookups and the probes of the fields run one at a time, as with the serial option of the tags,
+	// except for the fields with the parallel option, for a backend requiring a serialized access.
+	Serial bool
+
+	// NoFlatten makes the embedded structs prefix the keys of their fields with their name, like the other
+	// nested structs, instead of flattening them into their parent. The squash option of a field flattens it anyway.
+	NoFlatten bool
+
+	// ReuseBuffers makes Init reuse its internal buffers across the calls, through a sync.Pool, to reduce
+	// the allocations of the configurations read very frequently, like per-tenant overlays.
+	ReuseBuffers bool
+
+	// FileKeys makes Init read the value of a key which is not set from the file named by the same key
+	// with the _FILE suffix, trimming the trailing line break. This is the convention of Docker secrets:
+	//
+	//	$ DB_PASSWORD_FILE=/run/secrets/db_password ./program
+	FileKeys bool
+
+	// ProbeTimeout is the timeout of each probe of the fields with the probe option, 5 seconds by default.
+	ProbeTimeout time.Duration
+	// DSNProbe is the probe of the fields with the probe=dsn option. It typically opens a connection
+	// to the database and pings it, so that invalid credentials are reported at startup:
+	//
+	//	DSNProbe: func(ctx context.Context, dsn string) error {
+	//		db, err := sql.Open("postgres", dsn)
+	//		if err != nil {
+	//			return err
+	//		}
+	//		defer db.Close()
+	//		return db.PingContext(ctx)
+	//	}
+	DSNProbe func(ctx context.Context, dsn string) error
+	// SkipProbes disables the probes, for example to work offline.
+	SkipProbes bool
+
+	// Variant is the name of a struct tag overriding the envconfig tags, to build variants of a config struct
+	// with different defaults. The options of the variant tag replace the options of the envconfig tag:
+	//
+	//	var conf struct {
+	//		Timeout time.Duration `envconfig:"default=1s" onprem:"default=10s"`
+	//	}
+	//	envconfig.InitWithOptions(&conf, Options{Variant: "onprem"})
+	Variant string
+
+	// Decryptor decrypts the values of the fields with the encrypted option.
+	Decryptor Decryptor
+
+	// Trace is called at each step of the resolution of the fields: the lookup of each key and the resolution
+	// of the value of each field, with the source which provided it or the default value used. The values
+	// of the secret fields are masked. Use it to find out why a field doesn't have the expected value:
+	//
+	//	Options{Trace: func(ev envconfig.TraceEvent) { log.Printf("%+v", ev) }}
+	Trace func(ev TraceEvent)
+
+	// Logger receives the diagnostics of Init: the values read, the default values used and the optional values
+	// not set at the debug level, and the optional structs disabled at the warn level. The secret values are masked.
+	// A *slog.Logger can be used:
+	//
+	//	Options{Logger: slog.Default()}
+	Logger Logger
+
+	// Metrics receives the metrics of the reads of the configuration, see Metrics.
+	Metrics Metrics
+
+	// OnLoad is called with the report of each successful Init, including the reloads of a Store before
+	// the canaries run. Use it to publish the metadata of the configuration, see LastReport.
+	OnLoad func(r *Report)
 }
 
 // Init reads the configuration from environment variables and populates the conf object. conf must be a pointer
@@ -89,82 +396,358 @@ func InitWithPrefix(conf interface{}, prefix string) error {
 
 // InitWithOptions reads the configuration from environment variables and populates the conf object.
 // conf must be a pointer.
+//
+// Every missing key and every value which can't be parsed is reported: if there is more than one
+// such error, the returned error joins them all (see errors.Join).
 func InitWithOptions(conf interface{}, opts Options) error {
+	_, err := initWithChecks(context.Background(), conf, opts, nil)
+	return err
+}
+
+// InitContext is like InitWithOptions, with a context bounding the lookups and the probes.
+// When the context is done, Init gives up: if its deadline is exceeded it returns a *DeadlineError,
+// otherwise the error of the context. The sources implementing ContextSource get the context
+// to abort their requests.
+func InitContext(ctx context.Context, conf interface{}, opts Options) error {
+	_, err := initWithChecks(ctx, conf, opts, nil)
+	return err
+}
+
+// InitWithChecks is like InitWithOptions, and also returns the outcome of each validator and probe,
+// to be logged at startup or served by a readiness endpoint. The checks which failed are also
+// reported by the returned error.
+func InitWithChecks(conf interface{}, opts Options) (Checks, error) {
+	return initWithChecks(context.Background(), conf, opts, nil)
+}
+
+// initWithChecks reads conf, adding the fields to report if it is not nil.
+func initWithChecks(parent context.Context, conf interface{}, opts Options, report *Report) (Checks, error) {
+	opts = withProvidedOptions(conf, opts)
+	if report == nil && (opts.OnLoad != nil || opts.Metrics != nil) {
+		report = &Report{Loaded: time.Now()}
+	}
+
 	value := reflect.ValueOf(conf)
 	if value.Kind() != reflect.Ptr {
-		return ErrNotAPointer
+		return nil, ErrNotAPointer
 	}
 
 	elem := value.Elem()
 
-	ctx := context{
-		name:            opts.Prefix,
-		optional:        opts.AllOptional,
-		leaveNil:        opts.LeaveNil,
-		allowUnexported: opts.AllowUnexported,
+	for _, m := range opts.Mappings {
+		if m.err != nil {
+			return nil, m.err
+		}
+	}
+
+	if opts.ValidateKeys || opts.MaxKeyLength > 0 {
+		fields, err := describe(conf, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := joinErrors(checkKeyNames(fields, opts.ValidateKeys, opts.MaxKeyLength)); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := fieldContext{
+		name:     opts.Prefix,
+		optional: opts.AllOptional,
+		secret:   opts.RedactValues,
+		state: &state{
+			opts:   opts,
+			report: report,
+		},
+	}
+	if opts.TolerateSourceFailures {
+		ctx.failed = &failedSources{}
+	}
+	if opts.ReuseBuffers {
+		ctx.scratch = scratchPool.Get().(*scratch)
+		defer ctx.scratch.release()
+		ctx.keys = ctx.scratch.keys
+	} else {
+		ctx.keys = make(map[string]struct{})
+	}
+	if opts.Variant == "" {
+		ctx.plan = planTags(structType(elem.Type()), opts)
+	}
+	ctx.lookupCtx = parent
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx.lookupCtx, cancel = context.WithTimeout(parent, opts.Timeout)
+		defer cancel()
 	}
 	switch elem.Kind() {
 	case reflect.Ptr:
 		if elem.IsNil() {
 			elem.Set(reflect.New(elem.Type().Elem()))
 		}
-		_, err := readStruct(elem.Elem(), &ctx)
-		return err
+		elem = elem.Elem()
 	case reflect.Struct:
-		_, err := readStruct(elem, &ctx)
-		return err
 	default:
-		return ErrInvalidValueKind
+		return nil, ErrInvalidValueKind
+	}
+
+	if opts.Parallelism > 1 {
+		ctx.prefetch(conf)
+	}
+
+	if _, err := readStruct(elem, &ctx); err != nil {
+		return nil, err
+	}
+
+	if ctx.abortErr != nil {
+		return ctx.checks, ctx.abortErr
+	}
+
+	if err := fillRestFields(&ctx); err != nil {
+		return ctx.checks, err
+	}
+
+	if len(ctx.probes) > 0 && !opts.SkipProbes {
+		checks := runProbes(parent, ctx.probes, opts)
+		ctx.checks = append(ctx.checks, checks...)
+		ctx.errs = append(ctx.errs, checks.errors()...)
+	}
+
+	if opts.Strict && opts.Prefix != "" {
+		ctx.errs = append(ctx.errs, checkUnknownKeys(&ctx)...)
+	}
+
+	err := joinErrors(ctx.errs)
+	if report != nil && err == nil {
+		report.complete(conf, opts)
+		if opts.OnLoad != nil {
+			opts.OnLoad(report)
+		}
+		if opts.Metrics != nil {
+			opts.Metrics.Loaded(report.Resolved, report.Defaults)
+		}
+	}
+
+	return ctx.checks, err
+}
+
+// checkUnknownKeys returns an error for each environment variable starting with the prefix
+// which wasn't looked up.
+func checkUnknownKeys(ctx *fieldContext) (errs []error) {
+	prefixes := keyPrefixes(ctx.opts.Prefix)
+
+	var known []string
+	for key := range ctx.keys {
+		known = append(known, key)
+	}
+	sort.Strings(known)
+
+	for _, name := range ctx.sourceKeys() {
+		if _, ok := ctx.keys[name]; ok {
+			continue
+		}
+		if _, ok := trimKeyPrefix(name, prefixes); !ok {
+			continue
+		}
+		errs = append(errs, &UnknownKeyError{Key: name, Suggestion: closestKey([]string{name}, known)})
+	}
+
+	return errs
+}
+
+// joinErrors returns nil if errs is empty, the error itself if there is only one,
+// and all of them joined otherwise.
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
 	}
 }
 
 type tag struct {
 	customName string
-	optional   bool
-	skip       bool
-	defaultVal string
+	prefix     string
+	noPrefix   bool
+	// flatten is set for an embedded struct or a squashed one, whose fields are read as fields of its parent.
+	flatten        bool
+	optional       bool
+	secret         bool
+	rest           bool
+	skip           bool
+	fromFile       bool
+	trim           bool
+	base64         bool
+	encrypted      bool
+	csv            bool
+	serial         bool
+	parallel       bool
+	defaultVal     string
+	durationFormat string
+	validators     []string
+	probe          string
+	redact         string
+	description    string
+	group          string
+	deprecated     string
+	example        string
 }
 
-func parseTag(s string) *tag {
-	var t tag
+// structName returns the name of the nested struct field name, which prefixes the keys of its fields:
+// the prefix of the tag if any, nothing with the noprefix option, parent for a flattened struct,
+// the name of the field under parent otherwise.
+func (t *tag) structName(parent, name string) string {
+	switch {
+	case t.noPrefix:
+		return ""
+	case t.prefix != "":
+		return t.prefix
+	case t.flatten:
+		return parent
+	default:
+		return combineName(parent, name)
+	}
+}
 
-	tokens := strings.Split(s, ",")
-	for _, v := range tokens {
-		switch {
-		case v == "-":
-			t.skip = true
-		case v == "optional":
-			t.optional = true
-		case strings.HasPrefix(v, "default="):
-			t.defaultVal = strings.TrimPrefix(v, "default=")
-		default:
-			t.customName = v
+// isSerial returns true if the lookups and the probe of the field must not run concurrently with others.
+func (t *tag) isSerial(opts Options) bool {
+	return t.serial || opts.Serial && !t.parallel
+}
+
+// isEmbeddedStruct returns true if the field is an embedded struct, or a pointer to one, holding fields to read.
+// The exported fields of an embedded struct of an unexported type are read too.
+func isEmbeddedStruct(field reflect.StructField) bool {
+	typ := field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return field.Anonymous && typ.Kind() == reflect.Struct && !isUnmarshaler(typ) && !isSecretType(typ)
+}
+
+// asValidator returns the address of the struct value as a Validator. The struct of an embedded field
+// of an unexported type isn't validated, its methods are promoted to the parent.
+func asValidator(value reflect.Value) (Validator, bool) {
+	if !value.CanInterface() {
+		return nil, false
+	}
+	v, ok := value.Addr().Interface().(Validator)
+	return v, ok
+}
+
+// validator validates a string value and returns its canonical form.
+type validator func(s string) (string, error)
+
+// validators are the validators which can be applied to string fields, by name.
+var validators = map[string]validator{
+	"iso3166":   validateCountryCode,
+	"iso4217":   validateCurrencyCode,
+	"mimetype":  validateMediaType,
+	"extension": validateExtension,
+}
+
+// parseTag parses the envconfig tag of the field, overridden by the tag of the variant of the options if any.
+// It returns an error if an option refers to an unknown probe or redaction policy.
+func parseTag(field reflect.StructField, opts Options) (*tag, error) {
+	t := ParseTag(field.Tag.Get("envconfig"))
+	if opts.Variant != "" {
+		if s, ok := field.Tag.Lookup(opts.Variant); ok {
+			t = t.override(ParseTag(s))
 		}
 	}
+	return newTag(field, t, opts)
+}
+
+// fieldTag returns the tag of the field at path, from the registered plan if it has one for the field.
+func (s *state) fieldTag(field reflect.StructField, path string) (*tag, error) {
+	if t, ok := s.plan[path]; ok {
+		return newTag(field, t, s.opts)
+	}
+	return parseTag(field, s.opts)
+}
 
-	return &t
+// newTag returns the tag t of the field.
+func newTag(field reflect.StructField, t Tag, opts Options) (*tag, error) {
+	if t.Probe != "" && probers[t.Probe] == nil {
+		return nil, fmt.Errorf("envconfig: unknown tag probe %q", t.Probe)
+	}
+	if t.Redact != "" && redactors[t.Redact] == nil {
+		return nil, fmt.Errorf("envconfig: unknown tag redaction policy %q", t.Redact)
+	}
+
+	res := &tag{
+		customName:     t.Name,
+		prefix:         t.Prefix,
+		noPrefix:       t.NoPrefix,
+		flatten:        t.Squash || isEmbeddedStruct(field) && !t.Prefixed && !opts.NoFlatten,
+		optional:       t.Optional,
+		secret:         t.Secret,
+		rest:           t.Rest,
+		skip:           t.Skip,
+		fromFile:       t.FromFile,
+		trim:           t.Trim,
+		base64:         t.Base64,
+		encrypted:      t.Encrypted,
+		csv:            t.CSV,
+		serial:         t.Serial,
+		parallel:       t.Parallel,
+		defaultVal:     t.Default,
+		durationFormat: t.Duration,
+		probe:          t.Probe,
+		redact:         t.Redact,
+		description:    t.Description,
+		group:          t.Group,
+		validators:     t.Validators,
+		deprecated:     t.Deprecated,
+		example:        t.Example,
+	}
+
+	return res, nil
 }
 
-func readStruct(value reflect.Value, ctx *context) (nonNil bool, err error) {
+// readStruct reads all fields of the struct value.
+// Errors concerning a single field are collected in ctx.errs, the returned error is only set
+// when the struct itself is not usable.
+func readStruct(value reflect.Value, ctx *fieldContext) (nonNil bool, err error) {
 	var parents []reflect.Value
+	nbErrs := len(ctx.errs)
+
+	// groups are the groups of fields of the struct, in order of appearance.
+	// groupMissing are the missing keys of each group.
+	var groups []string
+	groupMissing := make(map[string][]string)
 
 	for i := 0; i < value.NumField(); i++ {
 		field := value.Field(i)
 		name := value.Type().Field(i).Name
 
-		tag := parseTag(value.Type().Field(i).Tag.Get("envconfig"))
-		if tag.skip || !field.CanSet() {
-			if !field.CanSet() && !ctx.allowUnexported {
-				return false, ErrUnexportedField
+		tag, err := ctx.fieldTag(value.Type().Field(i), combineName(ctx.path, name))
+		if err != nil {
+			return false, fmt.Errorf("%w (field %s)", err, combineName(ctx.path, name))
+		}
+		// the exported fields of an embedded struct of an unexported type can be set, but not a pointer to it
+		unexported := !field.CanSet() && !(isEmbeddedStruct(value.Type().Field(i)) && field.Kind() == reflect.Struct)
+		if tag.skip || unexported {
+			if unexported && !ctx.opts.AllowUnexported {
+				return false, fmt.Errorf("%w (field %s)", ErrUnexportedField, combineName(ctx.path, name))
 			}
 			continue
 		}
 
+		if tag.rest {
+			if field.Type() != stringMapType {
+				return false, fmt.Errorf("envconfig: rest field must be a map[string]string (field %s)", combineName(ctx.path, name))
+			}
+			ctx.rest = append(ctx.rest, restField{value: field, name: ctx.name})
+			continue
+		}
+
 		parents = ctx.parents
+		inSecret := false
 
 	doRead:
-		switch field.Kind() {
-		case reflect.Ptr:
+		switch {
+		case field.Kind() == reflect.Ptr:
 			// it's a pointer, create a new value and restart the switch
 			if field.IsNil() {
 				field.Set(reflect.New(field.Type().Elem()))
@@ -172,28 +755,84 @@ func readStruct(value reflect.Value, ctx *context) (nonNil bool, err error) {
 			}
 			field = field.Elem()
 			goto doRead
-		case reflect.Struct:
+		case isSecretType(field.Type()):
+			// it's a Secret, read its value instead
+			field = unwrapSecret(field)
+			inSecret = true
+			goto doRead
+		case field.Kind() == reflect.Struct && !isUnmarshaler(field.Type()):
+			missing := ctx.missing
+			if tag.optional && missing == nil {
+				missing = new([]string)
+			}
+
+			structCtx := &fieldContext{
+				name:       tag.structName(ctx.name, name),
+				path:       combineName(ctx.path, name),
+				optional:   ctx.optional || tag.optional,
+				secret:     ctx.secret || tag.secret || inSecret,
+				defaultVal: tag.defaultVal,
+				parents:    parents,
+				missing:    missing,
+				state:      ctx.state,
+			}
 			var nonNilIn bool
-			nonNilIn, err = readStruct(field, &context{
-				name:            combineName(ctx.name, name),
-				optional:        ctx.optional || tag.optional,
-				defaultVal:      tag.defaultVal,
-				parents:         parents,
-				leaveNil:        ctx.leaveNil,
-				allowUnexported: ctx.allowUnexported,
-			})
+			if m := ctx.opts.mapping(field.Type()); m != nil {
+				nonNilIn, err = readMapped(field, m, structCtx)
+			} else {
+				nonNilIn, err = readStruct(field, structCtx)
+			}
 			nonNil = nonNil || nonNilIn
+
+			if ctx.missing == nil && missing != nil && len(*missing) > 0 {
+				d := Disabled{Field: combineName(ctx.path, name), Keys: *missing}
+				if ctx.opts.OnDisabled != nil {
+					ctx.opts.OnDisabled(d)
+				}
+				if ctx.opts.Logger != nil {
+					ctx.opts.Logger.Warn("envconfig: optional struct disabled", "field", d.Field, "missing", strings.Join(d.Keys, ","))
+				}
+			}
 		default:
-			var ok bool
-			ok, err = setField(field, &context{
-				name:            combineName(ctx.name, name),
-				customName:      tag.customName,
-				optional:        ctx.optional || tag.optional,
-				defaultVal:      tag.defaultVal,
-				parents:         parents,
-				leaveNil:        ctx.leaveNil,
-				allowUnexported: ctx.allowUnexported,
-			})
+			fieldCtx := &fieldContext{
+				name:           combineName(ctx.name, name),
+				path:           combineName(ctx.path, name),
+				customName:     tag.customName,
+				optional:       ctx.optional || tag.optional || tag.group != "",
+				secret:         ctx.secret || tag.secret || tag.redact != "" || tag.encrypted || inSecret,
+				defaultVal:     tag.defaultVal,
+				durationFormat: tag.durationFormat,
+				validators:     tag.validators,
+				probe:          tag.probe,
+				redact:         tag.redact,
+				description:    tag.description,
+				deprecated:     tag.deprecated,
+				fromFile:       tag.fromFile,
+				trim:           tag.trim,
+				decodeBase64:   tag.base64,
+				encrypted:      tag.encrypted,
+				csv:            tag.csv,
+				serial:         tag.isSerial(ctx.opts),
+				structTag:      value.Type().Field(i).Tag,
+				parents:        parents,
+				state:          ctx.state,
+			}
+			ok, fieldErr := setField(field, fieldCtx)
+			if fieldErr != nil {
+				ctx.errs = append(ctx.errs, fieldErr)
+				fieldCtx.traceError(fieldErr)
+			}
+			if tag.group != "" {
+				if _, exists := groupMissing[tag.group]; !exists {
+					groups = append(groups, tag.group)
+					groupMissing[tag.group] = nil
+				}
+				if !ok && fieldErr == nil {
+					groupMissing[tag.group] = append(groupMissing[tag.group], canonicalKey(fieldCtx))
+				}
+			} else if !ok && fieldErr == nil && ctx.missing != nil && !tag.optional && tag.defaultVal == "" {
+				*ctx.missing = append(*ctx.missing, canonicalKey(fieldCtx))
+			}
 			nonNil = nonNil || ok
 		}
 
@@ -202,7 +841,21 @@ func readStruct(value reflect.Value, ctx *context) (nonNil bool, err error) {
 		}
 	}
 
-	if !nonNil && ctx.leaveNil { // re-zero
+	if len(groups) > 0 && !ctx.optional && !isGroupComplete(groupMissing) {
+		ctx.errs = append(ctx.errs, &GroupError{Field: ctx.path, Groups: groups, Missing: groupMissing})
+	}
+
+	if v, ok := asValidator(value); ok && len(ctx.errs) == nbErrs {
+		start := time.Now()
+		err := v.Validate()
+		if err != nil {
+			err = &ValidationError{Field: ctx.path, Err: err}
+			ctx.errs = append(ctx.errs, err)
+		}
+		ctx.checks = append(ctx.checks, Check{Name: "validate", Field: ctx.path, Duration: time.Since(start), Err: err})
+	}
+
+	if !nonNil && ctx.opts.LeaveNil { // re-zero
 		for _, p := range parents {
 			p.Set(reflect.Zero(p.Type()))
 		}
@@ -211,40 +864,172 @@ func readStruct(value reflect.Value, ctx *context) (nonNil bool, err error) {
 	return nonNil, err
 }
 
+func isGroupComplete(groupMissing map[string][]string) bool {
+	for _, missing := range groupMissing {
+		if len(missing) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
 var byteSliceType = reflect.TypeOf([]byte(nil))
 
-func setField(value reflect.Value, ctx *context) (ok bool, err error) {
-	str, err := readValue(ctx)
+func setField(value reflect.Value, ctx *fieldContext) (ok bool, err error) {
+	existing := ctx.opts.ExistingAsDefaults && !value.IsZero()
+	if existing {
+		// the existing value is the default: it takes precedence over the default tag
+		// and the field can't be missing.
+		tmp := *ctx
+		tmp.defaultVal = ""
+		tmp.optional = true
+		ctx = &tmp
+	}
+
+	isSliceNotUnmarshaler := value.Kind() == reflect.Slice && !isUnmarshaler(value.Type())
+	if isSliceNotUnmarshaler && value.Type() != byteSliceType && ctx.defaultVal != "" {
+		return false, fmt.Errorf("%w (field %s)", ErrDefaultUnsupportedOnSlice, ctx.path)
+	}
+
+	str, key, err := readValue(ctx)
 	if err != nil {
+		var mkErr *MissingKeyError
+		if errors.As(err, &mkErr) {
+			mkErr.Type = value.Type().String()
+		}
 		return false, err
 	}
 
 	if len(str) == 0 && ctx.optional {
-		return false, nil
+		if existing {
+			ctx.reportField(FromExisting, "", "")
+		} else {
+			ctx.reportField(Unset, "", "")
+		}
+		return existing, nil
 	}
+	read := str
+
+	isBytes := isSliceNotUnmarshaler && value.Type() == byteSliceType
+
+	if ctx.fromFile {
+		data, err := os.ReadFile(str)
+		if err != nil {
+			return true, fmt.Errorf("envconfig: unable to read the file of %s (field %s): %w", keyOrDefault(key), ctx.path, err)
+		}
+		str = string(data)
+	}
+	if ctx.trim {
+		str = strings.TrimSpace(str)
+	}
+	if ctx.encrypted {
+		str, err = decrypt(ctx, key, str)
+		if err != nil {
+			return true, err
+		}
+	}
+	// a byte slice is always decoded from base64, unless it is read from a file or decrypted
+	raw := ctx.fromFile || ctx.encrypted
+	if ctx.decodeBase64 && (!isBytes || raw) {
+		data, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return true, newParseError(ctx, key, str, err)
+		}
+		str = string(data)
+	}
+
+	start := time.Now()
 
-	isSliceNotUnmarshaler := value.Kind() == reflect.Slice && !isUnmarshaler(value.Type())
 	switch {
-	case isSliceNotUnmarshaler && value.Type() == byteSliceType:
-		return true, parseBytesValue(value, str)
+	case isBytes && raw:
+		value.SetBytes([]byte(str))
+
+	case isBytes:
+		err = parseBytesValue(value, str)
+
+	case ctx.csv:
+		err = setCSVField(value, str, ctx)
 
 	case isSliceNotUnmarshaler:
-		return true, setSliceField(value, str, ctx)
+		err = setSliceField(value, str, ctx)
+
+	default:
+		err = parseValue(value, str, ctx)
+	}
+
+	if err != nil {
+		err = newParseError(ctx, key, str, err)
+	}
+	if len(ctx.validators) > 0 {
+		ctx.checks = append(ctx.checks, Check{
+			Name:     strings.Join(ctx.validators, ","),
+			Field:    ctx.path,
+			Key:      key,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+	if err != nil {
+		return true, err
+	}
 
+	if ctx.probe != "" {
+		addProbes(ctx, key, str, isSliceNotUnmarshaler && !isBytes && !ctx.csv)
+	}
+
+	switch {
+	case key == "":
+		ctx.reportField(FromDefault, "", read)
+	case ctx.fileKey:
+		ctx.reportField(FromFile, key, read)
 	default:
-		return true, parseValue(value, str, ctx)
+		ctx.reportField(FromSource, key, read)
+	}
+
+	return true, nil
+}
+
+// addProbes registers the probes of the value, or of each of its elements for a slice.
+func addProbes(ctx *fieldContext, key, str string, slice bool) {
+	values := []string{str}
+	if slice {
+		values = nil
+		tnz := newSliceTokenizer(str)
+		for tnz.scan() {
+			values = append(values, tnz.text())
+		}
+	}
+
+	for _, v := range values {
+		p := probe{name: ctx.probe, field: ctx.path, key: key, value: v, display: v, serial: ctx.serial}
+		if ctx.secret || ctx.fromFile {
+			p.secret = true
+			p.display = redactValue(ctx.redact, v)
+		}
+		ctx.probes = append(ctx.probes, p)
 	}
 }
 
-func setSliceField(value reflect.Value, str string, ctx *context) error {
-	if ctx.defaultVal != "" {
-		return ErrDefaultUnsupportedOnSlice
+func newParseError(ctx *fieldContext, key, str string, err error) *ParseError {
+	if ctx.secret || ctx.fromFile {
+		return &ParseError{Field: ctx.path, Key: key, Value: redactValue(ctx.redact, str), Secret: true, Err: err}
+	}
+	return &ParseError{Field: ctx.path, Key: key, Value: str, Err: err}
+}
+
+// keyOrDefault returns the key, or a description of the default value if it is empty.
+func keyOrDefault(key string) string {
+	if key == "" {
+		return "the default value"
 	}
+	return key
+}
 
+func setSliceField(value reflect.Value, str string, ctx *fieldContext) error {
 	elType := value.Type().Elem()
 	tnz := newSliceTokenizer(str)
 
-	slice := reflect.MakeSlice(value.Type(), value.Len(), value.Cap())
+	slice := reflect.MakeSlice(value.Type(), 0, 0)
 
 	for tnz.scan() {
 		token := tnz.text()
@@ -276,7 +1061,7 @@ func isUnmarshaler(t reflect.Type) bool {
 	return t.Implements(unmarshalerType) || reflect.PtrTo(t).Implements(unmarshalerType)
 }
 
-func parseValue(v reflect.Value, str string, ctx *context) (err error) {
+func parseValue(v reflect.Value, str string, ctx *fieldContext) (err error) {
 	vtype := v.Type()
 
 	// Special case when the type is a map: we need to make the map
@@ -292,7 +1077,12 @@ func parseValue(v reflect.Value, str string, ctx *context) (err error) {
 
 	// Special case for time.Duration
 	if isDurationField(vtype) {
-		return parseDuration(v, str)
+		return parseDuration(v, str, ctx)
+	}
+
+	// Special case for http.Header
+	if vtype == httpHeaderType {
+		return parseHeader(v, str)
 	}
 
 	kind := vtype.Kind()
@@ -309,11 +1099,16 @@ func parseValue(v reflect.Value, str string, ctx *context) (err error) {
 		v.Set(reflect.New(vtype.Elem()))
 		return parseValue(v.Elem(), str, ctx)
 	case reflect.String:
+		for _, name := range ctx.validators {
+			if str, err = validators[name](str); err != nil {
+				return err
+			}
+		}
 		v.SetString(str)
 	case reflect.Struct:
 		err = parseStruct(v, str, ctx)
 	default:
-		return fmt.Errorf("envconfig: kind %v not supported", kind)
+		return fmt.Errorf("kind %v not supported", kind)
 	}
 
 	return
@@ -324,8 +1119,19 @@ func parseWithUnmarshaler(v reflect.Value, str string) error {
 	return u.Unmarshal(str)
 }
 
-func parseDuration(v reflect.Value, str string) error {
-	d, err := time.ParseDuration(str)
+func parseDuration(v reflect.Value, str string, ctx *fieldContext) error {
+	var (
+		d   time.Duration
+		err error
+	)
+	switch ctx.durationFormat {
+	case durationFormatGo:
+		d, err = time.ParseDuration(str)
+	case durationFormatISO8601:
+		d, err = parseISO8601Duration(str)
+	default:
+		return fmt.Errorf("unknown duration format %q", ctx.durationFormat)
+	}
 	if err != nil {
 		return err
 	}
@@ -336,10 +1142,10 @@ func parseDuration(v reflect.Value, str string) error {
 }
 
 // NOTE(vincent): this is only called when parsing structs inside a slice.
-func parseStruct(value reflect.Value, token string, ctx *context) error {
+func parseStruct(value reflect.Value, token string, ctx *fieldContext) error {
 	tokens := strings.Split(token[1:len(token)-1], ",")
 	if len(tokens) != value.NumField() {
-		return fmt.Errorf("envconfig: struct token has %d fields but struct has %d", len(tokens), value.NumField())
+		return fmt.Errorf("struct token has %d fields but struct has %d", len(tokens), value.NumField())
 	}
 
 	for i := 0; i < value.NumField(); i++ {
@@ -412,44 +1218,221 @@ func combineName(parentName, name string) string {
 	return parentName + "." + name
 }
 
-func readValue(ctx *context) (string, error) {
-	keys := makeAllPossibleKeys(ctx)
-
-	var str string
+// readValue returns the value of the first key found, along with the key itself.
+// The key is empty if the value is the default one.
+func readValue(ctx *fieldContext) (str string, key string, err error) {
+	n := len(ctx.failures)
+	defer func() {
+		if len(ctx.failures) > n {
+			err = tolerateFailures(ctx, ctx.failures[n:], err)
+			ctx.failures = ctx.failures[:n]
+		}
+	}()
 
+	keys := makeAllPossibleKeys(ctx)
 	for _, key := range keys {
-		str = os.Getenv(key)
+		ctx.keys[key] = struct{}{}
+	}
+	if ctx.deprecated != "" {
+		// the deprecated key is known even if the field is set with another key
+		ctx.keys[ctx.deprecated] = struct{}{}
+	}
+
+	for _, key = range keys {
+		str, ctx.origin, err = ctx.lookup(key, ctx.structTag)
+		ctx.traceLookup(key, str, err)
+		if err != nil {
+			return "", "", fmt.Errorf("envconfig: unable to look up %s (field %s): %w", key, ctx.path, err)
+		}
 		if str != "" {
-			break
+			ctx.resolved = append(ctx.resolved, key)
+			return str, key, nil
 		}
 	}
 
-	if str != "" {
-		return str, nil
+	if ctx.opts.FileKeys {
+		str, key, err = readFileValue(ctx, keys)
+		if err != nil || str != "" {
+			ctx.fileKey = str != ""
+			return str, key, err
+		}
+	}
+
+	if ctx.deprecated != "" {
+		str, key, err = readDeprecatedValue(ctx)
+		if err != nil || str != "" {
+			return str, key, err
+		}
 	}
 
 	if ctx.defaultVal != "" {
-		return ctx.defaultVal, nil
+		return ctx.defaultVal, "", nil
 	}
 
 	if ctx.optional {
-		return "", nil
+		return "", "", nil
+	}
+
+	return "", "", &MissingKeyError{
+		Field:       ctx.path,
+		Description: ctx.description,
+		Keys:        keys,
+		Key:         canonicalKey(ctx),
+		Suggestion:  closestKey(keys, ctx.sourceKeys()),
+	}
+}
+
+// tolerateFailures reports the failures of the sources skipped while reading the field, once per source, and
+// returns err. If the field is required and missing, it returns the error of the first failure instead,
+// which also wraps err.
+func tolerateFailures(ctx *fieldContext, failures []*SourceError, err error) error {
+	if missingErr, ok := err.(*MissingKeyError); ok {
+		return &failedLookupError{
+			err:     fmt.Errorf("envconfig: unable to look up %s (field %s): %w", missingErr.Key, ctx.path, failures[0]),
+			missing: missingErr,
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, srcErr := range failures {
+		if seen[srcErr.Source] {
+			continue
+		}
+		seen[srcErr.Source] = true
+
+		f := SourceFailure{Field: ctx.path, Source: srcErr.Source, Err: srcErr.Err}
+		if ctx.opts.OnSourceFailure != nil {
+			ctx.opts.OnSourceFailure(f)
+		}
+		if ctx.opts.Logger != nil {
+			ctx.opts.Logger.Warn("envconfig: source failure tolerated", "field", f.Field, "source", f.Source, "error", f.Err.Error())
+		}
+	}
+	return err
+}
+
+// readDeprecatedValue returns the value of the deprecated key of the field, along with the key,
+// and warns that it is used.
+func readDeprecatedValue(ctx *fieldContext) (str string, key string, err error) {
+	key = ctx.deprecated
+
+	str, ctx.origin, err = ctx.lookup(key, ctx.structTag)
+	ctx.traceLookup(key, str, err)
+	if err != nil {
+		return "", "", fmt.Errorf("envconfig: unable to look up %s (field %s): %w", key, ctx.path, err)
+	}
+	if str == "" {
+		return "", "", nil
 	}
+	ctx.resolved = append(ctx.resolved, key)
 
-	return "", fmt.Errorf("envconfig: keys %s not found", strings.Join(keys, ", "))
+	d := DeprecatedKey{Field: ctx.path, Key: key, Replacement: canonicalKey(ctx)}
+	if ctx.opts.OnDeprecated != nil {
+		ctx.opts.OnDeprecated(d)
+	}
+	if ctx.opts.Logger != nil {
+		ctx.opts.Logger.Warn("envconfig: deprecated key used", "field", d.Field, "key", d.Key, "replacement", d.Replacement)
+	}
+
+	return str, key, nil
 }
 
-func makeAllPossibleKeys(ctx *context) (res []string) {
+// readFileValue returns the content of the file named by the first key with the _FILE suffix found,
+// along with that key.
+func readFileValue(ctx *fieldContext, keys []string) (str string, key string, err error) {
+	for _, k := range keys {
+		key = k + "_FILE"
+		if k == strings.ToLower(k) {
+			key = k + "_file"
+		}
+		ctx.keys[key] = struct{}{}
+
+		var path string
+		path, ctx.origin, err = ctx.lookup(key, "")
+		ctx.traceLookup(key, path, err)
+		if err != nil {
+			return "", "", fmt.Errorf("envconfig: unable to look up %s (field %s): %w", key, ctx.path, err)
+		}
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("envconfig: unable to read the file of %s (field %s): %w", key, ctx.path, err)
+		}
+
+		return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), key, nil
+	}
+
+	return "", "", nil
+}
+
+// canonicalKey returns the key used to refer to a field, for example in the documentation.
+// It is the (first) custom name if there is one, the upper case name otherwise.
+func canonicalKey(ctx *fieldContext) string {
 	if ctx.customName != "" {
-		return []string{ctx.customName}
+		return customKeys(ctx.customName)[0]
+	}
+	if keys := namedKeys(ctx); len(keys) > 0 {
+		return keys[0]
 	}
+	if snakeCase(ctx) {
+		return snakeKey(ctx.name, ctx.opts.Acronyms)
+	}
+
+	return strings.ToUpper(strings.Replace(ctx.name, ".", "_", -1))
+}
 
-	tmp := make(map[string]struct{})
+// snakeCase returns true if the canonical key of the field splits the words of the names, see Options.SnakeCase.
+func snakeCase(ctx *fieldContext) bool {
+	return ctx.state != nil && ctx.opts.SnakeCase
+}
+
+// namedKeys returns the keys of the field returned by Options.KeyNames, if it is set.
+func namedKeys(ctx *fieldContext) []string {
+	if ctx.state == nil || ctx.opts.KeyNames == nil {
+		return nil
+	}
+	return ctx.opts.KeyNames(strings.Split(ctx.name, "."))
+}
+
+// customKeys returns the alternative keys of a custom name like ADDR|ADDRESS|HOST, in order.
+func customKeys(name string) []string {
+	var keys []string
+	for _, key := range strings.Split(name, "|") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return []string{name}
+	}
+	return keys
+}
+
+func makeAllPossibleKeys(ctx *fieldContext) (res []string) {
+	if ctx.customName != "" {
+		return customKeys(ctx.customName)
+	}
+	if keys := namedKeys(ctx); len(keys) > 0 {
+		return keys
+	}
+	if ctx.state != nil && ctx.opts.StrictCase {
+		return []string{canonicalKey(ctx)}
+	}
+
+	sc := ctx.keyBuffers()
+	tmp := sc.names
 	{
-		n := []rune(ctx.name)
+		n := sc.runes
+		for _, r := range ctx.name {
+			n = append(n, r)
+		}
+		sc.runes = n
 
-		var buf bytes.Buffer  // this is the buffer where we put extra underscores on "word" boundaries
-		var buf2 bytes.Buffer // this is the buffer with the standard naming scheme
+		buf := &sc.buf   // this is the buffer where we put extra underscores on "word" boundaries
+		buf2 := &sc.buf2 // this is the buffer with the standard naming scheme
 
 		wroteUnderscore := false
 		for i, r := range ctx.name {
@@ -477,11 +1460,21 @@ func makeAllPossibleKeys(ctx *context) (res []string) {
 		tmp[strings.ToUpper(buf2.String())] = struct{}{}
 	}
 
+	res = make([]string, 0, len(tmp))
+	sorted := 0
+	if snakeCase(ctx) {
+		// the key with the words split is the canonical one, looked up first
+		snake := snakeKey(ctx.name, ctx.opts.Acronyms)
+		delete(tmp, snake)
+		res = append(res, snake)
+		sorted = 1
+	}
+
 	for k := range tmp {
 		res = append(res, k)
 	}
 
-	sort.Strings(res)
+	sort.Strings(res[sorted:])
 
 	return
 }
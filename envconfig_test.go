@@ -1,8 +1,10 @@
 package envconfig_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -21,11 +23,11 @@ func TestParseSimpleConfig(t *testing.T) {
 	}
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, "envconfig: keys NAME, name not found", err.Error())
+	require.Equal(t, "envconfig: keys NAME, name not found (field Name)\nenvconfig: keys LOG_PATH, log_path not found (field Log.Path)", err.Error())
 
 	os.Setenv("NAME", "foobar")
 	err = envconfig.Init(&conf)
-	require.Equal(t, "envconfig: keys LOG_PATH, log_path not found", err.Error())
+	require.Equal(t, "envconfig: keys LOG_PATH, log_path not found (field Log.Path)", err.Error())
 
 	os.Setenv("LOG_PATH", "/var/log/foobar")
 	err = envconfig.Init(&conf)
@@ -140,7 +142,7 @@ func TestParseStructSliceWrongData(t *testing.T) {
 	os.Setenv("SHARDS", "foobar")
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, "envconfig: struct token has 1 fields but struct has 2", err.Error())
+	require.Equal(t, "envconfig: unable to parse SHARDS (field Shards): struct token has 1 fields but struct has 2", err.Error())
 }
 
 func TestParseStructSliceWrongValue(t *testing.T) {
@@ -154,34 +156,155 @@ func TestParseStructSliceWrongValue(t *testing.T) {
 	os.Setenv("SHARDS", "{foobar,barbaz}")
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, `strconv.ParseInt: parsing "barbaz": invalid syntax`, err.Error())
+	require.Equal(t, `envconfig: unable to parse SHARDS (field Shards): strconv.ParseInt: parsing "barbaz": invalid syntax`, err.Error())
 }
 
 func TestParseWrongValues(t *testing.T) {
 	var conf struct{ OK bool }
 	os.Setenv("OK", "foobar")
 	err := envconfig.Init(&conf)
-	require.Equal(t, `strconv.ParseBool: parsing "foobar": invalid syntax`, err.Error())
+	require.Equal(t, `envconfig: unable to parse OK (field OK): strconv.ParseBool: parsing "foobar": invalid syntax`, err.Error())
 
 	var conf2 struct{ Port int }
 	os.Setenv("PORT", "foobar")
 	err = envconfig.Init(&conf2)
-	require.Equal(t, `strconv.ParseInt: parsing "foobar": invalid syntax`, err.Error())
+	require.Equal(t, `envconfig: unable to parse PORT (field Port): strconv.ParseInt: parsing "foobar": invalid syntax`, err.Error())
 
 	var conf3 struct{ Port uint }
 	os.Setenv("PORT", "foobar")
 	err = envconfig.Init(&conf3)
-	require.Equal(t, `strconv.ParseUint: parsing "foobar": invalid syntax`, err.Error())
+	require.Equal(t, `envconfig: unable to parse PORT (field Port): strconv.ParseUint: parsing "foobar": invalid syntax`, err.Error())
 
 	var conf4 struct{ Port float32 }
 	os.Setenv("PORT", "foobar")
 	err = envconfig.Init(&conf4)
-	require.Equal(t, `strconv.ParseFloat: parsing "foobar": invalid syntax`, err.Error())
+	require.Equal(t, `envconfig: unable to parse PORT (field Port): strconv.ParseFloat: parsing "foobar": invalid syntax`, err.Error())
 
 	var conf5 struct{ Data []byte }
 	os.Setenv("DATA", "foobar")
 	err = envconfig.Init(&conf5)
-	require.Equal(t, "illegal base64 data at input byte 4", err.Error())
+	require.Equal(t, "envconfig: unable to parse DATA (field Data): illegal base64 data at input byte 4", err.Error())
+}
+
+func TestAllErrorsReported(t *testing.T) {
+	var conf struct {
+		Name string
+		Port int
+		Log  struct {
+			Path string
+		}
+		Timeout time.Duration
+	}
+
+	os.Setenv("NAME", "")
+	os.Setenv("PORT", "foobar")
+	os.Setenv("LOG_PATH", "")
+	os.Setenv("TIMEOUT", "1m")
+
+	err := envconfig.Init(&conf)
+	require.Equal(t, `envconfig: keys NAME, name not found (field Name)
+envconfig: unable to parse PORT (field Port): strconv.ParseInt: parsing "foobar": invalid syntax
+envconfig: keys LOG_PATH, log_path not found (field Log.Path)`, err.Error())
+	require.Equal(t, time.Minute, conf.Timeout)
+
+	os.Setenv("PORT", "")
+	os.Setenv("TIMEOUT", "")
+}
+
+func TestErrorTypes(t *testing.T) {
+	var conf struct {
+		MySQL struct {
+			Master struct {
+				Address string
+				Port    int
+			}
+		}
+	}
+
+	os.Setenv("MYSQL_MASTER_ADDRESS", "")
+	os.Setenv("MYSQL_MASTER_PORT", "foobar")
+
+	err := envconfig.Init(&conf)
+	require.NotNil(t, err)
+
+	var missingErr *envconfig.MissingKeyError
+	require.True(t, errors.As(err, &missingErr))
+	require.Equal(t, "MySQL.Master.Address", missingErr.Field)
+	require.Equal(t, []string{"MYSQL_MASTER_ADDRESS", "MY_SQL_MASTER_ADDRESS", "my_sql_master_address", "mysql_master_address"}, missingErr.Keys)
+
+	var parseErr *envconfig.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, "MySQL.Master.Port", parseErr.Field)
+	require.Equal(t, "MYSQL_MASTER_PORT", parseErr.Key)
+	require.Equal(t, "foobar", parseErr.Value)
+	require.True(t, errors.Is(err, strconv.ErrSyntax))
+
+	os.Setenv("MYSQL_MASTER_PORT", "")
+}
+
+func TestMissingKeySuggestion(t *testing.T) {
+	var conf struct {
+		Log struct {
+			Path string
+		}
+		Database string `envconfig:"DATABASE_URL"`
+	}
+
+	os.Setenv("LOG_PATH", "")
+	os.Setenv("LOGPATH", "/var/log/foobar")
+	os.Setenv("DATABSE_URL", "postgres://localhost")
+
+	err := envconfig.Init(&conf)
+	require.Equal(t, `envconfig: keys LOG_PATH, log_path not found (field Log.Path), did you mean LOGPATH?
+envconfig: keys DATABASE_URL not found (field Database), did you mean DATABSE_URL?`, err.Error())
+
+	var missingErr *envconfig.MissingKeyError
+	require.True(t, errors.As(err, &missingErr))
+	require.Equal(t, "LOGPATH", missingErr.Suggestion)
+
+	os.Setenv("LOGPATH", "")
+	os.Setenv("DATABSE_URL", "")
+}
+
+func TestSecretValuesRedacted(t *testing.T) {
+	var conf struct {
+		Password int `envconfig:",secret"`
+		Port     int
+	}
+
+	os.Setenv("PASSWORD", "hunter2")
+	os.Setenv("PORT", "foobar")
+
+	err := envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse PASSWORD (field Password): invalid value
+envconfig: unable to parse PORT (field Port): strconv.ParseInt: parsing "foobar": invalid syntax`, err.Error())
+
+	var parseErr *envconfig.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, "", parseErr.Value)
+	require.True(t, parseErr.Secret)
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{RedactValues: true})
+	require.Equal(t, `envconfig: unable to parse PASSWORD (field Password): invalid value
+envconfig: unable to parse PORT (field Port): invalid value`, err.Error())
+
+	os.Setenv("PASSWORD", "")
+	os.Setenv("PORT", "")
+}
+
+func TestDescriptionInErrors(t *testing.T) {
+	var conf struct {
+		Addr string `envconfig:"desc=Listen address, like :8080"`
+	}
+
+	os.Setenv("ADDR", "")
+
+	err := envconfig.Init(&conf)
+	require.Equal(t, "envconfig: keys ADDR, addr not found (field Addr, Listen address, like :8080)", err.Error())
+
+	var missingErr *envconfig.MissingKeyError
+	require.True(t, errors.As(err, &missingErr))
+	require.Equal(t, "Listen address, like :8080", missingErr.Description)
 }
 
 func TestDurationConfig(t *testing.T) {
@@ -208,6 +331,29 @@ func TestInvalidDurationConfig(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestISO8601DurationConfig(t *testing.T) {
+	var conf struct {
+		Timeout   time.Duration   `envconfig:"duration=iso8601"`
+		Intervals []time.Duration `envconfig:"duration=iso8601"`
+	}
+
+	os.Setenv("TIMEOUT", "PT1H30M")
+	os.Setenv("INTERVALS", "PT1S,P1D")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, time.Hour+30*time.Minute, conf.Timeout)
+	require.Equal(t, []time.Duration{time.Second, 24 * time.Hour}, conf.Intervals)
+
+	os.Setenv("TIMEOUT", "1h")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse TIMEOUT (field Timeout): invalid ISO 8601 duration "1h"`, err.Error())
+
+	os.Setenv("TIMEOUT", "")
+	os.Setenv("INTERVALS", "")
+}
+
 func TestAllPointerConfig(t *testing.T) {
 	var conf struct {
 		Name   *string
@@ -377,6 +523,395 @@ func TestParseOptionalStruct(t *testing.T) {
 	require.Equal(t, "", conf.Master.Name)
 }
 
+func TestOnDisabled(t *testing.T) {
+	var conf struct {
+		Tracing struct {
+			Endpoint    string
+			Token       string  `envconfig:"optional"`
+			SampleRatio float64 `envconfig:"default=0.1"`
+		} `envconfig:"optional"`
+		Metrics *struct {
+			Addr string
+		} `envconfig:"optional"`
+		Cache struct {
+			Size int
+		} `envconfig:"optional"`
+	}
+
+	os.Setenv("TRACING_ENDPOINT", "")
+	os.Setenv("METRICS_ADDR", "")
+	os.Setenv("CACHE_SIZE", "100")
+
+	var disabled []string
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{
+		OnDisabled: func(d envconfig.Disabled) {
+			disabled = append(disabled, d.String())
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"Tracing disabled: TRACING_ENDPOINT not set",
+		"Metrics disabled: METRICS_ADDR not set",
+	}, disabled)
+
+	os.Setenv("CACHE_SIZE", "")
+}
+
+type EmbeddedHTTP struct {
+	Addr    string
+	Timeout int `envconfig:"default=30"`
+}
+
+type embeddedLog struct {
+	Level string `envconfig:"default=info"`
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	var conf struct {
+		EmbeddedHTTP
+		embeddedLog
+		Worker struct {
+			*EmbeddedHTTP
+		}
+		Admin struct {
+			EmbeddedHTTP `envconfig:",prefixed"`
+		}
+	}
+
+	source := envconfig.MapSource{
+		"APP_ADDR":                     ":8080",
+		"APP_LEVEL":                    "debug",
+		"APP_WORKER_ADDR":              ":8081",
+		"APP_ADMIN_EMBEDDED_HTTP_ADDR": ":9090",
+	}
+	opts := envconfig.Options{Prefix: "APP", Source: source, Strict: true}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, EmbeddedHTTP{Addr: ":8080", Timeout: 30}, conf.EmbeddedHTTP)
+	require.Equal(t, "debug", conf.Level)
+	require.Equal(t, ":8081", conf.Worker.Addr)
+	require.Equal(t, ":9090", conf.Admin.Addr)
+
+	values, err := envconfig.MarshalWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "debug", values["APP_LEVEL"])
+	require.Equal(t, ":8081", values["APP_WORKER_ADDR"])
+	require.Equal(t, ":9090", values["APP_ADMIN_EMBEDDEDHTTP_ADDR"])
+
+	var usage strings.Builder
+	require.Nil(t, envconfig.WriteUsage(&usage, &conf, opts))
+	require.Contains(t, usage.String(), "APP_LEVEL ")
+}
+
+func TestNoFlatten(t *testing.T) {
+	var conf struct {
+		EmbeddedHTTP
+		embeddedLog `envconfig:",squash"`
+		Admin       struct {
+			Addr string
+		} `envconfig:",squash"`
+	}
+
+	source := envconfig.MapSource{
+		"APP_EMBEDDEDHTTP_ADDR": ":8080",
+		"APP_LEVEL":             "debug",
+		"APP_ADDR":              ":9090",
+	}
+	opts := envconfig.Options{Prefix: "APP", Source: source, Strict: true, NoFlatten: true}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, ":8080", conf.EmbeddedHTTP.Addr)
+	require.Equal(t, "debug", conf.Level)
+	require.Equal(t, ":9090", conf.Admin.Addr)
+
+	values, err := envconfig.MarshalWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, ":8080", values["APP_EMBEDDEDHTTP_ADDR"])
+	require.Equal(t, ":9090", values["APP_ADDR"])
+}
+
+func TestStrictCase(t *testing.T) {
+	var conf struct {
+		Name       string
+		MaxRetries int    `envconfig:"optional"`
+		Addr       string `envconfig:"ADDR|address"`
+	}
+
+	source := newCountingSource(envconfig.MapSource{"APP_NAME": "foo", "app_maxretries": "3", "address": ":8080"})
+	opts := envconfig.Options{Prefix: "APP", Source: source, StrictCase: true}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, 0, conf.MaxRetries)
+	require.Equal(t, ":8080", conf.Addr)
+	require.Equal(t, 1, source.count("APP_MAXRETRIES"))
+	require.Equal(t, 0, source.count("app_maxretries"))
+	require.Equal(t, 0, source.count("APP_MAX_RETRIES"))
+
+	opts.Source = envconfig.MapSource{"app_name": "foo", "ADDR": ":8080"}
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Equal(t, "envconfig: keys APP_NAME not found (field Name), did you mean app_name?", err.Error())
+
+	opts.Source = envconfig.MapSource{"APP_NAME": "foo", "APP_MAX_RETRIES": "3", "ADDR": ":8080"}
+	opts.SnakeCase = true
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, 3, conf.MaxRetries)
+}
+
+func TestValidateKeys(t *testing.T) {
+	var conf struct {
+		Größe    int
+		Timeout  int    `envconfig:"3TIMEOUT"`
+		Database string `envconfig:"DATABASE-URL"`
+		Name     string
+	}
+
+	opts := envconfig.Options{Source: envconfig.MapSource{"NAME": "foo"}, ValidateKeys: true}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Equal(t, `envconfig: invalid key "GRÖßE" (field Größe): it contains the character 'Ö'
+envconfig: invalid key "3TIMEOUT" (field Timeout): it starts with a digit
+envconfig: invalid key "DATABASE-URL" (field Database): it contains the character '-'`, err.Error())
+
+	var keyErr *envconfig.KeyNameError
+	require.True(t, errors.As(err, &keyErr))
+	require.Equal(t, "Größe", keyErr.Field)
+
+	var short struct {
+		Name              string
+		VeryLongFieldName string `envconfig:"optional"`
+	}
+	opts.Prefix = "APP"
+	opts.Source = envconfig.MapSource{"APP_NAME": "foo"}
+	opts.MaxKeyLength = 12
+	err = envconfig.InitWithOptions(&short, opts)
+	require.Equal(t, `envconfig: invalid key "APP_VERYLONGFIELDNAME" (field VeryLongFieldName): it is longer than 12 characters`, err.Error())
+
+	opts.MaxKeyLength = 0
+	require.Nil(t, envconfig.InitWithOptions(&short, opts))
+	require.Equal(t, "foo", short.Name)
+
+	// MaxKeyLength is checked without ValidateKeys, but not the characters
+	opts.ValidateKeys = false
+	opts.MaxKeyLength = 12
+	err = envconfig.InitWithOptions(&short, opts)
+	require.Equal(t, `envconfig: invalid key "APP_VERYLONGFIELDNAME" (field VeryLongFieldName): it is longer than 12 characters`, err.Error())
+
+	var portable struct {
+		Database string `envconfig:"DATABASE-URL"`
+	}
+	opts.Prefix = ""
+	opts.Source = envconfig.MapSource{"DATABASE-URL": "foo"}
+	require.Nil(t, envconfig.InitWithOptions(&portable, opts))
+	require.Equal(t, "foo", portable.Database)
+}
+
+func TestSnakeCase(t *testing.T) {
+	var conf struct {
+		MaxRetries int
+		HTTPServer struct {
+			ReadTimeout time.Duration `envconfig:"default=5s"`
+		}
+		Name string
+	}
+
+	source := envconfig.MapSource{"APP_MAX_RETRIES": "3", "APP_MAXRETRIES": "5", "APP_NAME": "myapp"}
+	opts := envconfig.Options{Prefix: "APP", Source: source, SnakeCase: true}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, 3, conf.MaxRetries)
+	require.Equal(t, 5*time.Second, conf.HTTPServer.ReadTimeout)
+
+	values, err := envconfig.MarshalWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"APP_MAX_RETRIES": "3", "APP_HTTP_SERVER_READ_TIMEOUT": "5s", "APP_NAME": "myapp"}, values)
+
+	// the concatenated key is still looked up
+	opts.Source = envconfig.MapSource{"APP_MAXRETRIES": "5", "APP_NAME": "myapp"}
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, 5, conf.MaxRetries)
+
+	opts.Source = envconfig.MapSource{"APP_NAME": "myapp"}
+	err = envconfig.InitWithOptions(&conf, opts)
+	var missingErr *envconfig.MissingKeyError
+	require.True(t, errors.As(err, &missingErr))
+	require.Equal(t, "APP_MAX_RETRIES", missingErr.Key)
+}
+
+func TestKeyNames(t *testing.T) {
+	var conf struct {
+		Database struct {
+			Host string
+			Port int `envconfig:"default=5432"`
+		}
+		Name string `envconfig:"APP_NAME"`
+		Log  struct {
+			Level string
+		}
+	}
+
+	keyNames := func(path []string) []string {
+		if path[1] == "Log" {
+			return nil
+		}
+		return []string{strings.ToUpper(strings.Join(path, "__")), strings.Join(path, ".")}
+	}
+	source := envconfig.MapSource{
+		"APP__DATABASE__HOST": "localhost",
+		"APP.Database.Port":   "5433",
+		"APP_NAME":            "myapp",
+		"APP_LOG_LEVEL":       "debug",
+	}
+	opts := envconfig.Options{Prefix: "APP", Source: source, KeyNames: keyNames, Strict: true}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "localhost", conf.Database.Host)
+	require.Equal(t, 5433, conf.Database.Port)
+	require.Equal(t, "myapp", conf.Name)
+	require.Equal(t, "debug", conf.Log.Level)
+
+	values, err := envconfig.MarshalWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "5433", values["APP__DATABASE__PORT"])
+
+	opts.Source = envconfig.MapSource{}
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Contains(t, err.Error(), "envconfig: keys APP__DATABASE__HOST, APP.Database.Host not found (field Database.Host)")
+}
+
+func TestStructPrefix(t *testing.T) {
+	type database struct {
+		Host string
+		Port int `envconfig:"default=5432"`
+	}
+	var conf struct {
+		Name    string
+		Primary database `envconfig:"prefix=DB"`
+		Replica database `envconfig:"prefix=DB_REPLICA,optional"`
+		Shared  struct {
+			DatabaseURL string
+		} `envconfig:",noprefix"`
+	}
+
+	source := envconfig.MapSource{
+		"APP_NAME":        "foo",
+		"DB_HOST":         "primary",
+		"DB_REPLICA_HOST": "replica",
+		"DATABASE_URL":    "postgres://primary",
+	}
+	opts := envconfig.Options{Prefix: "APP", Source: source}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "primary", conf.Primary.Host)
+	require.Equal(t, 5432, conf.Primary.Port)
+	require.Equal(t, "replica", conf.Replica.Host)
+	require.Equal(t, "postgres://primary", conf.Shared.DatabaseURL)
+
+	values, err := envconfig.MarshalWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{
+		"APP_NAME":        "foo",
+		"DB_HOST":         "primary",
+		"DB_PORT":         "5432",
+		"DB_REPLICA_HOST": "replica",
+		"DB_REPLICA_PORT": "5432",
+		"DATABASEURL":     "postgres://primary",
+	}, values)
+
+	var usage strings.Builder
+	require.Nil(t, envconfig.WriteUsage(&usage, &conf, opts))
+	require.Contains(t, usage.String(), "DB_REPLICA_PORT")
+}
+
+func TestAlternativeKeys(t *testing.T) {
+	var conf struct {
+		Addr string `envconfig:"ADDR|ADDRESS|HOST"`
+		Port int    `envconfig:"PORT|HTTP_PORT,default=80"`
+	}
+
+	source := envconfig.MapSource{"ADDRESS": "example.com", "HOST": "localhost"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "example.com", conf.Addr)
+	require.Equal(t, 80, conf.Port)
+
+	source["HTTP_PORT"] = "8080"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, 8080, conf.Port)
+
+	values, err := envconfig.Marshal(&conf)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"ADDR": "example.com", "PORT": "8080"}, values)
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{}})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "keys ADDR, ADDRESS, HOST not found")
+}
+
+func TestDeprecatedKey(t *testing.T) {
+	var conf struct {
+		LogLevel string `envconfig:"APP_LOG_LEVEL,deprecated=APP_LOGLEVEL"`
+		Port     int    `envconfig:"default=80,deprecated=APP_LISTEN_PORT"`
+	}
+
+	var deprecated []envconfig.DeprecatedKey
+	opts := envconfig.Options{
+		Prefix: "APP",
+		Strict: true,
+		Source: envconfig.MapSource{"APP_LOGLEVEL": "debug"},
+		OnDeprecated: func(d envconfig.DeprecatedKey) {
+			deprecated = append(deprecated, d)
+		},
+	}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "debug", conf.LogLevel)
+	require.Equal(t, 80, conf.Port)
+	require.Equal(t, []envconfig.DeprecatedKey{{Field: "LogLevel", Key: "APP_LOGLEVEL", Replacement: "APP_LOG_LEVEL"}}, deprecated)
+	require.Equal(t, "APP_LOGLEVEL is deprecated, use APP_LOG_LEVEL instead", deprecated[0].String())
+
+	// the new key takes precedence, and the deprecated one is still known to the strict mode
+	deprecated = nil
+	opts.Source = envconfig.MapSource{"APP_LOG_LEVEL": "info", "APP_LOGLEVEL": "debug", "APP_LISTEN_PORT": "8080"}
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "info", conf.LogLevel)
+	require.Equal(t, 8080, conf.Port)
+	require.Equal(t, []envconfig.DeprecatedKey{{Field: "Port", Key: "APP_LISTEN_PORT", Replacement: "APP_PORT"}}, deprecated)
+}
+
+func TestGroups(t *testing.T) {
+	var conf struct {
+		Auth struct {
+			User     string `envconfig:"group=basic"`
+			Password string `envconfig:"group=basic"`
+			Token    string `envconfig:"group=token"`
+		}
+	}
+
+	os.Setenv("AUTH_USER", "foobar")
+	os.Setenv("AUTH_PASSWORD", "")
+	os.Setenv("AUTH_TOKEN", "")
+
+	err := envconfig.Init(&conf)
+	require.Equal(t, "envconfig: one of these groups must be complete (field Auth): basic (missing AUTH_PASSWORD) or token (missing AUTH_TOKEN)", err.Error())
+
+	var groupErr *envconfig.GroupError
+	require.True(t, errors.As(err, &groupErr))
+	require.Equal(t, []string{"basic", "token"}, groupErr.Groups)
+
+	os.Setenv("AUTH_TOKEN", "secret")
+
+	err = envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, "secret", conf.Auth.Token)
+
+	os.Setenv("AUTH_USER", "")
+	os.Setenv("AUTH_TOKEN", "")
+}
+
 func TestParsePrefixedStruct(t *testing.T) {
 	var conf struct {
 		Name string
@@ -395,6 +930,75 @@ func TestParsePrefixedStruct(t *testing.T) {
 	require.Equal(t, "good", conf.Name)
 }
 
+func TestStrictMode(t *testing.T) {
+	var conf struct {
+		Name  string
+		Port  int
+		Debug bool `envconfig:"-"`
+	}
+
+	os.Setenv("APP_NAME", "foobar")
+	os.Setenv("APP_PORT", "80")
+	os.Setenv("APP_TIMEOUT", "1m")
+	os.Setenv("app_nmae", "barbaz")
+	os.Setenv("APPLICATION", "foobar")
+
+	err := envconfig.InitWithPrefix(&conf, "APP")
+	require.Nil(t, err)
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Strict: true})
+	require.Equal(t, `envconfig: unknown key APP_TIMEOUT
+envconfig: unknown key app_nmae, did you mean APP_NAME?`, err.Error())
+
+	var unknownErr *envconfig.UnknownKeyError
+	require.True(t, errors.As(err, &unknownErr))
+	require.Equal(t, "APP_TIMEOUT", unknownErr.Key)
+
+	os.Setenv("APP_NAME", "")
+	os.Setenv("APP_PORT", "")
+	os.Setenv("APP_TIMEOUT", "")
+	os.Setenv("app_nmae", "")
+	os.Setenv("APPLICATION", "")
+}
+
+func TestRestField(t *testing.T) {
+	var conf struct {
+		Name    string
+		Plugins struct {
+			Enabled bool
+			Rest    map[string]string `envconfig:",rest"`
+		}
+		Rest map[string]string `envconfig:",rest"`
+	}
+
+	os.Setenv("APP_NAME", "foobar")
+	os.Setenv("APP_PLUGINS_ENABLED", "true")
+	os.Setenv("APP_PLUGINS_AUTH_URL", "http://localhost")
+	os.Setenv("APP_TIMEOUT", "1m")
+	os.Setenv("app_debug", "true")
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Strict: true})
+	require.Nil(t, err)
+	require.Equal(t, "foobar", conf.Name)
+	require.Equal(t, map[string]string{"AUTH_URL": "http://localhost"}, conf.Plugins.Rest)
+	require.Equal(t, map[string]string{"TIMEOUT": "1m", "debug": "true"}, conf.Rest)
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, "envconfig: rest field requires a prefix", err.Error())
+
+	var conf2 struct {
+		Rest map[string]int `envconfig:",rest"`
+	}
+	err = envconfig.InitWithPrefix(&conf2, "APP")
+	require.Equal(t, "envconfig: rest field must be a map[string]string (field Rest)", err.Error())
+
+	os.Setenv("APP_NAME", "")
+	os.Setenv("APP_PLUGINS_ENABLED", "")
+	os.Setenv("APP_PLUGINS_AUTH_URL", "")
+	os.Setenv("APP_TIMEOUT", "")
+	os.Setenv("app_debug", "")
+}
+
 func TestUnexportedField(t *testing.T) {
 	var conf struct {
 		name string
@@ -403,7 +1007,8 @@ func TestUnexportedField(t *testing.T) {
 	os.Setenv("NAME", "foobar")
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, envconfig.ErrUnexportedField, err)
+	require.True(t, errors.Is(err, envconfig.ErrUnexportedField))
+	require.Equal(t, "envconfig: unexported field (field name)", err.Error())
 
 	err = envconfig.InitWithOptions(&conf, envconfig.Options{AllowUnexported: true})
 	require.Equal(t, nil, err)
@@ -421,7 +1026,8 @@ func TestNestedUnexportedField(t *testing.T) {
 	os.Setenv("FOO_BAR_BAZ", "foobar")
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, envconfig.ErrUnexportedField, err)
+	require.True(t, errors.Is(err, envconfig.ErrUnexportedField))
+	require.Equal(t, "envconfig: unexported field (field Foo.Bar.baz)", err.Error())
 
 	err = envconfig.InitWithOptions(&conf, envconfig.Options{AllowUnexported: true})
 	require.Equal(t, nil, err)
@@ -487,6 +1093,42 @@ func TestParseDefaultVal(t *testing.T) {
 	require.Equal(t, time.Minute*20, conf.MySQL.LocalTimeout)
 }
 
+func TestExistingAsDefaults(t *testing.T) {
+	type config struct {
+		Name    string
+		Timeout time.Duration `envconfig:"default=1m"`
+		Hosts   []string
+		Port    int
+	}
+
+	conf := config{
+		Name:    "foobar",
+		Timeout: time.Second,
+		Hosts:   []string{"localhost"},
+	}
+
+	os.Setenv("NAME", "")
+	os.Setenv("TIMEOUT", "")
+	os.Setenv("HOSTS", "")
+	os.Setenv("PORT", "")
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{ExistingAsDefaults: true})
+	require.Equal(t, "envconfig: keys PORT, port not found (field Port)", err.Error())
+
+	os.Setenv("PORT", "9000")
+	os.Setenv("HOSTS", "free.fr,google.com")
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{ExistingAsDefaults: true})
+	require.Nil(t, err)
+	require.Equal(t, "foobar", conf.Name)
+	require.Equal(t, time.Second, conf.Timeout)
+	require.Equal(t, []string{"free.fr", "google.com"}, conf.Hosts)
+	require.Equal(t, 9000, conf.Port)
+
+	os.Setenv("PORT", "")
+	os.Setenv("HOSTS", "")
+}
+
 func TestDefaultSlice(t *testing.T) {
 	// See https://github.com/vrischmann/envconfig/pull/15
 	//
@@ -501,7 +1143,8 @@ func TestDefaultSlice(t *testing.T) {
 
 	err := envconfig.Init(&conf)
 	require.NotNil(t, err)
-	require.Equal(t, envconfig.ErrDefaultUnsupportedOnSlice, err)
+	require.True(t, errors.Is(err, envconfig.ErrDefaultUnsupportedOnSlice))
+	require.Equal(t, "envconfig: default tag unsupported on slice (field Hosts)", err.Error())
 }
 
 func TestInitNotAPointer(t *testing.T) {
@@ -536,7 +1179,7 @@ func TestInvalidFieldValueKind(t *testing.T) {
 	os.Setenv("FOO", "lalala")
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, "envconfig: kind interface not supported", err.Error())
+	require.Equal(t, "envconfig: unable to parse FOO (field Foo): kind interface not supported", err.Error())
 }
 
 func TestInvalidSliceElementValueKind(t *testing.T) {
@@ -547,7 +1190,7 @@ func TestInvalidSliceElementValueKind(t *testing.T) {
 	os.Setenv("FOO", "lalala")
 
 	err := envconfig.Init(&conf)
-	require.Equal(t, "envconfig: kind interface not supported", err.Error())
+	require.Equal(t, "envconfig: unable to parse FOO (field Foo): kind interface not supported", err.Error())
 }
 
 func TestParseEmptyTag(t *testing.T) {
@@ -595,3 +1238,133 @@ func TestParseMapType(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, 1, conf.Map["a"])
 }
+
+func TestFileKeys(t *testing.T) {
+	var conf struct {
+		Password string
+		Name     string
+	}
+
+	path := filepath.Join(t.TempDir(), "password")
+	require.Nil(t, os.WriteFile(path, []byte("secret\n"), 0600))
+
+	os.Setenv("PASSWORD_FILE", path)
+	os.Setenv("NAME", "foo")
+	os.Setenv("NAME_FILE", path)
+
+	err := envconfig.Init(&conf)
+	require.NotNil(t, err)
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{FileKeys: true})
+	require.Nil(t, err)
+	require.Equal(t, "secret", conf.Password)
+	require.Equal(t, "foo", conf.Name)
+
+	os.Setenv("PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{FileKeys: true})
+	require.True(t, errors.Is(err, os.ErrNotExist))
+
+	os.Setenv("PASSWORD_FILE", "")
+	os.Setenv("NAME", "")
+	os.Setenv("NAME_FILE", "")
+}
+
+func TestFromFile(t *testing.T) {
+	var conf struct {
+		Cert  []byte `envconfig:",fromFile"`
+		Key   string `envconfig:",fromFile,trim,base64"`
+		Token string `envconfig:",trim"`
+	}
+
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "cert.pem"), []byte("-----BEGIN CERTIFICATE-----\n"), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "key"), []byte("Zm9vYmFy\n"), 0600))
+
+	os.Setenv("CERT", filepath.Join(dir, "cert.pem"))
+	os.Setenv("KEY", filepath.Join(dir, "key"))
+	os.Setenv("TOKEN", " foobar ")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, []byte("-----BEGIN CERTIFICATE-----\n"), conf.Cert)
+	require.Equal(t, "foobar", conf.Key)
+	require.Equal(t, "foobar", conf.Token)
+
+	os.Setenv("KEY", filepath.Join(dir, "missing"))
+
+	err = envconfig.Init(&conf)
+	require.True(t, errors.Is(err, os.ErrNotExist))
+	require.True(t, strings.HasPrefix(err.Error(), "envconfig: unable to read the file of KEY (field Key): "))
+
+	os.Setenv("KEY", filepath.Join(dir, "cert.pem"))
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, "envconfig: unable to parse KEY (field Key): invalid value", err.Error())
+
+	os.Setenv("CERT", "")
+	os.Setenv("KEY", "")
+	os.Setenv("TOKEN", "")
+}
+
+func TestMissingExports(t *testing.T) {
+	var conf struct {
+		Name    string `envconfig:"desc=Name of the service"`
+		Timeout time.Duration
+		Port    int `envconfig:"default=80"`
+	}
+
+	err := envconfig.InitWithPrefix(&conf, "APP")
+	require.Equal(t, `# Name of the service
+export APP_NAME=<string>
+export APP_TIMEOUT=<time.Duration>
+`, envconfig.MissingExports(err))
+
+	require.Equal(t, "", envconfig.MissingExports(nil))
+}
+
+func TestVariant(t *testing.T) {
+	var conf struct {
+		Timeout time.Duration `envconfig:"default=1s" onprem:"default=10s"`
+		Name    string        `onprem:"default=onprem"`
+	}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{"NAME": "foo"}})
+	require.Nil(t, err)
+	require.Equal(t, time.Second, conf.Timeout)
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{}, Variant: "onprem"})
+	require.Nil(t, err)
+	require.Equal(t, 10*time.Second, conf.Timeout)
+	require.Equal(t, "onprem", conf.Name)
+}
+
+type providedConfig struct {
+	Name string
+	Port int `envconfig:"default=80"`
+}
+
+func (*providedConfig) EnvconfigOptions() envconfig.Options {
+	return envconfig.Options{
+		Prefix: "APP",
+		Source: envconfig.MapSource{"APP_NAME": "foo", "WORKER_NAME": "bar"},
+	}
+}
+
+func TestOptionsProvider(t *testing.T) {
+	var conf providedConfig
+	require.Nil(t, envconfig.Init(&conf))
+	require.Equal(t, providedConfig{Name: "foo", Port: 80}, conf)
+
+	// the options of the caller take precedence
+	require.Nil(t, envconfig.InitWithPrefix(&conf, "WORKER"))
+	require.Equal(t, "bar", conf.Name)
+
+	values, err := envconfig.Marshal(&conf)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"APP_NAME": "bar", "APP_PORT": "80"}, values)
+
+	var usage strings.Builder
+	require.Nil(t, envconfig.WriteUsage(&usage, &conf, envconfig.Options{}))
+	require.Contains(t, usage.String(), "APP_NAME")
+}
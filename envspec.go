@@ -0,0 +1,64 @@
+package envconfig
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// EnvSpecFlag is the command line flag making HandleEnvSpecFlag print the specification of the environment.
+const EnvSpecFlag = "--print-env-spec"
+
+// GenerateEnvSpec writes the specification of the environment expected by InitWithOptions for the conf object
+// and opts to the file at path, in the format of WriteParameterSpec. The file is left untouched if it is up to date.
+//
+// It is meant to be called by a generator run by go generate, so that the specification is embedded into the binary
+// and the contract of any deployed artifact can be read without its source:
+//
+//	// gen/main.go
+//	func main() {
+//		if err := envconfig.GenerateEnvSpec("env-spec.json", &config.Config{}, envconfig.Options{Prefix: "APP"}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+//	// main.go
+//	//go:generate go run ./gen
+//	//go:embed env-spec.json
+//	var envSpec []byte
+func GenerateEnvSpec(path string, conf interface{}, opts Options) error {
+	var buf bytes.Buffer
+	if err := WriteParameterSpec(&buf, conf, opts); err != nil {
+		return err
+	}
+
+	if data, err := os.ReadFile(path); err == nil && bytes.Equal(data, buf.Bytes()) {
+		return nil
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// HandleEnvSpecFlag writes spec to w and returns true if the command line flags of args, like os.Args,
+// contain EnvSpecFlag. The program should then exit before reading its configuration:
+//
+//	if envconfig.HandleEnvSpecFlag(os.Args, os.Stdout, envSpec) {
+//		return
+//	}
+//
+// The flag is also recognized with a single dash. The flags after the terminator -- are ignored.
+func HandleEnvSpecFlag(args []string, w io.Writer, spec []byte) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	for _, arg := range args[1:] {
+		switch arg {
+		case "--":
+			return false
+		case EnvSpecFlag, EnvSpecFlag[1:]:
+			w.Write(spec)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestGenerateEnvSpec(t *testing.T) {
+	var conf struct {
+		Name string
+		Port int `envconfig:"default=80"`
+	}
+
+	path := filepath.Join(t.TempDir(), "env-spec.json")
+	require.Nil(t, envconfig.GenerateEnvSpec(path, &conf, envconfig.Options{Prefix: "APP"}))
+
+	var expected bytes.Buffer
+	require.Nil(t, envconfig.WriteParameterSpec(&expected, &conf, envconfig.Options{Prefix: "APP"}))
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, expected.String(), string(data))
+
+	// the file is not rewritten if it is up to date
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.Nil(t, os.Chtimes(path, old, old))
+	require.Nil(t, envconfig.GenerateEnvSpec(path, &conf, envconfig.Options{Prefix: "APP"}))
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+	require.True(t, info.ModTime().Equal(old))
+}
+
+func TestHandleEnvSpecFlag(t *testing.T) {
+	spec := []byte(`{"parameters": []}`)
+
+	testCases := []struct {
+		args    []string
+		handled bool
+	}{
+		{[]string{"app"}, false},
+		{[]string{"app", "--print-env-spec"}, true},
+		{[]string{"app", "-v", "-print-env-spec"}, true},
+		{[]string{"app", "--", "--print-env-spec"}, false},
+		{nil, false},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		require.Equal(t, tc.handled, envconfig.HandleEnvSpecFlag(tc.args, &buf, spec), "%v", tc.args)
+		if tc.handled {
+			require.Equal(t, string(spec), buf.String())
+		} else {
+			require.Empty(t, buf.String())
+		}
+	}
+}
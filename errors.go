@@ -0,0 +1,278 @@
+package envconfig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MissingKeyError is the error returned when none of the keys of a required field are found.
+type MissingKeyError struct {
+	// Field is the path of the field in the config struct, for example MySQL.Master.Address.
+	Field string
+	// Description is the description of the field from the desc tag, if any.
+	Description string
+	// Keys are all the keys which were looked up, Key is the canonical one.
+	Keys []string
+	Key  string
+	// Type is the type of the field, like int or time.Duration.
+	Type string
+	// Suggestion is the name of a defined environment variable close to one of the keys, if any.
+	// It is most likely a typo.
+	Suggestion string
+}
+
+func (e *MissingKeyError) Error() string {
+	field := e.Field
+	if e.Description != "" {
+		field += ", " + e.Description
+	}
+
+	msg := fmt.Sprintf("envconfig: keys %s not found (field %s)", strings.Join(e.Keys, ", "), field)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+	return msg
+}
+
+// MissingExports returns a line like `export KEY=<type>` for each key reported missing by err,
+// ready to be pasted in a shell and completed:
+//
+//	if err := envconfig.Init(&conf); err != nil {
+//		fmt.Fprint(os.Stderr, envconfig.MissingExports(err))
+//	}
+func MissingExports(err error) string {
+	var b strings.Builder
+	walkErrors(err, func(err error) {
+		if e, ok := err.(*MissingKeyError); ok {
+			if e.Description != "" {
+				fmt.Fprintf(&b, "# %s\n", e.Description)
+			}
+			fmt.Fprintf(&b, "export %s=<%s>\n", e.Key, e.Type)
+		}
+	})
+	return b.String()
+}
+
+// walkErrors calls fn for err and all the errors it wraps, depth first.
+func walkErrors(err error, fn func(error)) {
+	if err == nil {
+		return
+	}
+
+	fn(err)
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			walkErrors(err, fn)
+		}
+	case interface{ Unwrap() error }:
+		walkErrors(e.Unwrap(), fn)
+	}
+}
+
+// ParseError is the error returned when the value of a field can't be parsed.
+type ParseError struct {
+	// Field is the path of the field in the config struct, for example MySQL.Master.Address.
+	Field string
+	// Key is the key the value was read from. It is empty if the value is the default one.
+	Key string
+	// Value is the raw value. If Secret is true, it is empty or redacted by the redact option of the field.
+	Value string
+	// Secret is true if the field is a secret. In that case the message of the underlying error,
+	// which often contains the value, is not included in Error.
+	Secret bool
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	reason := "invalid value"
+	switch {
+	case !e.Secret:
+		reason = e.Err.Error()
+	case e.Value != "":
+		reason += " " + e.Value
+	}
+
+	if e.Key == "" {
+		return fmt.Sprintf("envconfig: unable to parse default value (field %s): %s", e.Field, reason)
+	}
+	return fmt.Sprintf("envconfig: unable to parse %s (field %s): %s", e.Key, e.Field, reason)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is the error returned when a struct implementing Validator is invalid.
+type ValidationError struct {
+	// Field is the path of the struct in the config struct, it is empty for the config struct itself.
+	Field string
+	// Err is the error returned by Validate.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("envconfig: invalid config: %v", e.Err)
+	}
+	return fmt.Sprintf("envconfig: invalid config (field %s): %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownKeyError is the error returned in strict mode when an environment variable starting with
+// the prefix doesn't match any field.
+type UnknownKeyError struct {
+	Key string
+	// Suggestion is the known key closest to Key, if any.
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	msg := fmt.Sprintf("envconfig: unknown key %s", e.Key)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestion)
+	}
+	return msg
+}
+
+// KeyNameError is the error returned with the option ValidateKeys when a key of a field isn't a valid name
+// of environment variable.
+type KeyNameError struct {
+	Field string
+	Key   string
+	// Reason explains why the key is invalid, like "it starts with a digit".
+	Reason string
+}
+
+func (e *KeyNameError) Error() string {
+	return fmt.Sprintf("envconfig: invalid key %q (field %s): %s", e.Key, e.Field, e.Reason)
+}
+
+// GroupError is the error returned when none of the groups of fields of a struct is complete.
+type GroupError struct {
+	// Field is the path of the struct in the config struct, it is empty for the config struct itself.
+	Field string
+	// Groups are the names of the groups, in order of appearance.
+	Groups []string
+	// Missing are the canonical keys of the missing fields of each group.
+	Missing map[string][]string
+}
+
+func (e *GroupError) Error() string {
+	alternatives := make([]string, len(e.Groups))
+	for i, group := range e.Groups {
+		alternatives[i] = fmt.Sprintf("%s (missing %s)", group, strings.Join(e.Missing[group], ", "))
+	}
+
+	field := ""
+	if e.Field != "" {
+		field = fmt.Sprintf(" (field %s)", e.Field)
+	}
+
+	return fmt.Sprintf("envconfig: one of these groups must be complete%s: %s", field, strings.Join(alternatives, " or "))
+}
+
+// ProbeError is the error returned when the probe of a field fails.
+type ProbeError struct {
+	// Field is the path of the field in the config struct, for example MySQL.Master.Address.
+	Field string
+	// Key is the key the value was read from. It is empty if the value is the default one.
+	Key string
+	// Value is the probed value. If Secret is true, it is empty or redacted by the redact option of the field.
+	Value string
+	// Probe is the name of the probe, like dns or tcp.
+	Probe string
+	// Secret is true if the field is a secret. In that case the message of the underlying error,
+	// which often contains the value, is not included in Error.
+	Secret bool
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ProbeError) Error() string {
+	msg := fmt.Sprintf("envconfig: %s probe of %s failed (field %s)", e.Probe, keyOrDefault(e.Key), e.Field)
+	switch {
+	case !e.Secret:
+		msg += ": " + e.Err.Error()
+	case e.Value != "":
+		msg += " for " + e.Value
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *ProbeError) Unwrap() error {
+	return e.Err
+}
+
+// CanaryError is the error of a reload whose configuration was rejected by a canary of a Store.
+type CanaryError struct {
+	Err error
+}
+
+func (e *CanaryError) Error() string {
+	return fmt.Sprintf("envconfig: config rejected by canary: %v", e.Err)
+}
+
+func (e *CanaryError) Unwrap() error {
+	return e.Err
+}
+
+// DeadlineError is the error returned when the resolution of the keys exceeds the option Timeout.
+type DeadlineError struct {
+	// Resolved are the keys resolved before the deadline.
+	Resolved []string
+	// Pending is the key which was being looked up in Source when the deadline was exceeded.
+	Pending string
+	Source  string
+}
+
+func (e *DeadlineError) Error() string {
+	resolved := "none"
+	if len(e.Resolved) > 0 {
+		resolved = strings.Join(e.Resolved, ", ")
+	}
+	return fmt.Sprintf("envconfig: deadline exceeded while looking up %s in %s (resolved: %s)", e.Pending, e.Source, resolved)
+}
+
+// Unwrap returns context.DeadlineExceeded, so that errors.Is(err, context.DeadlineExceeded) is true.
+func (e *DeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// SourceError is the error of a member of a Chain, named by Source.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// failedLookupError is the error of a required field missing from the members of a Chain which didn't fail.
+// It reads as the error of the first member which failed, and also wraps the *MissingKeyError of the field.
+type failedLookupError struct {
+	err     error
+	missing *MissingKeyError
+}
+
+func (e *failedLookupError) Error() string {
+	return e.err.Error()
+}
+
+func (e *failedLookupError) Unwrap() []error {
+	return []error{e.err, e.missing}
+}
@@ -0,0 +1,384 @@
+// Package etcd provides an envconfig source reading the values from etcd v3.
+//
+// The keys are mapped to keys under Config.Prefix: the key MYSQL_MASTER_ADDRESS is read from
+// /config/myapp/mysql/master/address with the prefix /config/myapp:
+//
+//	source, err := etcd.New(etcd.Config{Endpoint: "http://etcd:2379", Prefix: "/config/myapp"})
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+//
+// All the values under the prefix are read with a single request and kept by the source. Watch uses
+// the watch API of etcd to detect changes, for example to reload a store:
+//
+//	go source.Watch(ctx, func() { store.Reload() })
+//
+// Shutdown stops the calls to Watch and waits for them to return, when the application stops.
+// Health returns the error which stopped the last call to Watch, for the health endpoint of the application.
+//
+// The source only talks to the JSON gateway of etcd, it doesn't depend on the etcd client.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/internal/lifecycle"
+)
+
+// Config is the configuration of a Source.
+type Config struct {
+	// Endpoint is the address of an etcd member, like http://etcd:2379.
+	Endpoint string
+	// Username and Password are the credentials, if authentication is enabled.
+	// The source authenticates again when its token expires.
+	Username string
+	Password string
+
+	// Prefix is the prefix of the keys, like /config/myapp.
+	Prefix string
+
+	// HTTPClient is the client used to talk to etcd, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// Source reads the values from etcd. It implements envconfig.Source, envconfig.ContextSource and envconfig.Lister.
+type Source struct {
+	cfg Config
+
+	mu    sync.Mutex
+	token string
+	// values are the values under the prefix by relative key, nil until they are read.
+	values   map[string]string
+	revision int64
+
+	// lifecycle tracks the calls to Watch.
+	lifecycle lifecycle.Lifecycle
+}
+
+// New returns a new source. It authenticates if there are credentials, the values are read on the first lookup.
+func New(cfg Config) (*Source, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("etcd: no endpoint")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	cfg.Prefix = strings.TrimSuffix(cfg.Prefix, "/") + "/"
+
+	s := &Source{cfg: cfg}
+	if cfg.Username != "" {
+		if err := s.authenticate(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// String returns the name of the source used in errors.
+func (s *Source) String() string {
+	return "etcd " + s.cfg.Endpoint
+}
+
+// Lookup implements envconfig.Source.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, "")
+}
+
+// LookupContext implements envconfig.ContextSource, the values are read with ctx if they are not yet.
+func (s *Source) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	values, err := s.load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	v, ok := values[strings.ToLower(strings.ReplaceAll(key, "_", "/"))]
+	return v, ok, nil
+}
+
+// Keys implements envconfig.Lister, it returns the keys of the values under the prefix, like MYSQL_MASTER_ADDRESS.
+func (s *Source) Keys() []string {
+	values, err := s.load(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, strings.ToUpper(strings.ReplaceAll(k, "/", "_")))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Refresh reads the values again.
+func (s *Source) Refresh() error {
+	return s.refresh(context.Background())
+}
+
+// Watch watches the keys under the prefix, and calls onChange once the new values are read after a change.
+// It returns when ctx is done or when the watch fails, and envconfig.ErrShutdown once the source is shut down.
+func (s *Source) Watch(ctx context.Context, onChange func()) error {
+	watchCtx, release, err := s.lifecycle.Start(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = s.watch(watchCtx, onChange)
+	if ctx.Err() == nil && s.lifecycle.Stopped() {
+		return envconfig.ErrShutdown
+	}
+	if ctx.Err() == nil {
+		s.lifecycle.Fail(err)
+	}
+	return err
+}
+
+// Health implements envconfig.HealthChecker, it returns the error which stopped the last call to Watch,
+// until the values are read again or a new call to Watch is connected.
+func (s *Source) Health() error {
+	return s.lifecycle.Err()
+}
+
+func (s *Source) watch(ctx context.Context, onChange func()) error {
+	if _, err := s.load(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	revision := s.revision
+	s.mu.Unlock()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"create_request": map[string]interface{}{
+			"key":            []byte(s.cfg.Prefix),
+			"range_end":      prefixEnd(s.cfg.Prefix),
+			"start_revision": revision + 1,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.post(ctx, "/v3/watch", body)
+	if err != nil {
+		return s.watchErr(ctx, err)
+	}
+	defer resp.Body.Close()
+	s.lifecycle.Recover()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Result struct {
+				Events   []json.RawMessage `json:"events"`
+				Canceled bool              `json:"canceled"`
+				Reason   string            `json:"cancel_reason"`
+			} `json:"result"`
+		}
+		if err := dec.Decode(&msg); err != nil {
+			return s.watchErr(ctx, err)
+		}
+
+		if msg.Result.Canceled {
+			return fmt.Errorf("etcd: watch canceled: %s", msg.Result.Reason)
+		}
+		if len(msg.Result.Events) == 0 {
+			continue
+		}
+
+		if err := s.refresh(ctx); err != nil {
+			return s.watchErr(ctx, err)
+		}
+		onChange()
+	}
+}
+
+// Shutdown stops the calls to Watch and waits for them to return, or for ctx to be done.
+// The source keeps serving the values it read, but Watch can't be called anymore.
+func (s *Source) Shutdown(ctx context.Context) error {
+	return s.lifecycle.Shutdown(ctx)
+}
+
+// watchErr returns the error of ctx if it is done, err otherwise.
+func (s *Source) watchErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == io.EOF {
+		return errors.New("etcd: watch closed")
+	}
+	return err
+}
+
+// load returns the values, reading them if needed.
+func (s *Source) load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	values := s.values
+	s.mu.Unlock()
+
+	if values != nil {
+		return values, nil
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values, nil
+}
+
+// refresh reads all the values under the prefix.
+func (s *Source) refresh(ctx context.Context) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"key":       []byte(s.cfg.Prefix),
+		"range_end": prefixEnd(s.cfg.Prefix),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.post(ctx, "/v3/kv/range", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Header struct {
+			Revision int64 `json:"revision,string"`
+		} `json:"header"`
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("etcd: invalid response: %w", err)
+	}
+
+	values := make(map[string]string, len(res.Kvs))
+	for _, kv := range res.Kvs {
+		values[strings.ToLower(strings.TrimPrefix(string(kv.Key), s.cfg.Prefix))] = string(kv.Value)
+	}
+
+	s.mu.Lock()
+	s.values, s.revision = values, res.Header.Revision
+	s.mu.Unlock()
+	s.lifecycle.Recover()
+
+	return nil
+}
+
+const authenticatePath = "/v3/auth/authenticate"
+
+// authenticate gets a token with the credentials.
+func (s *Source) authenticate(ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{"name": s.cfg.Username, "password": s.cfg.Password})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.post(ctx, authenticatePath, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil || res.Token == "" {
+		return errors.New("etcd: authentication failed: no token")
+	}
+
+	s.mu.Lock()
+	s.token = res.Token
+	s.mu.Unlock()
+
+	return nil
+}
+
+// post sends a request to the JSON gateway of etcd. The caller must close the body of the response.
+// If the token expired, it authenticates again and retries once.
+func (s *Source) post(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	resp, err := s.send(ctx, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && s.cfg.Username != "" && path != authenticatePath {
+		resp.Body.Close()
+
+		if err := s.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		if resp, err = s.send(ctx, path, body); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("etcd: POST %s: %s: %s", path, resp.Status, apiErr.Message)
+		}
+		return nil, fmt.Errorf("etcd: POST %s: %s", path, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// send sends a request with the current token.
+func (s *Source) send(ctx context.Context, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.cfg.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	return resp, nil
+}
+
+// prefixEnd returns the end of the range of the keys starting with prefix.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// all the keys
+	return []byte{0}
+}
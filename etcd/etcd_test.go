@@ -0,0 +1,215 @@
+package etcd_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/etcd"
+)
+
+type etcdServer struct {
+	t        *testing.T
+	mu       sync.Mutex
+	revision int
+	values   map[string]string
+	changed  chan struct{}
+	// token is the valid token, "token" by default, and logins is the number of authentications.
+	token  string
+	logins int
+}
+
+func (s *etcdServer) validToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == "" {
+		return "token"
+	}
+	return s.token
+}
+
+func (s *etcdServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var body map[string]string
+		require.Nil(s.t, json.NewDecoder(r.Body).Decode(&body))
+		if body["password"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "etcdserver: authentication failed, invalid user ID or password"})
+			return
+		}
+		s.mu.Lock()
+		s.logins++
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"token": s.validToken()})
+		return
+	}
+
+	if r.Header.Get("Authorization") != s.validToken() {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"message": "etcdserver: invalid auth token"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var body struct {
+			Key      []byte `json:"key"`
+			RangeEnd []byte `json:"range_end"`
+		}
+		require.Nil(s.t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(s.t, "/config/myapp/", string(body.Key))
+		require.Equal(s.t, "/config/myapp0", string(body.RangeEnd))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var kvs []map[string][]byte
+		for k, v := range s.values {
+			kvs = append(kvs, map[string][]byte{"key": []byte("/config/myapp/" + k), "value": []byte(v)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"header": map[string]string{"revision": strconv.Itoa(s.revision)},
+			"kvs":    kvs,
+		})
+
+	case "/v3/watch":
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"created": true}})
+		w.(http.Flusher).Flush()
+
+		for {
+			select {
+			case <-s.changed:
+				json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"events": []interface{}{map[string]string{"type": "PUT"}}}})
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	server := &etcdServer{
+		t:        t,
+		revision: 1,
+		values:   map[string]string{"name": "foo", "mysql/master/address": "db:3306"},
+		changed:  make(chan struct{}),
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	_, err := etcd.New(etcd.Config{Endpoint: srv.URL, Username: "root", Password: "wrong"})
+	require.Equal(t, "etcd: POST /v3/auth/authenticate: 400 Bad Request: etcdserver: authentication failed, invalid user ID or password", err.Error())
+
+	source, err := etcd.New(etcd.Config{Endpoint: srv.URL, Username: "root", Password: "secret", Prefix: "/config/myapp"})
+	require.Nil(t, err)
+
+	var conf struct {
+		Name  string
+		MySQL struct {
+			Master struct {
+				Address string
+			}
+		}
+	}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, "db:3306", conf.MySQL.Master.Address)
+	require.Equal(t, []string{"MYSQL_MASTER_ADDRESS", "NAME"}, source.Keys())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- source.Watch(ctx, func() { changes <- struct{}{} })
+	}()
+
+	server.mu.Lock()
+	server.revision = 2
+	server.values["name"] = "bar"
+	server.mu.Unlock()
+	server.changed <- struct{}{}
+
+	<-changes
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "bar", conf.Name)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-done)
+}
+
+func TestWatchShutdown(t *testing.T) {
+	server := &etcdServer{
+		t:        t,
+		revision: 1,
+		values:   map[string]string{"name": "foo"},
+		changed:  make(chan struct{}),
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	source, err := etcd.New(etcd.Config{Endpoint: srv.URL, Username: "root", Password: "secret", Prefix: "/config/myapp"})
+	require.Nil(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- source.Watch(context.Background(), func() {})
+	}()
+
+	err = source.Shutdown(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, envconfig.ErrShutdown, <-done)
+	require.Equal(t, envconfig.ErrShutdown, source.Watch(context.Background(), func() {}))
+	// a shutdown isn't a failure
+	require.Nil(t, source.Health())
+}
+
+func TestTokenExpiration(t *testing.T) {
+	server := &etcdServer{
+		t:        t,
+		revision: 1,
+		values:   map[string]string{"name": "foo"},
+		changed:  make(chan struct{}),
+	}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	source, err := etcd.New(etcd.Config{Endpoint: srv.URL, Username: "root", Password: "secret", Prefix: "/config/myapp"})
+	require.Nil(t, err)
+	require.Nil(t, source.Refresh())
+
+	// the token expires: the source authenticates again
+	server.mu.Lock()
+	server.token = "token2"
+	server.values["name"] = "bar"
+	server.mu.Unlock()
+
+	require.Nil(t, source.Refresh())
+	server.mu.Lock()
+	require.Equal(t, 2, server.logins)
+	server.mu.Unlock()
+
+	var conf struct {
+		Name string
+	}
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "bar", conf.Name)
+
+	// without credentials, the error is returned
+	source, err = etcd.New(etcd.Config{Endpoint: srv.URL, Prefix: "/config/myapp"})
+	require.Nil(t, err)
+	err = source.Refresh()
+	require.NotNil(t, err)
+	require.Equal(t, "etcd: POST /v3/kv/range: 401 Unauthorized: etcdserver: invalid auth token", err.Error())
+}
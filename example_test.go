@@ -83,7 +83,54 @@ func ExampleInitWithPrefix() {
 
 	fmt.Println(conf.Name)
 	// Output:
-	// envconfig: keys FOO_NAME, foo_name not found
+	// envconfig: keys FOO_NAME, foo_name not found (field Name)
 	// <nil>
 	// foobar
 }
+
+func ExampleWriteUsage() {
+	var conf struct {
+		Name string
+		Log  struct {
+			Path  string `envconfig:"default=/var/log/mylog.log"`
+			Level string `envconfig:"optional,desc=Minimum level, one of debug, info or error"`
+		}
+		Timeout time.Duration `envconfig:"myTimeout"`
+		Hosts   []string
+	}
+
+	if err := envconfig.WriteUsage(os.Stdout, &conf, envconfig.Options{Prefix: "APP"}); err != nil {
+		fmt.Printf("err=%s\n", err)
+	}
+	// Output:
+	// KEY            TYPE           DEFAULT             OPTIONAL  DESCRIPTION
+	// APP_NAME       string                             no
+	// APP_LOG_PATH   string         /var/log/mylog.log  yes
+	// APP_LOG_LEVEL  string                             yes       Minimum level, one of debug, info or error
+	// myTimeout      time.Duration                      no
+	// APP_HOSTS      []string                           no
+}
+
+func ExampleWriteEnvTemplate() {
+	var conf struct {
+		Addr string `envconfig:"default=:8080,desc=Listen address of the HTTP server"`
+		Name string
+		Log  struct {
+			Level string `envconfig:"optional"`
+		}
+	}
+
+	if err := envconfig.WriteEnvTemplate(os.Stdout, &conf, envconfig.Options{Prefix: "APP"}); err != nil {
+		fmt.Printf("err=%s\n", err)
+	}
+	// Output:
+	// # Listen address of the HTTP server
+	// # string, optional
+	// # APP_ADDR=:8080
+	//
+	// # string, required
+	// APP_NAME=
+	//
+	// # string, optional
+	// # APP_LOG_LEVEL=
+}
@@ -0,0 +1,59 @@
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// VerifyExamples parses the example value of each field with the example option into a new value of the type of
+// conf, with the options of the field, and returns an error for each example which can't be parsed, so that the
+// documented examples are guaranteed to be valid. conf must be a pointer. It is meant to be called from a test:
+//
+//	func TestConfigExamples(t *testing.T) {
+//		if err := envconfig.VerifyExamples(&Config{}); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+//
+// The examples of the fields read from a file or encrypted are not verified, nor the validation of the structs.
+func VerifyExamples(conf interface{}) error {
+	value := reflect.ValueOf(conf)
+	if value.Kind() != reflect.Ptr {
+		return ErrNotAPointer
+	}
+
+	fields, err := describe(conf, Options{})
+	if err != nil {
+		return err
+	}
+
+	examples := make(MapSource)
+	for _, f := range fields {
+		t := ParseTag(f.tag.Get("envconfig"))
+		if t.Example == "" || t.FromFile || t.Encrypted {
+			continue
+		}
+		examples[f.key] = t.Example
+	}
+	if len(examples) == 0 {
+		return nil
+	}
+
+	// the examples are read into a new value, the fields without example are optional
+	_, err = initWithChecks(context.Background(), reflect.New(value.Type().Elem()).Interface(), Options{
+		Source:      examples,
+		AllOptional: true,
+		SkipProbes:  true,
+	}, nil)
+
+	var errs []error
+	for _, err := range unjoin(err) {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, err)
+		}
+	}
+
+	return joinErrors(errs)
+}
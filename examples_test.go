@@ -0,0 +1,45 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestVerifyExamples(t *testing.T) {
+	type config struct {
+		Addr    string        `envconfig:"example=:8080"`
+		Timeout time.Duration `envconfig:"example=10s"`
+		Country string        `envconfig:",iso3166,example=FR"`
+		Pod     envconfig.PodInfo
+		Key     string `envconfig:",fromFile,example=/run/secrets/key"`
+	}
+	require.Nil(t, envconfig.VerifyExamples(&config{}))
+
+	var invalid struct {
+		Addr    string        `envconfig:"example=:8080"`
+		Timeout time.Duration `envconfig:"example=10"`
+		Country string        `envconfig:",iso3166,example=France"`
+		Port    int           `envconfig:"APP_PORT,example=http"`
+	}
+	err := envconfig.VerifyExamples(&invalid)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "unable to parse TIMEOUT (field Timeout)")
+	require.Contains(t, err.Error(), `unable to parse COUNTRY (field Country): "France" is not an ISO 3166-1 alpha-2 country code`)
+	require.Contains(t, err.Error(), "unable to parse APP_PORT (field Port)")
+	require.NotContains(t, err.Error(), "Addr")
+
+	require.Equal(t, envconfig.ErrNotAPointer, envconfig.VerifyExamples(invalid))
+}
+
+func TestExampleDocumentation(t *testing.T) {
+	var conf struct {
+		Addr string `envconfig:"example=:8080,desc=Listen address"`
+	}
+	var buf bytes.Buffer
+	require.Nil(t, envconfig.WriteUsage(&buf, &conf, envconfig.Options{}))
+	require.Contains(t, buf.String(), "Listen address, e.g. :8080")
+}
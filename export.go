@@ -0,0 +1,149 @@
+package envconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// exportVar is a variable written by the exporters.
+type exportVar struct {
+	key         string
+	value       string
+	description string
+	secret      bool
+	// hasDefault is set if the field has a default value.
+	hasDefault bool
+}
+
+// exportVars returns the variables of the conf object, in the order Init reads them.
+// The fields left empty get their default value.
+func exportVars(conf interface{}, opts Options) ([]exportVar, error) {
+	fields, err := describe(conf, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := MarshalWithOptions(conf, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []exportVar
+	for _, f := range fields {
+		if f.rest {
+			continue
+		}
+
+		value, ok := values[f.key]
+		if !ok {
+			value = f.defaultVal
+		}
+		delete(values, f.key)
+
+		res = append(res, exportVar{
+			key:         f.key,
+			value:       value,
+			description: f.description,
+			secret:      f.secret,
+			hasDefault:  f.defaultVal != "",
+		})
+	}
+
+	// the remaining values come from the rest fields
+	rest := make([]string, 0, len(values))
+	for key := range values {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+
+	for _, key := range rest {
+		res = append(res, exportVar{key: key, value: values[key]})
+	}
+
+	return res, nil
+}
+
+// writeExport writes the variables of the conf object to w, formatting each one with format.
+// The descriptions are written as comments starting with #.
+func writeExport(w io.Writer, conf interface{}, opts Options, format func(key, value string) string) error {
+	vars, err := exportVars(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, v := range vars {
+		if v.value == "" {
+			continue
+		}
+		if v.description != "" {
+			fmt.Fprintf(bw, "# %s\n", v.description)
+		}
+		bw.WriteString(format(v.key, v.value) + "\n")
+	}
+
+	return bw.Flush()
+}
+
+// WriteEnvrc writes a direnv .envrc file exporting the values of the conf object with opts to w.
+// conf must be a pointer.
+//
+// The fields left empty get their default value, the descriptions are written as comments:
+//
+//	# Listen address of the HTTP server
+//	export APP_ADDR=:8080
+func WriteEnvrc(w io.Writer, conf interface{}, opts Options) error {
+	return writeExport(w, conf, opts, func(key, value string) string {
+		return "export " + key + "=" + quoteShell(value)
+	})
+}
+
+// WritePowerShell writes a PowerShell script setting the values of the conf object with opts to w.
+// conf must be a pointer. See WriteEnvrc.
+//
+//	# Listen address of the HTTP server
+//	$env:APP_ADDR = ':8080'
+func WritePowerShell(w io.Writer, conf interface{}, opts Options) error {
+	return writeExport(w, conf, opts, func(key, value string) string {
+		// single quoted strings are not expanded
+		return "$env:" + key + " = '" + strings.ReplaceAll(value, "'", "''") + "'"
+	})
+}
+
+// WriteFish writes a fish script exporting the values of the conf object with opts to w.
+// conf must be a pointer. See WriteEnvrc.
+//
+//	# Listen address of the HTTP server
+//	set -x APP_ADDR :8080
+func WriteFish(w io.Writer, conf interface{}, opts Options) error {
+	return writeExport(w, conf, opts, func(key, value string) string {
+		return "set -x " + key + " " + quoteFish(value)
+	})
+}
+
+// isShellSafe returns true if s can be written without quotes in a shell script.
+func isShellSafe(s string) bool {
+	return s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-.,:/@+=") == ""
+}
+
+// quoteShell quotes the value if needed to be read by a POSIX shell.
+func quoteShell(s string) string {
+	if isShellSafe(s) {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish quotes the value if needed to be read by the fish shell.
+func quoteFish(s string) string {
+	if isShellSafe(s) {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(s) + "'"
+}
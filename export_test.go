@@ -0,0 +1,65 @@
+package envconfig
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type exportConfig struct {
+	Addr  string `envconfig:"default=:8080,desc=Listen address of the HTTP server"`
+	Name  string
+	Debug bool              `envconfig:"optional"`
+	Extra map[string]string `envconfig:",rest"`
+}
+
+func TestWriteEnvrc(t *testing.T) {
+	conf := exportConfig{
+		Name:  "it's mine",
+		Extra: map[string]string{"FOO": "bar"},
+	}
+
+	var buf bytes.Buffer
+	err := WriteEnvrc(&buf, &conf, Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, `# Listen address of the HTTP server
+export APP_ADDR=:8080
+export APP_NAME='it'\''s mine'
+export APP_DEBUG=false
+export APP_FOO=bar
+`, buf.String())
+}
+
+func TestWritePowerShell(t *testing.T) {
+	conf := exportConfig{Name: "it's $mine"}
+
+	var buf bytes.Buffer
+	err := WritePowerShell(&buf, &conf, Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, `# Listen address of the HTTP server
+$env:APP_ADDR = ':8080'
+$env:APP_NAME = 'it''s $mine'
+$env:APP_DEBUG = 'false'
+`, buf.String())
+}
+
+func TestWriteFish(t *testing.T) {
+	conf := exportConfig{Name: `it's a\b`}
+
+	var buf bytes.Buffer
+	err := WriteFish(&buf, &conf, Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, `# Listen address of the HTTP server
+set -x APP_ADDR :8080
+set -x APP_NAME 'it\'s a\\b'
+set -x APP_DEBUG false
+`, buf.String())
+}
+
+func TestQuoteShell(t *testing.T) {
+	require.Equal(t, "''", quoteShell(""))
+	require.Equal(t, "postgres://localhost:5432/db", quoteShell("postgres://localhost:5432/db"))
+	require.Equal(t, "'$HOME'", quoteShell("$HOME"))
+	require.Equal(t, `'a'\''b'`, quoteShell("a'b"))
+}
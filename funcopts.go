@@ -0,0 +1,97 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OptionFunc builds a functional option of type O from the value of a field, see FunctionalOptions.
+type OptionFunc[O any] func(value interface{}) (O, error)
+
+// Option returns an OptionFunc calling fn with the value of the field, which must be of type T.
+// It adapts the constructors of functional options, like Option(redis.WithAddr).
+func Option[T, O any](fn func(T) O) OptionFunc[O] {
+	return func(value interface{}) (O, error) {
+		v, ok := value.(T)
+		if !ok {
+			var zero O
+			return zero, fmt.Errorf("expected a %T, got a %T", *new(T), value)
+		}
+		return fn(v), nil
+	}
+}
+
+// FunctionalOptions converts the config struct conf into functional options for another library,
+// with mapping giving the function building the option of each field by path, like MySQL.Address:
+//
+//	opts, err := envconfig.FunctionalOptions(&conf.Redis, map[string]envconfig.OptionFunc[redis.Option]{
+//		"Addr":    envconfig.Option(redis.WithAddr),
+//		"Timeout": envconfig.Option(redis.WithTimeout),
+//	})
+//	client := redis.New(opts...)
+//
+// The options are returned in the order of the fields. The fields holding a zero value are skipped,
+// so that the defaults of the library apply. A path of the mapping which is not a field is an error.
+func FunctionalOptions[O any](conf interface{}, mapping map[string]OptionFunc[O]) ([]O, error) {
+	value := reflect.ValueOf(conf)
+	if value.Kind() != reflect.Ptr {
+		return nil, ErrNotAPointer
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil, ErrInvalidValueKind
+	}
+
+	var res []O
+	seen := make(map[string]bool)
+	if err := functionalOptions(value, "", mapping, seen, &res); err != nil {
+		return nil, err
+	}
+
+	for path := range mapping {
+		if !seen[path] {
+			return nil, fmt.Errorf("envconfig: no field %s", path)
+		}
+	}
+
+	return res, nil
+}
+
+func functionalOptions[O any](value reflect.Value, path string, mapping map[string]OptionFunc[O], seen map[string]bool, res *[]O) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		fieldPath := combineName(path, value.Type().Field(i).Name)
+
+		if !field.CanInterface() {
+			continue
+		}
+
+		fn, ok := mapping[fieldPath]
+		if ok {
+			seen[fieldPath] = true
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+
+		switch {
+		case ok:
+			if field.IsZero() {
+				continue
+			}
+			opt, err := fn(field.Interface())
+			if err != nil {
+				return fmt.Errorf("envconfig: unable to build the option of %s: %w", fieldPath, err)
+			}
+			*res = append(*res, opt)
+
+		case field.Kind() == reflect.Struct && !isUnmarshaler(field.Type()):
+			if err := functionalOptions(field, fieldPath, mapping, seen, res); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
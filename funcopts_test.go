@@ -0,0 +1,61 @@
+package envconfig_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type client struct {
+	addr    string
+	timeout time.Duration
+	tls     bool
+}
+
+type clientOption func(c *client)
+
+func withAddr(addr string) clientOption        { return func(c *client) { c.addr = addr } }
+func withTimeout(d time.Duration) clientOption { return func(c *client) { c.timeout = d } }
+func withTLS(enabled bool) clientOption        { return func(c *client) { c.tls = enabled } }
+
+func TestFunctionalOptions(t *testing.T) {
+	var conf struct {
+		Name  string
+		Redis struct {
+			Addr    string
+			Timeout *time.Duration
+			TLS     bool
+		}
+	}
+	conf.Redis.Addr = "localhost:6379"
+	timeout := time.Second
+	conf.Redis.Timeout = &timeout
+
+	mapping := map[string]envconfig.OptionFunc[clientOption]{
+		"Redis.Addr":    envconfig.Option(withAddr),
+		"Redis.Timeout": envconfig.Option(withTimeout),
+		"Redis.TLS":     envconfig.Option(withTLS),
+	}
+
+	opts, err := envconfig.FunctionalOptions(&conf, mapping)
+	require.Nil(t, err)
+	require.Len(t, opts, 2)
+
+	var c client
+	for _, opt := range opts {
+		opt(&c)
+	}
+	require.Equal(t, client{addr: "localhost:6379", timeout: time.Second}, c)
+
+	mapping["Redis.Port"] = envconfig.Option(withAddr)
+	_, err = envconfig.FunctionalOptions(&conf, mapping)
+	require.Equal(t, "envconfig: no field Redis.Port", err.Error())
+
+	delete(mapping, "Redis.Port")
+	mapping["Name"] = envconfig.Option(withTLS)
+	conf.Name = "foo"
+	_, err = envconfig.FunctionalOptions(&conf, mapping)
+	require.Equal(t, "envconfig: unable to build the option of Name: expected a bool, got a string", err.Error())
+}
@@ -0,0 +1,108 @@
+package envconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Globs is a list of glob patterns compiled into a single matcher, for the allow and deny lists of hosts or paths.
+//
+// It is parsed from a comma-separated list of patterns like this:
+//
+//	*.internal,10.0.*,/api/**
+//
+// In a pattern, * matches any sequence of characters except /, ** any sequence of characters, ? a single
+// character except / and [...] a character class like [a-z], negated with [!a-z]. The other characters
+// are matched literally.
+//
+// The zero value is an empty list, which matches nothing.
+type Globs struct {
+	Patterns []string
+	re       *regexp.Regexp
+}
+
+// DocValue implements DocValuer.
+func (Globs) DocValue() string {
+	return "*.internal,10.0.*"
+}
+
+// Unmarshal implements Unmarshaler. The spaces around the patterns and the empty patterns are ignored.
+func (g *Globs) Unmarshal(s string) error {
+	var (
+		patterns []string
+		exprs    []string
+	)
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		expr, err := globExpr(p)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, p)
+		exprs = append(exprs, expr)
+	}
+
+	*g = Globs{Patterns: patterns}
+	if len(exprs) > 0 {
+		g.re = regexp.MustCompile("^(?:" + strings.Join(exprs, "|") + ")$")
+	}
+
+	return nil
+}
+
+// Match returns true if s matches one of the patterns.
+func (g Globs) Match(s string) bool {
+	return g.re != nil && g.re.MatchString(s)
+}
+
+// String returns the patterns separated by commas.
+func (g Globs) String() string {
+	return strings.Join(g.Patterns, ",")
+}
+
+// globExpr returns the regular expression of the glob pattern.
+func globExpr(pattern string) (string, error) {
+	var buf strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				buf.WriteString(".*")
+				i++
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("invalid glob %q, unterminated character class", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			negated := strings.HasPrefix(class, "!")
+			class = strings.TrimPrefix(class, "!")
+			if class == "" {
+				return "", fmt.Errorf("invalid glob %q, empty character class", pattern)
+			}
+			buf.WriteByte('[')
+			if negated {
+				buf.WriteByte('^')
+			}
+			buf.WriteString(strings.NewReplacer(`\`, `\\`, "[", `\[`, "^", `\^`).Replace(class))
+			buf.WriteByte(']')
+			i += end + 1
+		default:
+			buf.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	if _, err := regexp.Compile(buf.String()); err != nil {
+		return "", fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return buf.String(), nil
+}
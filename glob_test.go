@@ -0,0 +1,56 @@
+package envconfig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestGlobs(t *testing.T) {
+	var conf struct {
+		AllowedHosts envconfig.Globs
+		DeniedPaths  envconfig.Globs `envconfig:"optional"`
+	}
+
+	source := envconfig.MapSource{"ALLOWED_HOSTS": "*.internal, 10.0.*,,db-[0-9]"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, []string{"*.internal", "10.0.*", "db-[0-9]"}, conf.AllowedHosts.Patterns)
+	require.Equal(t, "*.internal,10.0.*,db-[0-9]", conf.AllowedHosts.String())
+
+	for _, host := range []string{"api.internal", "10.0.1.2", "db-1"} {
+		require.True(t, conf.AllowedHosts.Match(host), host)
+	}
+	for _, host := range []string{"internal", "api.internal.example.com", "10.1.0.1", "db-a", "db-10"} {
+		require.False(t, conf.AllowedHosts.Match(host), host)
+	}
+	require.False(t, conf.DeniedPaths.Match(""))
+
+	values, err := envconfig.Marshal(conf)
+	require.Nil(t, err)
+	require.Equal(t, "*.internal,10.0.*,db-[0-9]", values["ALLOWEDHOSTS"])
+
+	var paths envconfig.Globs
+	require.Nil(t, paths.Unmarshal("/api/*,/static/**,/v?/health,/[!_]*.txt"))
+	for _, path := range []string{"/api/users", "/static/css/app.css", "/v1/health", "/robots.txt"} {
+		require.True(t, paths.Match(path), path)
+	}
+	for _, path := range []string{"/api/users/1", "/v10/health", "/_secret.txt", "/static"} {
+		require.False(t, paths.Match(path), path)
+	}
+
+	testCases := []struct {
+		in  string
+		err string
+	}{
+		{"db-[0-9", `invalid glob "db-[0-9", unterminated character class`},
+		{"db-[!]", `invalid glob "db-[!]", empty character class`},
+		{"db-[9-0]", `invalid glob "db-[9-0]": error parsing regexp: invalid character class range: ` + "`9-0`"},
+	}
+	for _, tc := range testCases {
+		err := paths.Unmarshal(tc.in)
+		require.NotNil(t, err)
+		require.Equal(t, tc.err, err.Error())
+	}
+}
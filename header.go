@@ -0,0 +1,34 @@
+package envconfig
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var httpHeaderType = reflect.TypeOf(http.Header(nil))
+
+// parseHeader parses a comma-separated list of Key:value pairs into an http.Header.
+// Keys are canonicalized and repeated keys add values.
+func parseHeader(v reflect.Value, str string) error {
+	h := make(http.Header)
+
+	for _, token := range strings.Split(str, ",") {
+		i := strings.IndexByte(token, ':')
+		if i < 0 {
+			return fmt.Errorf("invalid header %q, expected Key:value", token)
+		}
+
+		key := strings.TrimSpace(token[:i])
+		if key == "" {
+			return fmt.Errorf("invalid header %q, empty key", token)
+		}
+
+		h.Add(key, strings.TrimSpace(token[i+1:]))
+	}
+
+	v.Set(reflect.ValueOf(h))
+
+	return nil
+}
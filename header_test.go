@@ -0,0 +1,33 @@
+package envconfig_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestHTTPHeader(t *testing.T) {
+	var conf struct {
+		Headers http.Header
+	}
+
+	os.Setenv("HEADERS", "x-api-key:foobar, accept: text/html ,Accept:application/json,X-Forwarded-For:10.0.0.1:80")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, http.Header{
+		"X-Api-Key":       {"foobar"},
+		"Accept":          {"text/html", "application/json"},
+		"X-Forwarded-For": {"10.0.0.1:80"},
+	}, conf.Headers)
+
+	os.Setenv("HEADERS", "X-Api-Key")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse HEADERS (field Headers): invalid header "X-Api-Key", expected Key:value`, err.Error())
+
+	os.Setenv("HEADERS", "")
+}
@@ -0,0 +1,224 @@
+// Package httpjson provides an envconfig source reading the values from a JSON document served over HTTP,
+// like the one of an in-house configuration service.
+//
+// The document is flattened into keys: nested objects are joined with underscores and the names are
+// upper cased, so that the document
+//
+//	{"name": "foo", "mysql": {"master": {"address": "db:3306"}}, "tags": ["a", "b"]}
+//
+// provides the keys NAME, MYSQL_MASTER_ADDRESS and TAGS, the arrays being comma-separated lists.
+// The keys are then resolved with the usual naming rules:
+//
+//	source, err := httpjson.New(httpjson.Config{
+//		URL:    "https://config.internal/myapp.json",
+//		Header: http.Header{"Authorization": {"Bearer " + token}},
+//	})
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+package httpjson
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config is the configuration of a Source.
+type Config struct {
+	// URL is the URL of the JSON document.
+	URL string
+	// Header is added to the request, for example to authenticate with an Authorization header.
+	Header http.Header
+
+	// HTTPClient is the client used to fetch the document, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// Source reads the values from a JSON document. It implements envconfig.Source, envconfig.ContextSource
+// and envconfig.Lister.
+type Source struct {
+	cfg Config
+
+	mu sync.Mutex
+	// values are the values of the document by key, nil until it is fetched.
+	values map[string]string
+}
+
+// New returns a new source. The document is fetched on the first lookup.
+func New(cfg Config) (*Source, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("httpjson: no URL")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Source{cfg: cfg}, nil
+}
+
+// String returns the name of the source used in errors.
+func (s *Source) String() string {
+	return "httpjson " + s.cfg.URL
+}
+
+// Lookup implements envconfig.Source.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, "")
+}
+
+// LookupContext implements envconfig.ContextSource, the document is fetched with ctx if it is not yet.
+func (s *Source) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	values, err := s.load(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	v, ok := values[strings.ToUpper(key)]
+	return v, ok, nil
+}
+
+// Keys implements envconfig.Lister.
+func (s *Source) Keys() []string {
+	values, err := s.load(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Refresh fetches the document again.
+func (s *Source) Refresh() error {
+	return s.refresh(context.Background())
+}
+
+func (s *Source) refresh(ctx context.Context) error {
+	values, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	return nil
+}
+
+// load returns the values, fetching the document if needed.
+func (s *Source) load(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	values := s.values
+	s.mu.Unlock()
+
+	if values != nil {
+		return values, nil
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values, nil
+}
+
+// fetch fetches and flattens the document.
+func (s *Source) fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range s.cfg.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("httpjson: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return nil, fmt.Errorf("httpjson: GET %s: %s", s.cfg.URL, resp.Status)
+	}
+
+	var doc map[string]interface{}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("httpjson: invalid document: %w", err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten(values, "", doc); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// flatten adds the values of the object obj to values, with their keys prefixed by prefix.
+func flatten(values map[string]string, prefix string, obj map[string]interface{}) error {
+	for name, v := range obj {
+		key := strings.ToUpper(name)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if err := flatten(values, key, v); err != nil {
+				return err
+			}
+		case []interface{}:
+			elems := make([]string, len(v))
+			for i, el := range v {
+				str, ok := scalar(el)
+				if !ok {
+					return fmt.Errorf("httpjson: invalid value of %s, arrays must contain scalars", key)
+				}
+				elems[i] = str
+			}
+			values[key] = strings.Join(elems, ",")
+		case nil:
+		default:
+			values[key], _ = scalar(v)
+		}
+	}
+
+	return nil
+}
+
+// scalar returns the string form of a JSON string, number or boolean.
+func scalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		if v {
+			return "true", true
+		}
+		return "false", true
+	default:
+		return "", false
+	}
+}
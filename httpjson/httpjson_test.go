@@ -0,0 +1,61 @@
+package httpjson_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/httpjson"
+)
+
+func TestSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{
+			"name": "foo",
+			"port": 8080,
+			"debug": true,
+			"mysql": {"master": {"address": "db:3306"}},
+			"sslCert": "cert",
+			"tags": ["a", "b"]
+		}`))
+	}))
+	defer srv.Close()
+
+	source, err := httpjson.New(httpjson.Config{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer token"}}})
+	require.Nil(t, err)
+
+	var conf struct {
+		Name  string
+		Port  int
+		Debug bool
+		MySQL struct {
+			Master struct {
+				Address string
+			}
+		}
+		SSLCert string
+		Tags    []string
+	}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, 8080, conf.Port)
+	require.True(t, conf.Debug)
+	require.Equal(t, "db:3306", conf.MySQL.Master.Address)
+	require.Equal(t, "cert", conf.SSLCert)
+	require.Equal(t, []string{"a", "b"}, conf.Tags)
+	require.Equal(t, []string{"DEBUG", "MYSQL_MASTER_ADDRESS", "NAME", "PORT", "SSLCERT", "TAGS"}, source.Keys())
+
+	source, err = httpjson.New(httpjson.Config{URL: srv.URL})
+	require.Nil(t, err)
+
+	_, _, err = source.Lookup("NAME")
+	require.Equal(t, "httpjson: GET "+srv.URL+": 401 Unauthorized", err.Error())
+}
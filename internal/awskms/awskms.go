@@ -0,0 +1,120 @@
+// Package awskms calls the Decrypt API of AWS KMS, for the subpackages which don't depend on the AWS SDK.
+package awskms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vrischmann/envconfig/internal/sigv4"
+)
+
+// Client calls the API of KMS.
+type Client struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	endpoint        string
+	httpClient      *http.Client
+}
+
+// New returns a new client. The credentials are AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// if accessKeyID is empty, the endpoint is the one of the region of each request if it is empty.
+func New(accessKeyID, secretAccessKey, sessionToken, endpoint string, httpClient *http.Client) (*Client, error) {
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New("no credentials, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		endpoint:        endpoint,
+		httpClient:      httpClient,
+	}, nil
+}
+
+// Region returns the region of the ARN of a key, or an empty string if keyID is not an ARN.
+func Region(keyID string) string {
+	// arn:aws:kms:REGION:ACCOUNT:key/ID
+	parts := strings.Split(keyID, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[2] != "kms" {
+		return ""
+	}
+	return parts[3]
+}
+
+// Decrypt calls the Decrypt API in the region and returns the plaintext of the ciphertext.
+// keyID and encryptionContext are optional.
+func (c *Client) Decrypt(ctx context.Context, region, keyID string, ciphertext []byte, encryptionContext map[string]string) ([]byte, error) {
+	endpoint := c.endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + region + ".amazonaws.com"
+	}
+
+	body, err := json.Marshal(struct {
+		CiphertextBlob    []byte            `json:"CiphertextBlob"`
+		EncryptionContext map[string]string `json:"EncryptionContext,omitempty"`
+		KeyID             string            `json:"KeyId,omitempty"`
+	}{ciphertext, encryptionContext, keyID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	if c.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.sessionToken)
+	}
+	sigv4.Sign(req, body, c.accessKeyID, c.secretAccessKey, region, "kms", time.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Type == "" {
+			return nil, fmt.Errorf("Decrypt: %s", resp.Status)
+		}
+		return nil, fmt.Errorf("Decrypt: %s: %s", apiErr.Type[strings.LastIndexByte(apiErr.Type, '#')+1:], apiErr.Message)
+	}
+
+	var res struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return res.Plaintext, nil
+}
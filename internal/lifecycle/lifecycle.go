@@ -0,0 +1,107 @@
+// Package lifecycle tracks the background loops of the stores and sources of envconfig so that they can be shut down.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShutdown is the error returned by the loops started after a shutdown.
+var ErrShutdown = errors.New("envconfig: shut down")
+
+// Lifecycle tracks loops, the zero value is ready to use.
+type Lifecycle struct {
+	mu      sync.Mutex
+	stopped bool
+	stop    chan struct{}
+	loops   sync.WaitGroup
+	// err is the error which stopped the last loop, until the loops are healthy again.
+	err error
+}
+
+// Start registers a loop. The loop must use the returned context, which is canceled when ctx is done
+// or on shutdown, and call release when it returns. It returns ErrShutdown after shutdown.
+func (l *Lifecycle) Start(ctx context.Context) (context.Context, func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.stopped {
+		return nil, nil, ErrShutdown
+	}
+	if l.stop == nil {
+		l.stop = make(chan struct{})
+	}
+	l.loops.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func(stop chan struct{}) {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}(l.stop)
+
+	return ctx, func() {
+		cancel()
+		l.loops.Done()
+	}, nil
+}
+
+// Fail records the error which stopped a loop, returned by Err until Recover is called.
+func (l *Lifecycle) Fail(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.err = err
+}
+
+// Recover clears the error recorded by Fail, once the loops work again.
+func (l *Lifecycle) Recover() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.err = nil
+}
+
+// Err returns the error recorded by Fail, nil if the loops are healthy.
+func (l *Lifecycle) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.err
+}
+
+// Stopped returns true once Shutdown is called.
+func (l *Lifecycle) Stopped() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stopped
+}
+
+// Shutdown cancels the contexts of the loops and waits for them to return, or for ctx to be done.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	if !l.stopped {
+		l.stopped = true
+		if l.stop != nil {
+			close(l.stop)
+		}
+	}
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,117 @@
+package envconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isoCountryCodes are the ISO 3166-1 alpha-2 country codes.
+var isoCountryCodes = makeCodeSet(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+	BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+	CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+	DE DJ DK DM DO DZ
+	EC EE EG EH ER ES ET
+	FI FJ FK FM FO FR
+	GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+	HK HM HN HR HT HU
+	ID IE IL IM IN IO IQ IR IS IT
+	JE JM JO JP
+	KE KG KH KI KM KN KP KR KW KY KZ
+	LA LB LC LI LK LR LS LT LU LV LY
+	MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+	NA NC NE NF NG NI NL NO NP NR NU NZ
+	OM
+	PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+	QA
+	RE RO RS RU RW
+	SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+	TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+	UA UG UM US UY UZ
+	VA VC VE VG VI VN VU
+	WF WS
+	YE YT
+	ZA ZM ZW
+`)
+
+// isoCurrencyCodes are the active ISO 4217 currency codes, including funds and precious metals.
+var isoCurrencyCodes = makeCodeSet(`
+	AED AFN ALL AMD AOA ARS AUD AWG AZN
+	BAM BBD BDT BGN BHD BIF BMD BND BOB BOV BRL BSD BTN BWP BYN BZD
+	CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK
+	DJF DKK DOP DZD
+	EGP ERN ETB EUR
+	FJD FKP
+	GBP GEL GHS GIP GMD GNF GTQ GYD
+	HKD HNL HTG HUF
+	IDR ILS INR IQD IRR ISK
+	JMD JOD JPY
+	KES KGS KHR KMF KPW KRW KWD KYD KZT
+	LAK LBP LKR LRD LSL LYD
+	MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN
+	NAD NGN NIO NOK NPR NZD
+	OMR
+	PAB PEN PGK PHP PKR PLN PYG
+	QAR
+	RON RSD RUB RWF
+	SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL
+	THB TJS TMT TND TOP TRY TTD TWD TZS
+	UAH UGX USD USN UYI UYU UYW UZS
+	VED VES VND VUV
+	WST
+	XAF XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF XPD XPF XPT XSU XTS XUA XXX
+	YER
+	ZAR ZMW ZWG
+`)
+
+func makeCodeSet(s string) map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, code := range strings.Fields(s) {
+		res[code] = struct{}{}
+	}
+	return res
+}
+
+func validateCountryCode(s string) (string, error) {
+	code := strings.ToUpper(s)
+	if _, ok := isoCountryCodes[code]; !ok {
+		return "", fmt.Errorf("%q is not an ISO 3166-1 alpha-2 country code", s)
+	}
+	return code, nil
+}
+
+func validateCurrencyCode(s string) (string, error) {
+	code := strings.ToUpper(s)
+	if _, ok := isoCurrencyCodes[code]; !ok {
+		return "", fmt.Errorf("%q is not an ISO 4217 currency code", s)
+	}
+	return code, nil
+}
+
+// CountryCode is an ISO 3166-1 alpha-2 country code, like FR or US.
+// It is case insensitive and always stored in upper case.
+type CountryCode string
+
+// Unmarshal implements Unmarshaler.
+func (c *CountryCode) Unmarshal(s string) error {
+	code, err := validateCountryCode(s)
+	if err != nil {
+		return err
+	}
+	*c = CountryCode(code)
+	return nil
+}
+
+// CurrencyCode is an ISO 4217 currency code, like EUR or USD.
+// It is case insensitive and always stored in upper case.
+type CurrencyCode string
+
+// Unmarshal implements Unmarshaler.
+func (c *CurrencyCode) Unmarshal(s string) error {
+	code, err := validateCurrencyCode(s)
+	if err != nil {
+		return err
+	}
+	*c = CurrencyCode(code)
+	return nil
+}
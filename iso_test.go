@@ -0,0 +1,42 @@
+package envconfig_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestISOCodes(t *testing.T) {
+	var conf struct {
+		Country    envconfig.CountryCode
+		Currency   envconfig.CurrencyCode
+		Countries  []string `envconfig:",iso3166"`
+		Settlement string   `envconfig:",iso4217"`
+	}
+
+	os.Setenv("COUNTRY", "fr")
+	os.Setenv("CURRENCY", "EUR")
+	os.Setenv("COUNTRIES", "US,gb")
+	os.Setenv("SETTLEMENT", "chf")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, envconfig.CountryCode("FR"), conf.Country)
+	require.Equal(t, envconfig.CurrencyCode("EUR"), conf.Currency)
+	require.Equal(t, []string{"US", "GB"}, conf.Countries)
+	require.Equal(t, "CHF", conf.Settlement)
+
+	os.Setenv("COUNTRY", "EU")
+	os.Setenv("SETTLEMENT", "FRF")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse COUNTRY (field Country): "EU" is not an ISO 3166-1 alpha-2 country code
+envconfig: unable to parse SETTLEMENT (field Settlement): "FRF" is not an ISO 4217 currency code`, err.Error())
+
+	os.Setenv("COUNTRY", "")
+	os.Setenv("CURRENCY", "")
+	os.Setenv("COUNTRIES", "")
+	os.Setenv("SETTLEMENT", "")
+}
@@ -0,0 +1,172 @@
+package envconfig
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// KeyFor returns the keys looked up by Init for a field, given the path of the field:
+// the names of its parent fields and its own name, like KeyFor("MySQL", "Address").
+//
+// The first key is the canonical one, used by Usage and Marshal, the others follow in a stable order.
+// Custom names set with a tag are not taken into account.
+func KeyFor(path ...string) []string {
+	return KeyForWithOptions(Options{}, path...)
+}
+
+// KeyForWithOptions returns the keys looked up by InitWithOptions with opts for a field, given the path of the field.
+// Only the options Prefix, SnakeCase, Acronyms, StrictCase and KeyNames are used.
+func KeyForWithOptions(opts Options, path ...string) []string {
+	ctx := &fieldContext{name: opts.Prefix, state: &state{opts: opts}}
+	for _, name := range path {
+		ctx.name = combineName(ctx.name, name)
+	}
+	if strings.Trim(ctx.name, ".") == "" {
+		return nil
+	}
+
+	canonical := canonicalKey(ctx)
+
+	res := []string{canonical}
+	for _, key := range makeAllPossibleKeys(ctx) {
+		if key != canonical {
+			res = append(res, key)
+		}
+	}
+
+	return res
+}
+
+// checkKeyNames returns a *KeyNameError for each field with a key which isn't a portable name of environment
+// variable if names is true, made of ASCII letters, digits and underscores and not starting with a digit,
+// or which is longer than maxLength if it is positive. The canonical key is checked first, a single key
+// is reported by field.
+func checkKeyNames(fields []fieldInfo, names bool, maxLength int) (errs []error) {
+	for _, f := range fields {
+		if f.rest {
+			continue
+		}
+		for _, key := range append([]string{f.key}, f.keys...) {
+			if reason := keyNameProblem(key, names, maxLength); reason != "" {
+				errs = append(errs, &KeyNameError{Field: f.path, Key: key, Reason: reason})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// keyNameProblem returns why the key isn't a valid name of environment variable, or nothing if it is.
+// Only its length is checked unless names is true.
+func keyNameProblem(key string, names bool, maxLength int) string {
+	if names {
+		if key == "" {
+			return "it is empty"
+		}
+		if key[0] >= '0' && key[0] <= '9' {
+			return "it starts with a digit"
+		}
+		for _, r := range key {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+				return fmt.Sprintf("it contains the character %q", r)
+			}
+		}
+	}
+	if maxLength > 0 && len(key) > maxLength {
+		return fmt.Sprintf("it is longer than %d characters", maxLength)
+	}
+	return ""
+}
+
+// acronyms are the acronyms kept as single words by snakeKey, along with Options.Acronyms.
+var acronyms = []string{"API", "AWS", "DB", "DNS", "GCP", "GRPC", "HTTP", "HTTPS", "ID", "IP", "JSON", "JWT", "SQL", "SSH", "SSL", "TCP", "TLS", "UDP", "URI", "URL", "UUID"}
+
+// snakeKey returns the name, made of names separated by dots, in upper case with an underscore between its words:
+// a new word starts at an upper case letter following a lower case letter or a digit, or ending a sequence of upper
+// case letters followed by a lower case one, like in HTTPServer, unless the sequence is an acronym like in SSLmode.
+// At the start of a word, the longest acronym of the built-in ones and extra, optionally in the plural like IDs,
+// is a word if it ends at a word boundary.
+func snakeKey(name string, extra []string) string {
+	var words []string
+	for _, part := range strings.Split(name, ".") {
+		words = append(words, splitWords([]rune(part), extra)...)
+	}
+	return strings.ToUpper(strings.Join(words, "_"))
+}
+
+// splitWords returns the words of the name, see snakeKey.
+func splitWords(name []rune, extra []string) (words []string) {
+	for start := 0; start < len(name); {
+		if n := acronymAt(name[start:], extra); n > 0 {
+			words = append(words, string(name[start:start+n]))
+			start += n
+			continue
+		}
+
+		end := start + 1
+		for ; end < len(name); end++ {
+			r, prev := name[end], name[end-1]
+			if !unicode.IsUpper(r) {
+				continue
+			}
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+				break
+			}
+			if end-1 > start && unicode.IsUpper(prev) && end+1 < len(name) && unicode.IsLower(name[end+1]) &&
+				!isAcronym(string(name[start:end+1]), extra) {
+				break
+			}
+		}
+		words = append(words, string(name[start:end]))
+		start = end
+	}
+	return words
+}
+
+// acronymAt returns the length of the longest acronym starting s, with a final s if it is in the plural,
+// 0 if there is none. The acronym must end at a word boundary, see wordBoundary.
+func acronymAt(s []rune, extra []string) int {
+	best := 0
+	for _, list := range [][]string{acronyms, extra} {
+		for _, a := range list {
+			n := len([]rune(a))
+			if n <= best || n > len(s) || string(s[:n]) != a {
+				continue
+			}
+			if n < len(s) && s[n] == 's' && (n+1 == len(s) || !unicode.IsLower(s[n+1])) {
+				n++
+			}
+			if wordBoundary(s[n:], extra) {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
+// wordBoundary returns true if a word ends before s: at the end of the name, before a character which isn't
+// a letter, like a digit, or before a capitalized word or an acronym.
+func wordBoundary(s []rune, extra []string) bool {
+	switch {
+	case len(s) == 0 || !unicode.IsLetter(s[0]):
+		return true
+	case !unicode.IsUpper(s[0]):
+		return false
+	case len(s) > 1 && unicode.IsLower(s[1]):
+		return true
+	}
+	return acronymAt(s, extra) > 0
+}
+
+// isAcronym returns true if word is one of the built-in acronyms or extra.
+func isAcronym(word string, extra []string) bool {
+	for _, list := range [][]string{acronyms, extra} {
+		for _, a := range list {
+			if a == word {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package envconfig
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -8,7 +9,7 @@ import (
 
 func TestMakeAllPossibleKeys(t *testing.T) {
 	fieldName := "CassandraSslCert"
-	keys := makeAllPossibleKeys(&context{
+	keys := makeAllPossibleKeys(&fieldContext{
 		name: fieldName,
 	})
 
@@ -19,7 +20,7 @@ func TestMakeAllPossibleKeys(t *testing.T) {
 	require.Equal(t, "cassandrasslcert", keys[3])
 
 	fieldName = "CassandraSSLCert"
-	keys = makeAllPossibleKeys(&context{
+	keys = makeAllPossibleKeys(&fieldContext{
 		name: fieldName,
 	})
 
@@ -30,7 +31,7 @@ func TestMakeAllPossibleKeys(t *testing.T) {
 	require.Equal(t, "cassandrasslcert", keys[3])
 
 	fieldName = "Cassandra.SslCert"
-	keys = makeAllPossibleKeys(&context{
+	keys = makeAllPossibleKeys(&fieldContext{
 		name: fieldName,
 	})
 
@@ -41,7 +42,7 @@ func TestMakeAllPossibleKeys(t *testing.T) {
 	require.Equal(t, "cassandra_sslcert", keys[3])
 
 	fieldName = "Cassandra.SSLCert"
-	keys = makeAllPossibleKeys(&context{
+	keys = makeAllPossibleKeys(&fieldContext{
 		name: fieldName,
 	})
 
@@ -52,7 +53,7 @@ func TestMakeAllPossibleKeys(t *testing.T) {
 	require.Equal(t, "cassandra_sslcert", keys[3])
 
 	fieldName = "Name"
-	keys = makeAllPossibleKeys(&context{
+	keys = makeAllPossibleKeys(&fieldContext{
 		name: fieldName,
 	})
 
@@ -60,3 +61,54 @@ func TestMakeAllPossibleKeys(t *testing.T) {
 	require.Equal(t, "NAME", keys[0])
 	require.Equal(t, "name", keys[1])
 }
+
+func TestKeyFor(t *testing.T) {
+	require.Equal(t, []string{"MYSQL_ADDRESS", "MY_SQL_ADDRESS", "my_sql_address", "mysql_address"}, KeyFor("MySQL", "Address"))
+	require.Equal(t, []string{"APP_NAME", "app_name"}, KeyForWithOptions(Options{Prefix: "APP"}, "Name"))
+	require.Equal(t, []string{"APP", "app"}, KeyForWithOptions(Options{Prefix: "APP"}))
+	require.Nil(t, KeyFor())
+
+	require.Equal(t, []string{"APP_HTTP_SERVER", "APP_HTTPSERVER", "app_http_server", "app_httpserver"}, KeyForWithOptions(Options{Prefix: "APP", SnakeCase: true}, "HTTPServer"))
+
+	opts := Options{Prefix: "APP", KeyNames: func(path []string) []string {
+		return []string{strings.ToUpper(strings.Join(path, "__"))}
+	}}
+	require.Equal(t, []string{"APP__MYSQL__ADDRESS"}, KeyForWithOptions(opts, "MySQL", "Address"))
+}
+
+func TestSnakeKey(t *testing.T) {
+	testCases := []struct {
+		name  string
+		extra []string
+		key   string
+	}{
+		{"MaxRetries", nil, "MAX_RETRIES"},
+		{"APP.HTTPServer.ReadTimeout", nil, "APP_HTTP_SERVER_READ_TIMEOUT"},
+		{"APIKey", nil, "API_KEY"},
+		{"UserIDs", nil, "USER_IDS"},
+		{"HTTPSProxy", nil, "HTTPS_PROXY"},
+		{"IPv6Addr", nil, "IPV6_ADDR"},
+		{"S3Bucket", nil, "S3_BUCKET"},
+		{"VALID", nil, "VALID"},
+		{"GRPCAPIKey", nil, "GRPC_API_KEY"},
+		{"OIDCIssuerURL", nil, "OIDC_ISSUER_URL"},
+		{"OIDCAPIKey", nil, "OIDCAPI_KEY"},
+		{"OIDCAPIKey", []string{"OIDC"}, "OIDC_API_KEY"},
+		{"MY_APP.Name", nil, "MY_APP_NAME"},
+		{"IDLETimeout", nil, "IDLE_TIMEOUT"},
+		{"MaxIDLEConns", nil, "MAX_IDLE_CONNS"},
+		{"IPCTimeout", nil, "IPC_TIMEOUT"},
+		{"DBSName", nil, "DBS_NAME"},
+		{"IDPURL", nil, "IDPURL"},
+		{"SSLmode", nil, "SSLMODE"},
+		{"DBs", nil, "DBS"},
+		{"TLSCert2", nil, "TLS_CERT2"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.key, snakeKey(tc.name, tc.extra), tc.name)
+	}
+
+	opts := Options{Prefix: "APP", SnakeCase: true, Acronyms: []string{"OIDC"}}
+	require.Equal(t, "APP_OIDC_CLIENT_ID", KeyForWithOptions(opts, "OIDCClientID")[0])
+}
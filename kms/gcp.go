@@ -0,0 +1,176 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GCPConfig is the configuration of a GCP decryptor.
+type GCPConfig struct {
+	// KeyName is the resource name of the key, like projects/P/locations/L/keyRings/R/cryptoKeys/K.
+	KeyName string
+
+	// Token returns the OAuth2 access token authenticating the requests. By default the token of the service account
+	// of the instance is read from the metadata server, which works on Compute Engine, GKE and Cloud Run.
+	// The service account key files aren't supported: get the token with the Google libraries and return it.
+	Token func(ctx context.Context) (string, error)
+
+	// Endpoint is the URL of the API of Cloud KMS, https://cloudkms.googleapis.com by default.
+	Endpoint string
+	// MetadataEndpoint is the URL of the metadata server, http://$GCE_METADATA_HOST or
+	// http://metadata.google.internal by default.
+	MetadataEndpoint string
+
+	// HTTPClient is the client used to talk to Cloud KMS and the metadata server, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// GCP decrypts the values with GCP Cloud KMS. It implements envconfig.Decryptor.
+type GCP struct {
+	cfg GCPConfig
+
+	// mu protects the token read from the metadata server.
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewGCP returns a new GCP decryptor.
+func NewGCP(cfg GCPConfig) (*GCP, error) {
+	if cfg.KeyName == "" {
+		return nil, errors.New("kms: no key name")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://cloudkms.googleapis.com"
+	}
+	if cfg.MetadataEndpoint == "" {
+		cfg.MetadataEndpoint = "http://metadata.google.internal"
+		if host := os.Getenv("GCE_METADATA_HOST"); host != "" {
+			cfg.MetadataEndpoint = "http://" + host
+		}
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	g := &GCP{cfg: cfg}
+	if g.cfg.Token == nil {
+		g.cfg.Token = g.metadataToken
+	}
+
+	return g, nil
+}
+
+// Decrypt implements envconfig.Decryptor, the ciphertext is encoded in base64.
+func (g *GCP) Decrypt(ctx context.Context, key, ciphertext string) (string, error) {
+	blob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ciphertext))
+	if err != nil {
+		return "", fmt.Errorf("kms: invalid ciphertext: %w", err)
+	}
+
+	token, err := g.cfg.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("kms: unable to get a token: %w", err)
+	}
+
+	body, err := json.Marshal(map[string][]byte{"ciphertext": blob})
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSuffix(g.cfg.Endpoint, "/") + "/v1/" + g.cfg.KeyName + ":decrypt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var res struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := g.do(req, &res); err != nil {
+		return "", fmt.Errorf("kms: decrypt %s: %w", g.cfg.KeyName, err)
+	}
+
+	return string(res.Plaintext), nil
+}
+
+// metadataToken returns the token of the service account of the instance, cached until it expires.
+func (g *GCP) metadataToken(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	token, expires := g.token, g.expires
+	g.mu.Unlock()
+
+	if token != "" && time.Now().Before(expires) {
+		return token, nil
+	}
+
+	url := strings.TrimSuffix(g.cfg.MetadataEndpoint, "/") + "/computeMetadata/v1/instance/service-accounts/default/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var res struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := g.do(req, &res); err != nil {
+		return "", fmt.Errorf("metadata server: %w", err)
+	}
+	if res.AccessToken == "" {
+		return "", errors.New("metadata server: no token")
+	}
+
+	g.mu.Lock()
+	g.token = res.AccessToken
+	// the token is renewed a minute before it expires
+	g.expires = time.Now().Add(time.Duration(res.ExpiresIn)*time.Second - time.Minute)
+	g.mu.Unlock()
+
+	return res.AccessToken, nil
+}
+
+// do sends the request and decodes the JSON response into v.
+func (g *GCP) do(req *http.Request, v interface{}) error {
+	resp, err := g.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Error.Message == "" {
+			return errors.New(resp.Status)
+		}
+		return fmt.Errorf("%s: %s", apiErr.Error.Status, apiErr.Error.Message)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package kms provides envconfig decryptors for the values encrypted with AWS KMS or GCP Cloud KMS,
+// so that the fields with the encrypted option hold the base64 ciphertexts of a KMS key:
+//
+//	decryptor, err := kms.NewAWS(kms.AWSConfig{})
+//	...
+//	var conf struct {
+//		DBPassword string `envconfig:",encrypted"`
+//	}
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Decryptor: decryptor})
+//
+// The ciphertexts are the outputs of aws kms encrypt and gcloud kms encrypt encoded in base64.
+// The decryptors only talk to the HTTP APIs of the KMS, they don't depend on the SDKs.
+package kms
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/vrischmann/envconfig/internal/awskms"
+)
+
+// AWSConfig is the configuration of an AWS decryptor.
+type AWSConfig struct {
+	// Region is the AWS region, the region of KeyID if it is an ARN, otherwise AWS_REGION or AWS_DEFAULT_REGION
+	// by default.
+	Region string
+	// KeyID is the key which must have encrypted the values, any key by default.
+	KeyID string
+	// EncryptionContext is the encryption context of the values, if any.
+	EncryptionContext map[string]string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials, AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN by default. The other providers of credentials,
+	// like the ECS container credentials or the EC2 instance profiles, aren't supported.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint is the URL of the API of KMS, https://kms.REGION.amazonaws.com by default.
+	Endpoint string
+
+	// HTTPClient is the client used to talk to KMS, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// AWS decrypts the values with AWS KMS. It implements envconfig.Decryptor.
+type AWS struct {
+	cfg    AWSConfig
+	client *awskms.Client
+}
+
+// NewAWS returns a new AWS decryptor.
+func NewAWS(cfg AWSConfig) (*AWS, error) {
+	if cfg.Region == "" {
+		cfg.Region = awskms.Region(cfg.KeyID)
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("kms: no region")
+	}
+
+	client, err := awskms.New(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken, cfg.Endpoint, cfg.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("kms: %w", err)
+	}
+
+	return &AWS{cfg: cfg, client: client}, nil
+}
+
+// Decrypt implements envconfig.Decryptor, the ciphertext is encoded in base64.
+func (a *AWS) Decrypt(ctx context.Context, key, ciphertext string) (string, error) {
+	blob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ciphertext))
+	if err != nil {
+		return "", fmt.Errorf("kms: invalid ciphertext: %w", err)
+	}
+
+	plaintext, err := a.client.Decrypt(ctx, a.cfg.Region, a.cfg.KeyID, blob, a.cfg.EncryptionContext)
+	if err != nil {
+		return "", fmt.Errorf("kms: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,138 @@
+package kms_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/kms"
+)
+
+var ciphertext = base64.StdEncoding.EncodeToString([]byte("ciphertext"))
+
+func TestAWS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+		require.Contains(t, r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/")
+		require.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request")
+
+		var req struct {
+			CiphertextBlob    []byte
+			EncryptionContext map[string]string
+			KeyID             string `json:"KeyId"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "arn:aws:kms:eu-west-1:123456789012:key/1234abcd", req.KeyID)
+		require.Equal(t, map[string]string{"app": "myapp"}, req.EncryptionContext)
+
+		if string(req.CiphertextBlob) != "ciphertext" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "InvalidCiphertextException", "message": "invalid ciphertext"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": []byte("secret")})
+	}))
+	defer srv.Close()
+
+	decryptor, err := kms.NewAWS(kms.AWSConfig{
+		KeyID:             "arn:aws:kms:eu-west-1:123456789012:key/1234abcd",
+		EncryptionContext: map[string]string{"app": "myapp"},
+		AccessKeyID:       "AKID",
+		SecretAccessKey:   "secret",
+		Endpoint:          srv.URL,
+	})
+	require.Nil(t, err)
+
+	var conf struct {
+		Password string `envconfig:",encrypted"`
+	}
+
+	source := envconfig.MapSource{"PASSWORD": ciphertext}
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: decryptor})
+	require.Nil(t, err)
+	require.Equal(t, "secret", conf.Password)
+
+	source["PASSWORD"] = base64.StdEncoding.EncodeToString([]byte("invalid"))
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: decryptor})
+	require.Equal(t, "envconfig: unable to decrypt PASSWORD (field Password): kms: Decrypt: InvalidCiphertextException: invalid ciphertext", err.Error())
+
+	source["PASSWORD"] = "not base64"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: decryptor})
+	require.Contains(t, err.Error(), "kms: invalid ciphertext")
+}
+
+func TestGCP(t *testing.T) {
+	var tokens int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/computeMetadata/v1/instance/service-accounts/default/token", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		tokens++
+		w.Write([]byte(`{"access_token": "token", "expires_in": 3600, "token_type": "Bearer"}`))
+	})
+	mux.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/projects/p/locations/global/keyRings/r/cryptoKeys/k:decrypt", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"code": 401, "status": "UNAUTHENTICATED", "message": "invalid token"}}`))
+			return
+		}
+
+		var req struct {
+			Ciphertext []byte `json:"ciphertext"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "ciphertext", string(req.Ciphertext))
+
+		json.NewEncoder(w).Encode(map[string][]byte{"plaintext": []byte("secret")})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	decryptor, err := kms.NewGCP(kms.GCPConfig{
+		KeyName:          "projects/p/locations/global/keyRings/r/cryptoKeys/k",
+		Endpoint:         srv.URL,
+		MetadataEndpoint: srv.URL,
+	})
+	require.Nil(t, err)
+
+	var conf struct {
+		Password string `envconfig:",encrypted"`
+		APIKey   string `envconfig:",encrypted"`
+	}
+
+	source := envconfig.MapSource{"PASSWORD": ciphertext, "APIKEY": ciphertext}
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Decryptor: decryptor})
+	require.Nil(t, err)
+	require.Equal(t, "secret", conf.Password)
+	require.Equal(t, "secret", conf.APIKey)
+	// the token is cached
+	require.Equal(t, 1, tokens)
+
+	_, err = kms.NewGCP(kms.GCPConfig{})
+	require.Equal(t, "kms: no key name", err.Error())
+}
+
+func TestGCPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "status": "PERMISSION_DENIED", "message": "permission denied"}}`))
+	}))
+	defer srv.Close()
+
+	decryptor, err := kms.NewGCP(kms.GCPConfig{
+		KeyName:  "projects/p/locations/global/keyRings/r/cryptoKeys/k",
+		Endpoint: srv.URL,
+		Token: func(ctx context.Context) (string, error) {
+			return "token", nil
+		},
+	})
+	require.Nil(t, err)
+
+	_, err = decryptor.Decrypt(context.Background(), "PASSWORD", ciphertext)
+	require.Equal(t, "kms: decrypt projects/p/locations/global/keyRings/r/cryptoKeys/k: PERMISSION_DENIED: permission denied", err.Error())
+}
@@ -0,0 +1,206 @@
+package envconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// WriteKubernetesManifests writes a Kubernetes ConfigMap and a Secret named name holding the values of
+// the conf object with opts to w, in YAML. conf must be a pointer.
+//
+// The values of the secret fields go to the Secret, the others to the ConfigMap. The fields left empty get
+// their default value and the fields without a value are written with an empty value, ready to be filled.
+// A manifest without any key is left out.
+//
+// Use them in a container with envFrom:
+//
+//	envFrom:
+//	  - configMapRef:
+//	      name: myapp
+//	  - secretRef:
+//	      name: myapp
+func WriteKubernetesManifests(w io.Writer, conf interface{}, opts Options, name string) error {
+	vars, err := exportVars(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	var data, secrets []exportVar
+	for _, v := range vars {
+		if v.secret {
+			secrets = append(secrets, v)
+		} else {
+			data = append(data, v)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if len(data) > 0 {
+		writeKubernetesManifest(bw, "ConfigMap", name, "data", data)
+	}
+	if len(secrets) > 0 {
+		if len(data) > 0 {
+			bw.WriteString("---\n")
+		}
+		writeKubernetesManifest(bw, "Secret", name, "stringData", secrets)
+	}
+
+	return bw.Flush()
+}
+
+func writeKubernetesManifest(w *bufio.Writer, kind, name, dataField string, vars []exportVar) {
+	fmt.Fprintf(w, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, strconv.Quote(name))
+	if kind == "Secret" {
+		w.WriteString("type: Opaque\n")
+	}
+
+	fmt.Fprintf(w, "%s:\n", dataField)
+	for _, v := range vars {
+		if v.description != "" {
+			fmt.Fprintf(w, "  # %s\n", v.description)
+		}
+		// a YAML double quoted string accepts the escape sequences of Go
+		fmt.Fprintf(w, "  %s: %s\n", v.key, strconv.Quote(v.value))
+	}
+}
+
+// WriteHelmEnv writes the env of a container reading the conf object with opts to w, as a Helm template.
+// conf must be a pointer. Each variable gets a value depending on its field:
+//
+//   - a secret field references the key of the Secret named name, like the one of WriteKubernetesManifests
+//   - a field with a default value is static: its value is written as is
+//   - the other fields depend on the environment: their value is read from .Values.env
+//
+// For example:
+//
+//	env:
+//	  # Listen address
+//	  - name: APP_ADDR
+//	    value: ":8080"
+//	  - name: APP_NAME
+//	    value: {{ .Values.env.APP_NAME | quote }}
+//	  - name: APP_PASSWORD
+//	    valueFrom:
+//	      secretKeyRef:
+//	        name: "myapp"
+//	        key: APP_PASSWORD
+func WriteHelmEnv(w io.Writer, conf interface{}, opts Options, name string) error {
+	vars, err := exportVars(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("env:\n")
+	for _, v := range vars {
+		if v.description != "" {
+			fmt.Fprintf(bw, "  # %s\n", v.description)
+		}
+		fmt.Fprintf(bw, "  - name: %s\n", v.key)
+
+		switch {
+		case v.secret:
+			fmt.Fprintf(bw, "    valueFrom:\n      secretKeyRef:\n        name: %s\n        key: %s\n", strconv.Quote(name), v.key)
+		case v.hasDefault:
+			value := strconv.Quote(v.value)
+			if strings.Contains(v.value, "{{") {
+				// a quoted string is also a string constant of the template
+				value = "{{ " + value + " }}"
+			}
+			fmt.Fprintf(bw, "    value: %s\n", value)
+		default:
+			fmt.Fprintf(bw, "    value: {{ .Values.env.%s | quote }}\n", v.key)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// PodInfo is the identity of a pod, read from the variables set with the Downward API of Kubernetes.
+// Embed it in a configuration so that all services read their identity the same way:
+//
+//	var conf struct {
+//		Pod  envconfig.PodInfo
+//		Addr string
+//	}
+//
+// The keys are the same whatever the prefix. WritePodInfoEnv writes the env of the container setting them.
+type PodInfo struct {
+	Name      string `envconfig:"POD_NAME,desc=Name of the pod"`
+	Namespace string `envconfig:"POD_NAMESPACE,desc=Namespace of the pod"`
+	NodeName  string `envconfig:"NODE_NAME,optional,desc=Name of the node running the pod"`
+	IP        string `envconfig:"POD_IP,optional,desc=IP address of the pod"`
+}
+
+// podInfoFields are the fields of the pod of the variables of PodInfo.
+var podInfoFields = [][2]string{
+	{"POD_NAME", "metadata.name"},
+	{"POD_NAMESPACE", "metadata.namespace"},
+	{"NODE_NAME", "spec.nodeName"},
+	{"POD_IP", "status.podIP"},
+}
+
+// Validate implements Validator. The names must be valid Kubernetes names and the IP a valid IP address,
+// which catches the variables set by hand or to the wrong field.
+func (p *PodInfo) Validate() error {
+	var errs []error
+	if !isDNSSubdomain(p.Name) {
+		errs = append(errs, fmt.Errorf("invalid pod name %q, it must be a lowercase RFC 1123 subdomain", p.Name))
+	}
+	if len(p.Namespace) > 63 || !isDNSSubdomain(p.Namespace) || strings.Contains(p.Namespace, ".") {
+		errs = append(errs, fmt.Errorf("invalid namespace %q, it must be a lowercase RFC 1123 label", p.Namespace))
+	}
+	if p.NodeName != "" && !isDNSSubdomain(p.NodeName) {
+		errs = append(errs, fmt.Errorf("invalid node name %q, it must be a lowercase RFC 1123 subdomain", p.NodeName))
+	}
+	if p.IP != "" && net.ParseIP(p.IP) == nil {
+		errs = append(errs, fmt.Errorf("invalid pod IP %q", p.IP))
+	}
+	return errors.Join(errs...)
+}
+
+// String returns namespace/name.
+func (p PodInfo) String() string {
+	return p.Namespace + "/" + p.Name
+}
+
+// isDNSSubdomain returns true if s is a lowercase RFC 1123 subdomain, the format of most Kubernetes names.
+func isDNSSubdomain(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// WritePodInfoEnv writes the env of a container setting the variables of PodInfo with the Downward API, in YAML:
+//
+//	env:
+//	  - name: POD_NAME
+//	    valueFrom:
+//	      fieldRef:
+//	        fieldPath: metadata.name
+//	...
+func WritePodInfoEnv(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("env:\n")
+	for _, f := range podInfoFields {
+		fmt.Fprintf(bw, "  - name: %s\n    valueFrom:\n      fieldRef:\n        fieldPath: %s\n", f[0], f[1])
+	}
+	return bw.Flush()
+}
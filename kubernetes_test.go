@@ -0,0 +1,127 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestWriteKubernetesManifests(t *testing.T) {
+	var conf struct {
+		Addr     string `envconfig:"default=:8080,desc=Listen address"`
+		Name     string
+		Password string `envconfig:",secret"`
+	}
+	conf.Name = "foo \"bar\""
+
+	var buf bytes.Buffer
+	err := envconfig.WriteKubernetesManifests(&buf, &conf, envconfig.Options{Prefix: "APP"}, "myapp")
+	require.Nil(t, err)
+	require.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "myapp"
+data:
+  # Listen address
+  APP_ADDR: ":8080"
+  APP_NAME: "foo \"bar\""
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: "myapp"
+type: Opaque
+stringData:
+  APP_PASSWORD: ""
+`, buf.String())
+}
+
+func TestPodInfo(t *testing.T) {
+	var conf struct {
+		Pod  envconfig.PodInfo
+		Addr string
+	}
+
+	source := envconfig.MapSource{
+		"APP_ADDR":      ":8080",
+		"POD_NAME":      "myapp-7d9f8b6c5-x2x4z",
+		"POD_NAMESPACE": "prod",
+		"NODE_NAME":     "ip-10-0-1-12.eu-west-1.compute.internal",
+		"POD_IP":        "10.0.1.34",
+	}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "prod/myapp-7d9f8b6c5-x2x4z", conf.Pod.String())
+	require.Equal(t, "10.0.1.34", conf.Pod.IP)
+
+	source["POD_NAMESPACE"] = "prod.eu"
+	source["POD_IP"] = "metadata.name"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `invalid namespace "prod.eu"`)
+	require.Contains(t, err.Error(), `invalid pod IP "metadata.name"`)
+
+	source["POD_NAMESPACE"] = "prod"
+	source["POD_IP"] = ""
+	source["POD_NAME"] = "MyApp"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `invalid pod name "MyApp"`)
+
+	delete(source, "POD_NAME")
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "POD_NAME")
+}
+
+func TestWritePodInfoEnv(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, envconfig.WritePodInfoEnv(&buf))
+	require.Equal(t, `env:
+  - name: POD_NAME
+    valueFrom:
+      fieldRef:
+        fieldPath: metadata.name
+  - name: POD_NAMESPACE
+    valueFrom:
+      fieldRef:
+        fieldPath: metadata.namespace
+  - name: NODE_NAME
+    valueFrom:
+      fieldRef:
+        fieldPath: spec.nodeName
+  - name: POD_IP
+    valueFrom:
+      fieldRef:
+        fieldPath: status.podIP
+`, buf.String())
+}
+
+func TestWriteHelmEnv(t *testing.T) {
+	var conf struct {
+		Addr     string `envconfig:"default=:8080,desc=Listen address"`
+		Template string `envconfig:"default=hello {{name}}"`
+		Name     string
+		Password string `envconfig:",secret"`
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteHelmEnv(&buf, &conf, envconfig.Options{Prefix: "APP"}, "myapp")
+	require.Nil(t, err)
+	require.Equal(t, `env:
+  # Listen address
+  - name: APP_ADDR
+    value: ":8080"
+  - name: APP_TEMPLATE
+    value: {{ "hello {{name}}" }}
+  - name: APP_NAME
+    value: {{ .Values.env.APP_NAME | quote }}
+  - name: APP_PASSWORD
+    valueFrom:
+      secretKeyRef:
+        name: "myapp"
+        key: APP_PASSWORD
+`, buf.String())
+}
@@ -0,0 +1,126 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// systemdFirstFD is the first file descriptor passed by systemd socket activation.
+const systemdFirstFD = 3
+
+// Listener describes where a service listens. It is parsed from one of:
+//
+//	tcp://0.0.0.0:8080     a TCP address, tcp4:// and tcp6:// are also supported
+//	unix:///tmp/app.sock   a unix socket path
+//	fd://3                 an inherited file descriptor
+//	systemd://             the first socket passed by systemd socket activation
+//	systemd://http         the socket named http passed by systemd socket activation (see FileDescriptorName=)
+type Listener struct {
+	// Scheme is one of tcp, tcp4, tcp6, unix, fd or systemd.
+	Scheme string
+	// Address is the TCP address, the socket path, the file descriptor or the systemd socket name.
+	Address string
+}
+
+// DocValue implements DocValuer.
+func (Listener) DocValue() string {
+	return "tcp://0.0.0.0:8080"
+}
+
+// Unmarshal implements Unmarshaler.
+func (l *Listener) Unmarshal(s string) error {
+	i := strings.Index(s, "://")
+	if i < 0 {
+		return fmt.Errorf("invalid listener %q, expected scheme://address", s)
+	}
+
+	res := Listener{Scheme: strings.ToLower(s[:i]), Address: s[i+3:]}
+
+	switch res.Scheme {
+	case "tcp", "tcp4", "tcp6":
+		if _, _, err := net.SplitHostPort(res.Address); err != nil {
+			return fmt.Errorf("invalid listener %q: %v", s, err)
+		}
+	case "unix":
+		if res.Address == "" {
+			return fmt.Errorf("invalid listener %q, empty socket path", s)
+		}
+	case "fd":
+		if fd, err := strconv.Atoi(res.Address); err != nil || fd < 0 {
+			return fmt.Errorf("invalid listener %q, invalid file descriptor", s)
+		}
+	case "systemd":
+	default:
+		return fmt.Errorf("invalid listener %q, unknown scheme %s", s, res.Scheme)
+	}
+
+	*l = res
+
+	return nil
+}
+
+// String returns the listener in the format accepted by Unmarshal.
+func (l Listener) String() string {
+	return l.Scheme + "://" + l.Address
+}
+
+// Listen returns a net.Listener for the listener.
+func (l Listener) Listen() (net.Listener, error) {
+	switch l.Scheme {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return net.Listen(l.Scheme, l.Address)
+	case "fd":
+		fd, err := strconv.Atoi(l.Address)
+		if err != nil {
+			return nil, err
+		}
+		return fileListener(fd, l.String())
+	case "systemd":
+		fd, err := systemdFD(l.Address)
+		if err != nil {
+			return nil, err
+		}
+		return fileListener(fd, l.String())
+	default:
+		return nil, fmt.Errorf("envconfig: unknown listener scheme %s", l.Scheme)
+	}
+}
+
+func fileListener(fd int, name string) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), name)
+	if f == nil {
+		return nil, fmt.Errorf("envconfig: invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+
+	return net.FileListener(f)
+}
+
+// systemdFD returns the file descriptor passed by systemd with the name, or the first one if name is empty.
+// See sd_listen_fds(3).
+func systemdFD(name string) (int, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return 0, errors.New("envconfig: no socket passed by systemd")
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return 0, errors.New("envconfig: no socket passed by systemd")
+	}
+
+	if name == "" {
+		return systemdFirstFD, nil
+	}
+
+	for i, fdName := range strings.Split(os.Getenv("LISTEN_FDNAMES"), ":") {
+		if fdName == name && i < n {
+			return systemdFirstFD + i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("envconfig: no socket named %s passed by systemd", name)
+}
@@ -0,0 +1,70 @@
+package envconfig_test
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestListener(t *testing.T) {
+	dir := t.TempDir()
+
+	var conf struct {
+		HTTP  envconfig.Listener
+		Admin envconfig.Listener
+	}
+
+	os.Setenv("HTTP", "tcp://127.0.0.1:0")
+	os.Setenv("ADMIN", "unix://"+filepath.Join(dir, "admin.sock"))
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, envconfig.Listener{Scheme: "tcp", Address: "127.0.0.1:0"}, conf.HTTP)
+	require.Equal(t, "unix://"+filepath.Join(dir, "admin.sock"), conf.Admin.String())
+
+	l, err := conf.HTTP.Listen()
+	require.Nil(t, err)
+	defer l.Close()
+
+	ul, err := conf.Admin.Listen()
+	require.Nil(t, err)
+	require.Equal(t, filepath.Join(dir, "admin.sock"), ul.Addr().String())
+	ul.Close()
+
+	// inherit the file descriptor of the TCP listener
+	f, err := l.(*net.TCPListener).File()
+	require.Nil(t, err)
+	defer f.Close()
+
+	os.Setenv("HTTP", fmt.Sprintf("fd://%d", f.Fd()))
+
+	err = envconfig.Init(&conf)
+	require.Nil(t, err)
+
+	fl, err := conf.HTTP.Listen()
+	require.Nil(t, err)
+	require.Equal(t, l.Addr().String(), fl.Addr().String())
+	fl.Close()
+
+	os.Setenv("HTTP", "systemd://")
+	os.Setenv("LISTEN_PID", "")
+
+	err = envconfig.Init(&conf)
+	require.Nil(t, err)
+
+	_, err = conf.HTTP.Listen()
+	require.Equal(t, "envconfig: no socket passed by systemd", err.Error())
+
+	os.Setenv("HTTP", "udp://127.0.0.1:53")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse HTTP (field HTTP): invalid listener "udp://127.0.0.1:53", unknown scheme udp`, err.Error())
+
+	os.Setenv("HTTP", "")
+	os.Setenv("ADMIN", "")
+}
@@ -0,0 +1,22 @@
+package envconfig
+
+// Logger is the structured logger of Options.Logger, implemented by *slog.Logger.
+// The arguments are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Warn(msg string, args ...any)
+}
+
+// logField logs the resolution of the field f.
+func (ctx *fieldContext) logField(f FieldReport) {
+	switch f.Origin {
+	case FromSource, FromFile:
+		ctx.opts.Logger.Debug("envconfig: value read", "field", f.Path, "key", f.Key, "source", f.Source, "value", f.Value)
+	case FromDefault:
+		ctx.opts.Logger.Debug("envconfig: default value used", "field", f.Path, "value", f.Value)
+	case FromExisting:
+		ctx.opts.Logger.Debug("envconfig: existing value kept", "field", f.Path)
+	case Unset:
+		ctx.opts.Logger.Debug("envconfig: optional value not set", "field", f.Path, "key", canonicalKey(ctx))
+	}
+}
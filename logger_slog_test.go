@@ -0,0 +1,24 @@
+//go:build go1.21
+
+package envconfig_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var conf struct {
+		Timeout string `envconfig:"default=10s"`
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{}, Logger: logger})
+	require.Nil(t, err)
+	require.Contains(t, buf.String(), `level=DEBUG msg="envconfig: default value used" field=Timeout value=10s`)
+}
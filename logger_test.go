@@ -0,0 +1,77 @@
+package envconfig_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+// recordLogger records the messages as lines.
+type recordLogger struct {
+	lines []string
+}
+
+func (l *recordLogger) log(level, msg string, args ...any) {
+	line := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		line += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	l.lines = append(l.lines, line)
+}
+
+func (l *recordLogger) Debug(msg string, args ...any) { l.log("DEBUG", msg, args...) }
+func (l *recordLogger) Warn(msg string, args ...any)  { l.log("WARN", msg, args...) }
+
+func TestLogger(t *testing.T) {
+	var conf struct {
+		Name     string
+		Timeout  string `envconfig:"default=10s"`
+		Password string `envconfig:",secret"`
+		Proxy    string `envconfig:"optional"`
+		Tracing  struct {
+			Endpoint string
+		} `envconfig:"optional"`
+	}
+
+	var logger recordLogger
+	source := envconfig.MapSource{"APP_NAME": "foo", "APP_PASSWORD": "foobar"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source, Logger: &logger})
+	require.Nil(t, err)
+	require.Equal(t, `DEBUG envconfig: value read field=Name key=APP_NAME source=envconfig.MapSource value=foo
+DEBUG envconfig: default value used field=Timeout value=10s
+DEBUG envconfig: value read field=Password key=APP_PASSWORD source=envconfig.MapSource value=***
+DEBUG envconfig: optional value not set field=Proxy key=APP_PROXY
+DEBUG envconfig: optional value not set field=Tracing.Endpoint key=APP_TRACING_ENDPOINT
+WARN envconfig: optional struct disabled field=Tracing missing=APP_TRACING_ENDPOINT`, strings.Join(logger.lines, "\n"))
+}
+
+func TestLoggerDeprecatedKey(t *testing.T) {
+	var conf struct {
+		LogLevel string `envconfig:"LOG_LEVEL,deprecated=LOGLEVEL"`
+	}
+
+	var logger recordLogger
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{"LOGLEVEL": "debug"}, Logger: &logger})
+	require.Nil(t, err)
+	require.Equal(t, `WARN envconfig: deprecated key used field=LogLevel key=LOGLEVEL replacement=LOG_LEVEL
+DEBUG envconfig: value read field=LogLevel key=LOGLEVEL source=envconfig.MapSource value=debug`, strings.Join(logger.lines, "\n"))
+}
+
+func TestLoggerSourceFailure(t *testing.T) {
+	var conf struct {
+		Port int `envconfig:"default=8080"`
+	}
+
+	var logger recordLogger
+	opts := envconfig.Options{
+		Source:                 envconfig.Chain{failingSource{}, envconfig.MapSource{}},
+		TolerateSourceFailures: true,
+		Logger:                 &logger,
+	}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "WARN envconfig: source failure tolerated field=Port source=envconfig_test.failingSource error=unreachable", logger.lines[0])
+}
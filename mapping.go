@@ -0,0 +1,117 @@
+package envconfig
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strconv"
+)
+
+// MappedField is a field of a struct which can't be tagged, read like a struct field of the same name, type
+// and envconfig tag and set with a setter. See MapStruct.
+type MappedField[T any] struct {
+	name string
+	tag  string
+	typ  reflect.Type
+	set  func(target *T, v reflect.Value)
+}
+
+// MapField returns the field name of T, set by set with the value read. The name is turned into keys like
+// the name of a struct field and tag is the envconfig tag of the field, like "optional,default=5s".
+func MapField[T, V any](name, tag string, set func(target *T, v V)) MappedField[T] {
+	return MappedField[T]{
+		name: name,
+		tag:  tag,
+		typ:  reflect.TypeOf(new(V)).Elem(),
+		set: func(target *T, v reflect.Value) {
+			set(target, v.Interface().(V))
+		},
+	}
+}
+
+// Mapping describes how to read a struct type which can't be tagged, like the options of another library.
+// Use it with Options.Mappings.
+type Mapping struct {
+	typ reflect.Type
+	// fields is the struct read instead of typ, with one field per mapped field.
+	fields reflect.Type
+	set    []func(target, v reflect.Value)
+	err    error
+}
+
+// MapStruct returns the mapping of the struct type T to fields: a field of type T in a config struct
+// is read as if it was a struct with the mapped fields, with the same rules for the keys, the default values
+// and the errors, and the values read are then passed to the setters.
+//
+//	var conf struct {
+//		Redis redis.Options
+//	}
+//	err := envconfig.InitWithOptions(&conf, envconfig.Options{
+//		Mappings: []envconfig.Mapping{
+//			envconfig.MapStruct(
+//				envconfig.MapField("Addr", "default=localhost:6379", func(o *redis.Options, v string) { o.Addr = v }),
+//				envconfig.MapField("DialTimeout", "optional", func(o *redis.Options, v time.Duration) { o.DialTimeout = v }),
+//			),
+//		},
+//	})
+//
+// The setters aren't called with zero values, so that the defaults of the library apply. The fields of type T
+// are skipped by Marshal, since their values can't be read back.
+func MapStruct[T any](fields ...MappedField[T]) Mapping {
+	m := Mapping{typ: reflect.TypeOf(new(T)).Elem()}
+	if m.typ.Kind() != reflect.Struct {
+		m.err = fmt.Errorf("envconfig: invalid mapping of %v, it is not a struct", m.typ)
+		return m
+	}
+
+	structFields := make([]reflect.StructField, len(fields))
+	seen := make(map[string]bool)
+	for i, f := range fields {
+		if !token.IsIdentifier(f.name) || !token.IsExported(f.name) || seen[f.name] {
+			m.err = fmt.Errorf("envconfig: invalid mapped field %q of %v", f.name, m.typ)
+			return m
+		}
+		seen[f.name] = true
+
+		structFields[i] = reflect.StructField{
+			Name: f.name,
+			Type: f.typ,
+			Tag:  reflect.StructTag("envconfig:" + strconv.Quote(f.tag)),
+		}
+		set := f.set
+		m.set = append(m.set, func(target, v reflect.Value) {
+			set(target.Interface().(*T), v)
+		})
+	}
+	m.fields = reflect.StructOf(structFields)
+
+	return m
+}
+
+// mapping returns the mapping of the type typ, nil if there is none.
+func (o Options) mapping(typ reflect.Type) *Mapping {
+	for i := range o.Mappings {
+		if o.Mappings[i].typ == typ {
+			return &o.Mappings[i]
+		}
+	}
+	return nil
+}
+
+// readMapped reads the struct of the mapped fields of m with ctx, and sets the non-zero values
+// to the field value of the mapped type. The mapping is valid, see initWithChecks.
+func readMapped(value reflect.Value, m *Mapping, ctx *fieldContext) (bool, error) {
+	fields := reflect.New(m.fields).Elem()
+	nonNil, err := readStruct(fields, ctx)
+	if err != nil {
+		return nonNil, err
+	}
+
+	for i, set := range m.set {
+		if v := fields.Field(i); !v.IsZero() {
+			set(value.Addr(), v)
+		}
+	}
+
+	return nonNil, nil
+}
@@ -0,0 +1,81 @@
+package envconfig_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+// clientOptions stands for the options of another library, which can't be tagged.
+type clientOptions struct {
+	addr        string
+	dialTimeout time.Duration
+	retries     int
+}
+
+var clientMapping = envconfig.MapStruct(
+	envconfig.MapField("Addr", "default=localhost:6379", func(o *clientOptions, v string) { o.addr = v }),
+	envconfig.MapField("DialTimeout", "optional", func(o *clientOptions, v time.Duration) { o.dialTimeout = v }),
+	envconfig.MapField("Retries", "optional", func(o *clientOptions, v int) { o.retries = v }),
+)
+
+func TestMapStruct(t *testing.T) {
+	var conf struct {
+		Name  string
+		Cache *clientOptions
+	}
+	conf.Cache = &clientOptions{retries: 3}
+
+	source := envconfig.MapSource{"NAME": "foo", "CACHE_DIAL_TIMEOUT": "2s"}
+	opts := envconfig.Options{Source: source, Mappings: []envconfig.Mapping{clientMapping}}
+	report, err := envconfig.InitWithReport(&conf, opts)
+	require.Nil(t, err)
+	// the zero values don't replace the defaults of the library
+	require.Equal(t, &clientOptions{addr: "localhost:6379", dialTimeout: 2 * time.Second, retries: 3}, conf.Cache)
+
+	f, ok := report.Field("Cache.DialTimeout")
+	require.True(t, ok)
+	require.Equal(t, "CACHE_DIAL_TIMEOUT", f.Key)
+
+	source["CACHE_RETRIES"] = "many"
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "CACHE_RETRIES")
+
+	var usage strings.Builder
+	require.Nil(t, envconfig.WriteUsage(&usage, &conf, opts))
+	require.Contains(t, usage.String(), "CACHE_DIALTIMEOUT")
+
+	values, err := envconfig.MarshalWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"NAME": "foo"}, values)
+}
+
+func TestMapStructInvalid(t *testing.T) {
+	var conf struct {
+		Cache clientOptions
+	}
+
+	testCases := []struct {
+		mapping envconfig.Mapping
+		err     string
+	}{
+		{
+			envconfig.MapStruct(envconfig.MapField("addr", "", func(o *clientOptions, v string) {})),
+			`envconfig: invalid mapped field "addr" of envconfig_test.clientOptions`,
+		},
+		{
+			envconfig.MapStruct(envconfig.MapField("Addr", "", func(o *string, v string) {})),
+			"envconfig: invalid mapping of string, it is not a struct",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{}, Mappings: []envconfig.Mapping{tc.mapping}})
+		require.NotNil(t, err)
+		require.Equal(t, tc.err, err.Error())
+	}
+}
@@ -0,0 +1,59 @@
+package envconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes the keys expected by InitWithOptions for the conf object and opts to w as a Markdown table,
+// along with their type, default value, whether they are required and their description. conf must be a pointer.
+func WriteMarkdown(w io.Writer, conf interface{}, opts Options) error {
+	fields, err := describe(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString("| Variable | Type | Default | Required | Description |\n")
+	bw.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, f := range fields {
+		required := "yes"
+		switch {
+		case f.optional || f.defaultVal != "":
+			required = "no"
+		case f.group != "":
+			required = "group " + f.group
+		}
+
+		defaultVal := ""
+		if f.defaultVal != "" {
+			defaultVal = markdownCode(f.defaultVal)
+		}
+
+		fmt.Fprintf(bw, "| %s | %s | %s | %s | %s |\n",
+			markdownCode(f.key), markdownCode(f.typ.String()), defaultVal, required, escapeMarkdown(f.documentation()))
+	}
+
+	return bw.Flush()
+}
+
+// markdownCode returns s as inline code in a table cell.
+func markdownCode(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// escapeMarkdown escapes the characters of s which would break a table cell or be interpreted as Markdown.
+func escapeMarkdown(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", ">", "&gt;",
+		"\r\n", "<br>", "\n", "<br>",
+	)
+	return r.Replace(s)
+}
@@ -0,0 +1,52 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	var conf struct {
+		Addr    string        `envconfig:"default=:8080,desc=Listen address | host:port"`
+		Name    string        `envconfig:"desc=Name of the *service*"`
+		Timeout time.Duration `envconfig:"optional"`
+		Auth    struct {
+			Token string `envconfig:"group=token"`
+		}
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteMarkdown(&buf, &conf, envconfig.Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, "| Variable | Type | Default | Required | Description |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `APP_ADDR` | `string` | `:8080` | no | Listen address \\| host:port |\n"+
+		"| `APP_NAME` | `string` |  | yes | Name of the \\*service\\* |\n"+
+		"| `APP_TIMEOUT` | `time.Duration` |  | no |  |\n"+
+		"| `APP_AUTH_TOKEN` | `string` |  | group token |  |\n", buf.String())
+}
+
+type docLevel int
+
+func (l *docLevel) Unmarshal(s string) error { return nil }
+
+func (docLevel) DocValue() string { return "debug" }
+
+func TestWriteMarkdownDocValue(t *testing.T) {
+	var conf struct {
+		Level  docLevel `envconfig:"desc=Log level"`
+		Listen envconfig.Listener
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteMarkdown(&buf, &conf, envconfig.Options{})
+	require.Nil(t, err)
+	require.Equal(t, "| Variable | Type | Default | Required | Description |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| `LEVEL` | `envconfig_test.docLevel` |  | yes | Log level, e.g. debug |\n"+
+		"| `LISTEN` | `envconfig.Listener` |  | yes | e.g. tcp://0.0.0.0:8080 |\n", buf.String())
+}
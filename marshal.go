@@ -0,0 +1,300 @@
+package envconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns the environment variables which Init would read into conf, by key.
+// conf must be a struct or a pointer to a struct.
+//
+// The keys are the canonical keys of the fields and the values are formatted so that Init parses them back.
+// Nil pointers and empty values are left out, since an empty variable is the same as an unset one.
+// The values of secret fields are included.
+func Marshal(conf interface{}) (map[string]string, error) {
+	return MarshalWithOptions(conf, Options{})
+}
+
+// MarshalWithOptions returns the environment variables which InitWithOptions would read into conf
+// with opts, by key. The options changing the output are Prefix, Variant, Mappings, NoFlatten, AllowUnexported
+// and the options naming the keys, SnakeCase, Acronyms and KeyNames. StrictCase doesn't change it, the keys
+// are always the canonical keys, and the other options are ignored.
+func MarshalWithOptions(conf interface{}, opts Options) (map[string]string, error) {
+	opts = withProvidedOptions(conf, opts)
+	value := reflect.ValueOf(conf)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, ErrInvalidValueKind
+	}
+
+	res := make(map[string]string)
+	err := marshalStruct(value, &fieldContext{
+		name:   opts.Prefix,
+		secret: opts.RedactValues,
+		state:  &state{opts: opts},
+	}, func(f marshaledField) {
+		if f.value != "" {
+			res[f.key] = f.value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Environ returns the variables in the KEY=value form of os.Environ, sorted by key.
+// It can be used with the result of Marshal to configure a child process with exec.Cmd.Env.
+func Environ(vars map[string]string) []string {
+	res := make([]string, 0, len(vars))
+	for key, value := range vars {
+		res = append(res, key+"="+value)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// AppendEnviron marshals conf with opts and appends the variables to env in the KEY=value form,
+// replacing the variables of env with the same keys. It returns the updated slice.
+//
+// It builds the environment of a child process from a config struct, or a part of it with a prefix:
+//
+//	cmd.Env, err = envconfig.AppendEnviron(os.Environ(), &conf.Worker, envconfig.Options{Prefix: "WORKER"})
+//
+// Use a nil env to not inherit the environment of the current process.
+func AppendEnviron(env []string, conf interface{}, opts Options) ([]string, error) {
+	vars, err := MarshalWithOptions(conf, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0, len(env)+len(vars))
+	for _, kv := range env {
+		key := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			key = kv[:i]
+		}
+		if _, ok := vars[key]; !ok {
+			res = append(res, kv)
+		}
+	}
+
+	return append(res, Environ(vars)...), nil
+}
+
+// marshaledField is the value of a field formatted by marshalStruct, path is the path of the field in the struct.
+type marshaledField struct {
+	key    string
+	path   string
+	value  string
+	secret bool
+	redact string
+}
+
+// marshalStruct formats the values of the fields of value and passes them to emit, including the empty ones.
+func marshalStruct(value reflect.Value, ctx *fieldContext, emit func(f marshaledField)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		name := value.Type().Field(i).Name
+
+		tag, err := parseTag(value.Type().Field(i), ctx.opts)
+		if err != nil {
+			return fmt.Errorf("%w (field %s)", err, combineName(ctx.path, name))
+		}
+		unexported := !field.CanInterface() && !(isEmbeddedStruct(value.Type().Field(i)) && field.Kind() == reflect.Struct)
+		if tag.skip || unexported {
+			if unexported && !ctx.opts.AllowUnexported {
+				return fmt.Errorf("%w (field %s)", ErrUnexportedField, combineName(ctx.path, name))
+			}
+			continue
+		}
+
+		if tag.rest {
+			if field.Type() != stringMapType {
+				return fmt.Errorf("envconfig: rest field must be a map[string]string (field %s)", combineName(ctx.path, name))
+			}
+			if field.Len() == 0 {
+				continue
+			}
+			if ctx.name == "" {
+				return fmt.Errorf("envconfig: rest field requires a prefix")
+			}
+			prefix := keyPrefixes(ctx.name)[0]
+			path := combineName(ctx.path, name)
+			for key, v := range field.Interface().(map[string]string) {
+				emit(marshaledField{key: prefix + key, path: path + "." + key, value: v, secret: ctx.secret || tag.secret})
+			}
+			continue
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		inSecret := isSecretType(field.Type())
+		if inSecret {
+			field = unwrapSecret(field)
+			for field.Kind() == reflect.Ptr && !field.IsNil() {
+				field = field.Elem()
+			}
+		}
+		if field.Kind() == reflect.Ptr {
+			continue
+		}
+		if ctx.opts.mapping(field.Type()) != nil {
+			// the values of a mapped struct can't be read back
+			continue
+		}
+
+		fieldCtx := &fieldContext{
+			name:           combineName(ctx.name, name),
+			path:           combineName(ctx.path, name),
+			customName:     tag.customName,
+			durationFormat: tag.durationFormat,
+			secret:         ctx.secret || tag.secret || tag.redact != "" || tag.encrypted || inSecret,
+			redact:         tag.redact,
+			csv:            tag.csv,
+			state:          ctx.state,
+		}
+
+		if field.Kind() == reflect.Struct && !isUnmarshaler(field.Type()) {
+			fieldCtx.customName = ""
+			fieldCtx.name = tag.structName(ctx.name, name)
+			if err := marshalStruct(field, fieldCtx, emit); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := canonicalKey(fieldCtx)
+
+		var str string
+		if fieldCtx.csv {
+			str, err = marshalCSV(field, fieldCtx)
+		} else {
+			str, err = marshalValue(field, fieldCtx)
+		}
+		if err != nil {
+			return fmt.Errorf("envconfig: unable to marshal %s (field %s): %v", key, fieldCtx.path, err)
+		}
+		emit(marshaledField{key: key, path: fieldCtx.path, value: str, secret: fieldCtx.secret, redact: fieldCtx.redact})
+	}
+
+	return nil
+}
+
+func marshalValue(v reflect.Value, ctx *fieldContext) (string, error) {
+	vtype := v.Type()
+
+	if m, ok := asMarshaler(v); ok {
+		return m.Marshal()
+	}
+	if isUnmarshaler(vtype) {
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+	}
+
+	switch {
+	case isDurationField(vtype):
+		d := time.Duration(v.Int())
+		if ctx.durationFormat == durationFormatISO8601 {
+			return formatISO8601Duration(d), nil
+		}
+		return d.String(), nil
+	case vtype == httpHeaderType:
+		return formatHeader(v.Interface().(http.Header)), nil
+	case vtype == byteSliceType:
+		if v.Len() == 0 {
+			return "", nil
+		}
+		return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+	}
+
+	switch kind := vtype.Kind(); kind {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, vtype.Bits()), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "", nil
+		}
+		return marshalValue(v.Elem(), ctx)
+	case reflect.Slice:
+		tokens := make([]string, v.Len())
+		for i := range tokens {
+			token, err := marshalValue(v.Index(i), ctx)
+			if err != nil {
+				return "", err
+			}
+			if v.Index(i).Kind() != reflect.Struct && strings.ContainsAny(token, ",{}") {
+				return "", fmt.Errorf("slice element %q can't contain a comma or a brace", token)
+			}
+			tokens[i] = token
+		}
+		return strings.Join(tokens, ","), nil
+	case reflect.Struct:
+		// only structs inside a slice are marshaled as a token
+		tokens := make([]string, v.NumField())
+		for i := range tokens {
+			token, err := marshalValue(v.Field(i), ctx)
+			if err != nil {
+				return "", err
+			}
+			if strings.ContainsAny(token, ",{}") {
+				return "", fmt.Errorf("struct field %q can't contain a comma or a brace", token)
+			}
+			tokens[i] = token
+		}
+		return "{" + strings.Join(tokens, ",") + "}", nil
+	default:
+		return "", fmt.Errorf("kind %v not supported", kind)
+	}
+}
+
+// asMarshaler returns v or its address as a Marshaler.
+func asMarshaler(v reflect.Value) (Marshaler, bool) {
+	if m, ok := v.Interface().(Marshaler); ok {
+		return m, true
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(Marshaler); ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// formatHeader formats an http.Header in the format accepted by parseHeader, sorted by key.
+func formatHeader(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var tokens []string
+	for _, key := range keys {
+		for _, value := range h[key] {
+			tokens = append(tokens, key+":"+value)
+		}
+	}
+
+	return strings.Join(tokens, ",")
+}
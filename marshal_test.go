@@ -0,0 +1,123 @@
+package envconfig_test
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type marshalConfig struct {
+	Name    string
+	Port    uint16
+	Ratio   float64
+	Debug   bool
+	Timeout time.Duration `envconfig:"duration=iso8601"`
+	Tags    []string
+	Token   []byte
+	Header  http.Header
+	Window  envconfig.Window
+	Country envconfig.CountryCode
+	MySQL   struct {
+		Address string `envconfig:"MYSQL_ADDR"`
+		Backups []struct {
+			Host string
+			Port int
+		}
+	}
+	Cache *struct {
+		Size int
+	}
+	Extra map[string]string `envconfig:",rest"`
+}
+
+func TestMarshal(t *testing.T) {
+	var conf marshalConfig
+	conf.Name = "foobar"
+	conf.Port = 8080
+	conf.Ratio = 0.25
+	conf.Debug = true
+	conf.Timeout = 90 * time.Minute
+	conf.Tags = []string{"a", "b"}
+	conf.Token = []byte("secret")
+	conf.Header = http.Header{"X-Api-Key": {"foo"}, "Accept": {"text/html"}}
+	require.Nil(t, conf.Window.Unmarshal("Mon-Fri 09:00-17:00"))
+	conf.Country = "FR"
+	conf.MySQL.Address = "localhost"
+	conf.MySQL.Backups = append(conf.MySQL.Backups, struct {
+		Host string
+		Port int
+	}{"backup", 3306})
+	conf.Extra = map[string]string{"FOO": "bar"}
+
+	vars, err := envconfig.MarshalWithOptions(&conf, envconfig.Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{
+		"APP_NAME":          "foobar",
+		"APP_PORT":          "8080",
+		"APP_RATIO":         "0.25",
+		"APP_DEBUG":         "true",
+		"APP_TIMEOUT":       "PT1H30M",
+		"APP_TAGS":          "a,b",
+		"APP_TOKEN":         "c2VjcmV0",
+		"APP_HEADER":        "Accept:text/html,X-Api-Key:foo",
+		"APP_WINDOW":        "Mon+Tue+Wed+Thu+Fri 09:00-17:00 UTC",
+		"APP_COUNTRY":       "FR",
+		"MYSQL_ADDR":        "localhost",
+		"APP_MYSQL_BACKUPS": "{backup,3306}",
+		"APP_FOO":           "bar",
+	}, vars)
+
+	for _, kv := range envconfig.Environ(vars) {
+		require.Nil(t, os.Setenv(kv[:strings.IndexByte(kv, '=')], kv[strings.IndexByte(kv, '=')+1:]))
+	}
+	defer func() {
+		for key := range vars {
+			os.Setenv(key, "")
+		}
+	}()
+
+	var conf2 marshalConfig
+	err = envconfig.InitWithOptions(&conf2, envconfig.Options{Prefix: "APP", AllOptional: true, LeaveNil: true})
+	require.Nil(t, err)
+	require.Equal(t, conf, conf2)
+}
+
+func TestMarshalSliceWithComma(t *testing.T) {
+	conf := struct {
+		Tags []string
+	}{
+		Tags: []string{"a,b"},
+	}
+
+	_, err := envconfig.Marshal(&conf)
+	require.NotNil(t, err)
+	require.Equal(t, `envconfig: unable to marshal TAGS (field Tags): slice element "a,b" can't contain a comma or a brace`, err.Error())
+}
+
+func TestEnviron(t *testing.T) {
+	require.Equal(t, []string{"A=1", "B=2"}, envconfig.Environ(map[string]string{"B": "2", "A": "1"}))
+}
+
+func TestAppendEnviron(t *testing.T) {
+	var conf struct {
+		Worker struct {
+			Count int
+			Queue string
+		}
+	}
+	conf.Worker.Count = 4
+	conf.Worker.Queue = "jobs"
+
+	env, err := envconfig.AppendEnviron([]string{"HOME=/root", "WORKER_COUNT=1"}, &conf.Worker, envconfig.Options{Prefix: "WORKER"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"HOME=/root", "WORKER_COUNT=4", "WORKER_QUEUE=jobs"}, env)
+
+	env, err = envconfig.AppendEnviron(nil, &conf, envconfig.Options{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"WORKER_COUNT=4", "WORKER_QUEUE=jobs"}, env)
+}
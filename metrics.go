@@ -0,0 +1,26 @@
+package envconfig
+
+import "time"
+
+// Metrics receives the metrics of the configuration, so that they can be exported to a monitoring system like
+// Prometheus without envconfig depending on it. Set it in Options, and in the options of a Store for the reloads:
+//
+//	type promMetrics struct{}
+//
+//	func (promMetrics) Loaded(resolved, defaults int) {
+//		resolvedGauge.Set(float64(resolved))
+//		defaultsGauge.Set(float64(defaults))
+//	}
+//	...
+//
+// A fleet silently falling back to its default values is spotted by alerting on the number of defaults.
+type Metrics interface {
+	// Loaded is called after each successful read of the configuration with the number of fields whose value
+	// was read from the source or a file, and the number of fields with their default value.
+	Loaded(resolved, defaults int)
+	// Lookup is called after the lookup of a key in the source named source, with its duration and error.
+	// It is called concurrently when Options.Parallelism is set.
+	Lookup(source string, d time.Duration, err error)
+	// Reloaded is called after each reload of a Store, with its error, nil if the configuration was replaced.
+	Reloaded(err error)
+}
@@ -0,0 +1,92 @@
+package envconfig_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type recordMetrics struct {
+	mu       sync.Mutex
+	resolved int
+	defaults int
+	lookups  map[string]int
+	errors   int
+	reloads  []error
+}
+
+func (m *recordMetrics) Loaded(resolved, defaults int) {
+	m.resolved, m.defaults = resolved, defaults
+}
+
+func (m *recordMetrics) Lookup(source string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lookups == nil {
+		m.lookups = make(map[string]int)
+	}
+	m.lookups[source]++
+	if err != nil {
+		m.errors++
+	}
+}
+
+func (m *recordMetrics) Reloaded(err error) {
+	m.reloads = append(m.reloads, err)
+}
+
+func TestMetrics(t *testing.T) {
+	var conf struct {
+		Name    string
+		Port    int    `envconfig:"default=80"`
+		Timeout string `envconfig:"optional"`
+	}
+
+	var metrics recordMetrics
+	source := envconfig.MapSource{"NAME": "foo"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Metrics: &metrics})
+	require.Nil(t, err)
+	require.Equal(t, 1, metrics.resolved)
+	require.Equal(t, 1, metrics.defaults)
+	// NAME is found, PORT and TIMEOUT are also looked up in lower case
+	require.Equal(t, map[string]int{"envconfig.MapSource": 5}, metrics.lookups)
+	require.Equal(t, 0, metrics.errors)
+
+	metrics = recordMetrics{}
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Metrics: &metrics, Parallelism: 2})
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{"envconfig.MapSource": 5}, metrics.lookups)
+}
+
+func TestMetricsLookupError(t *testing.T) {
+	var conf struct {
+		Name string
+	}
+
+	var metrics recordMetrics
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: failingSource{}, Metrics: &metrics})
+	require.NotNil(t, err)
+	require.Equal(t, 1, metrics.errors)
+	// nothing is loaded
+	require.Equal(t, 0, metrics.resolved)
+}
+
+func TestMetricsReload(t *testing.T) {
+	var metrics recordMetrics
+	source := envconfig.MapSource{"NAME": "foo", "PORT": "80"}
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: source, Metrics: &metrics},
+	})
+	require.Nil(t, err)
+	require.Nil(t, metrics.reloads)
+
+	require.Nil(t, store.Reload())
+	source["PORT"] = "foobar"
+	err = store.Reload()
+	require.NotNil(t, err)
+	require.Equal(t, []error{nil, err}, metrics.reloads)
+	require.Equal(t, 2, metrics.resolved)
+}
@@ -0,0 +1,76 @@
+package envconfig
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+func validateMediaType(s string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid media type: %v", s, err)
+	}
+	if !strings.Contains(mediaType, "/") {
+		return "", fmt.Errorf("%q is not a valid media type: no subtype", s)
+	}
+	return mime.FormatMediaType(mediaType, params), nil
+}
+
+// knownExtensions are the file extensions accepted by the extension validator. It doesn't rely on
+// mime.TypeByExtension, which also reads the mime.types files of the system, so that a value valid on a machine
+// is valid everywhere.
+var knownExtensions = map[string]bool{
+	".aac": true, ".abw": true, ".arc": true, ".avi": true, ".avif": true, ".azw": true, ".bin": true,
+	".bmp": true, ".bz": true, ".bz2": true, ".cjs": true, ".css": true, ".csv": true, ".doc": true,
+	".docx": true, ".eot": true, ".epub": true, ".flac": true, ".gif": true, ".gz": true,
+	".htm": true, ".html": true, ".ico": true, ".ics": true, ".jar": true, ".jpeg": true,
+	".jpg": true, ".js": true, ".json": true, ".jsonld": true, ".m4a": true, ".md": true,
+	".mid": true, ".midi": true, ".mjs": true, ".mkv": true, ".mov": true, ".mp3": true, ".mp4": true,
+	".mpeg": true, ".oga": true, ".ogg": true, ".ogv": true, ".opus": true, ".otf": true,
+	".pdf": true, ".php": true, ".png": true, ".ppt": true, ".pptx": true, ".rar": true, ".rtf": true,
+	".sh": true, ".svg": true, ".tar": true, ".tif": true, ".tiff": true, ".toml": true, ".ts": true,
+	".ttf": true, ".txt": true, ".wasm": true, ".wav": true, ".weba": true, ".webm": true,
+	".webp": true, ".woff": true, ".woff2": true, ".xhtml": true, ".xls": true, ".xlsx": true,
+	".xml": true, ".yaml": true, ".yml": true, ".zip": true, ".7z": true,
+}
+
+func validateExtension(s string) (string, error) {
+	ext := strings.ToLower(s)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	if !knownExtensions[ext] {
+		return "", fmt.Errorf("%q is not a known file extension", s)
+	}
+	return ext, nil
+}
+
+// MediaType is a media type (also known as MIME type) with its parameters, like text/html; charset=utf-8.
+type MediaType struct {
+	// Type is the media type in lower case, without the parameters.
+	Type   string
+	Params map[string]string
+}
+
+// DocValue implements DocValuer.
+func (MediaType) DocValue() string {
+	return "text/html; charset=utf-8"
+}
+
+// Unmarshal implements Unmarshaler.
+func (t *MediaType) Unmarshal(s string) error {
+	if _, err := validateMediaType(s); err != nil {
+		return err
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(s)
+	*t = MediaType{Type: mediaType, Params: params}
+
+	return nil
+}
+
+// String returns the canonical form of the media type.
+func (t MediaType) String() string {
+	return mime.FormatMediaType(t.Type, t.Params)
+}
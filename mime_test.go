@@ -0,0 +1,41 @@
+package envconfig_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestMediaTypes(t *testing.T) {
+	var conf struct {
+		Accepted   []string `envconfig:",mimetype"`
+		Extensions []string `envconfig:",extension"`
+		Default    envconfig.MediaType
+	}
+
+	os.Setenv("ACCEPTED", "image/PNG,Text/HTML; Charset=utf-8")
+	os.Setenv("EXTENSIONS", "PNG,.jpg,yml")
+	os.Setenv("DEFAULT", "application/json; charset=utf-8")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+	require.Equal(t, []string{"image/png", "text/html; charset=utf-8"}, conf.Accepted)
+	require.Equal(t, []string{".png", ".jpg", ".yml"}, conf.Extensions)
+	require.Equal(t, "application/json", conf.Default.Type)
+	require.Equal(t, map[string]string{"charset": "utf-8"}, conf.Default.Params)
+	require.Equal(t, "application/json; charset=utf-8", conf.Default.String())
+
+	os.Setenv("ACCEPTED", "image")
+	os.Setenv("EXTENSIONS", "foobar")
+	os.Setenv("DEFAULT", "")
+
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse ACCEPTED (field Accepted): "image" is not a valid media type: no subtype
+envconfig: unable to parse EXTENSIONS (field Extensions): "foobar" is not a known file extension
+envconfig: keys DEFAULT, default not found (field Default)`, err.Error())
+
+	os.Setenv("ACCEPTED", "")
+	os.Setenv("EXTENSIONS", "")
+}
@@ -0,0 +1,111 @@
+package envconfig
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// parameterSpec is the document written by WriteParameterSpec.
+type parameterSpec struct {
+	Parameters []parameter `json:"parameters"`
+}
+
+// parameter is an OpenAPI parameter object describing a key, the location env being an extension of OpenAPI.
+type parameter struct {
+	Name        string           `json:"name"`
+	In          string           `json:"in"`
+	Description string           `json:"description,omitempty"`
+	Required    bool             `json:"required"`
+	Schema      *parameterSchema `json:"schema"`
+	Example     string           `json:"example,omitempty"`
+	Field       string           `json:"x-field"`
+	Secret      bool             `json:"x-secret,omitempty"`
+	Group       string           `json:"x-group,omitempty"`
+}
+
+// parameterSchema is the schema of the value of a parameter.
+type parameterSchema struct {
+	Type      string           `json:"type"`
+	Format    string           `json:"format,omitempty"`
+	Items     *parameterSchema `json:"items,omitempty"`
+	Enum      []string         `json:"enum,omitempty"`
+	Pattern   string           `json:"pattern,omitempty"`
+	Default   string           `json:"default,omitempty"`
+	WriteOnly bool             `json:"writeOnly,omitempty"`
+}
+
+// WriteParameterSpec writes the specification of the environment expected by InitWithOptions for the conf object
+// and opts to w, as a JSON document listing OpenAPI parameter objects, so that platforms validating deployment
+// manifests can check the variables of a service:
+//
+//	{"parameters": [
+//	  {"name": "APP_PORT", "in": "env", "required": false, "schema": {"type": "integer", "format": "uint16", "default": "8080"}, "x-field": "Port"}
+//	]}
+//
+// Unlike WriteJSONSchema, the parameters are keyed by the name of the variable and their schema is the type of
+// the field: boolean, integer, number, array or string. The default values and the enums are the strings read by
+// Init. The x-field extension is the path of the field, x-secret marks the secret fields and x-group is the group
+// of the field, which is not required when another group of its struct is complete. The rest fields are left out.
+// The parameters are in the order of the fields.
+func WriteParameterSpec(w io.Writer, conf interface{}, opts Options) error {
+	fields, err := describe(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	spec := parameterSpec{Parameters: []parameter{}}
+	for _, f := range fields {
+		if f.rest {
+			continue
+		}
+
+		schema := parameterValueSchema(f.typ, f.durationFormat, f.validators)
+		schema.Default = f.defaultVal
+		schema.WriteOnly = f.secret
+
+		spec.Parameters = append(spec.Parameters, parameter{
+			Name:        f.key,
+			In:          "env",
+			Description: f.description,
+			Required:    !f.optional && f.defaultVal == "" && f.group == "",
+			Schema:      schema,
+			Example:     f.example,
+			Field:       f.path,
+			Secret:      f.secret,
+			Group:       f.group,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(spec)
+}
+
+// parameterValueSchema returns the schema of the value of a parameter of type typ.
+// The strings have the enum or the pattern of their JSON schema.
+func parameterValueSchema(typ reflect.Type, durationFormat string, validators []string) *parameterSchema {
+	switch {
+	case isDurationField(typ):
+		return &parameterSchema{Type: "string", Format: "duration", Pattern: valueSchema(typ, durationFormat, validators).Pattern}
+	case typ == byteSliceType:
+		return &parameterSchema{Type: "string", Format: "byte"}
+	case !isUnmarshaler(typ) && typ != httpHeaderType:
+		switch typ.Kind() {
+		case reflect.Bool:
+			return &parameterSchema{Type: "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return &parameterSchema{Type: "integer", Format: typ.Kind().String()}
+		case reflect.Float32, reflect.Float64:
+			return &parameterSchema{Type: "number", Format: typ.Kind().String()}
+		case reflect.Slice:
+			return &parameterSchema{Type: "array", Items: parameterValueSchema(typ.Elem(), durationFormat, validators)}
+		}
+	}
+
+	value := valueSchema(typ, durationFormat, validators)
+	return &parameterSchema{Type: "string", Enum: value.Enum, Pattern: value.Pattern}
+}
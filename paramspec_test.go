@@ -0,0 +1,46 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestWriteParameterSpec(t *testing.T) {
+	var conf struct {
+		Port     uint16        `envconfig:"default=8080,desc=Port of the HTTP server"`
+		Debug    bool          `envconfig:"optional"`
+		Timeout  time.Duration `envconfig:"duration=iso8601"`
+		Ratio    float64
+		Hosts    []string
+		SameSite envconfig.SameSite
+		Auth     struct {
+			Password string `envconfig:",secret,group=basic"`
+			Token    string `envconfig:"group=token"`
+		}
+		Extra map[string]string `envconfig:",rest"`
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteParameterSpec(&buf, &conf, envconfig.Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.JSONEq(t, `{"parameters": [
+  {"name": "APP_PORT", "in": "env", "description": "Port of the HTTP server", "required": false,
+   "schema": {"type": "integer", "format": "uint16", "default": "8080"}, "x-field": "Port"},
+  {"name": "APP_DEBUG", "in": "env", "required": false, "schema": {"type": "boolean"}, "x-field": "Debug"},
+  {"name": "APP_TIMEOUT", "in": "env", "required": true,
+   "schema": {"type": "string", "format": "duration", "pattern": "^-?P"}, "x-field": "Timeout"},
+  {"name": "APP_RATIO", "in": "env", "required": true, "schema": {"type": "number", "format": "float64"}, "x-field": "Ratio"},
+  {"name": "APP_HOSTS", "in": "env", "required": true,
+   "schema": {"type": "array", "items": {"type": "string"}}, "x-field": "Hosts"},
+  {"name": "APP_SAMESITE", "in": "env", "required": true,
+   "schema": {"type": "string", "enum": ["default", "lax", "strict", "none"]}, "x-field": "SameSite"},
+  {"name": "APP_AUTH_PASSWORD", "in": "env", "required": false, "schema": {"type": "string", "writeOnly": true},
+   "x-field": "Auth.Password", "x-secret": true, "x-group": "basic"},
+  {"name": "APP_AUTH_TOKEN", "in": "env", "required": false, "schema": {"type": "string"},
+   "x-field": "Auth.Token", "x-group": "token"}
+]}`, buf.String())
+}
@@ -0,0 +1,92 @@
+package envconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CloudProvider is a cloud provider of a Placement.
+type CloudProvider string
+
+// The cloud providers whose regions are recognized by Placement.
+const (
+	ProviderAWS   CloudProvider = "aws"
+	ProviderGCP   CloudProvider = "gcp"
+	ProviderAzure CloudProvider = "azure"
+)
+
+var (
+	// awsPlacement matches the regions of AWS like us-east-1 and us-gov-west-1, and their zones like us-east-1a.
+	awsPlacement = regexp.MustCompile(`^([a-z]{2}(?:-gov|-iso[a-z]?)?-(?:north|south|east|west|central|northeast|northwest|southeast|southwest)-[0-9])([a-z])?$`)
+	// gcpPlacement matches the regions of GCP like europe-west1, and their zones like europe-west1-b.
+	gcpPlacement = regexp.MustCompile(`^((?:africa|asia|australia|europe|me|northamerica|southamerica|us)-(?:north|south|east|west|central|northeast|northwest|southeast|southwest)[0-9]+)(-[a-z])?$`)
+)
+
+// azureRegions are the names of the regions of Azure, which don't follow a pattern.
+var azureRegions = makeCodeSet(`
+	eastus eastus2 westus westus2 westus3 centralus northcentralus southcentralus westcentralus
+	canadacentral canadaeast brazilsouth brazilsoutheast mexicocentral chilecentral
+	northeurope westeurope uksouth ukwest francecentral francesouth germanywestcentral germanynorth
+	switzerlandnorth switzerlandwest norwayeast norwaywest swedencentral swedensouth polandcentral
+	italynorth spaincentral austriaeast belgiumcentral denmarkeast
+	eastasia southeastasia japaneast japanwest koreacentral koreasouth australiaeast australiasoutheast
+	australiacentral australiacentral2 centralindia southindia westindia jioindiawest jioindiacentral
+	indonesiacentral malaysiawest newzealandnorth taiwannorth
+	uaenorth uaecentral qatarcentral israelcentral southafricanorth southafricawest
+`)
+
+// Placement is a region of a cloud provider, and optionally one of its zones, parsed from the names used by
+// the providers:
+//
+//	us-east-1, us-east-1a         a region and a zone of AWS
+//	europe-west1, europe-west1-b  a region and a zone of GCP
+//	eastus2                       a region of Azure
+//
+// The names are case insensitive. The zones of Azure are numbered per subscription and aren't part of the names,
+// so that the placements of Azure only have a region.
+type Placement struct {
+	Provider CloudProvider
+	// Region is the name of the region, like us-east-1.
+	Region string
+	// Zone is the name of the zone, like us-east-1a, empty if the value is a region.
+	Zone string
+}
+
+// DocValue implements DocValuer.
+func (Placement) DocValue() string {
+	return "us-east-1a"
+}
+
+// Unmarshal implements Unmarshaler.
+func (p *Placement) Unmarshal(s string) error {
+	name := strings.ToLower(strings.TrimSpace(s))
+
+	var res Placement
+	if m := awsPlacement.FindStringSubmatch(name); m != nil {
+		res = Placement{Provider: ProviderAWS, Region: m[1]}
+		if m[2] != "" {
+			res.Zone = name
+		}
+	} else if m := gcpPlacement.FindStringSubmatch(name); m != nil {
+		res = Placement{Provider: ProviderGCP, Region: m[1]}
+		if m[2] != "" {
+			res.Zone = name
+		}
+	} else if _, ok := azureRegions[name]; ok {
+		res = Placement{Provider: ProviderAzure, Region: name}
+	} else {
+		return fmt.Errorf("%q is not a known region or zone of AWS, GCP or Azure", s)
+	}
+
+	*p = res
+	return nil
+}
+
+// String returns the name of the zone, or of the region if there is no zone.
+func (p Placement) String() string {
+	if p.Zone != "" {
+		return p.Zone
+	}
+	return p.Region
+}
@@ -0,0 +1,57 @@
+package envconfig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestPlacement(t *testing.T) {
+	testCases := []struct {
+		in  string
+		exp envconfig.Placement
+	}{
+		{"us-east-1", envconfig.Placement{Provider: envconfig.ProviderAWS, Region: "us-east-1"}},
+		{"US-EAST-1A", envconfig.Placement{Provider: envconfig.ProviderAWS, Region: "us-east-1", Zone: "us-east-1a"}},
+		{"us-gov-west-1", envconfig.Placement{Provider: envconfig.ProviderAWS, Region: "us-gov-west-1"}},
+		{"ap-southeast-2b", envconfig.Placement{Provider: envconfig.ProviderAWS, Region: "ap-southeast-2", Zone: "ap-southeast-2b"}},
+		{"europe-west1", envconfig.Placement{Provider: envconfig.ProviderGCP, Region: "europe-west1"}},
+		{"europe-west1-b", envconfig.Placement{Provider: envconfig.ProviderGCP, Region: "europe-west1", Zone: "europe-west1-b"}},
+		{"northamerica-northeast2-a", envconfig.Placement{Provider: envconfig.ProviderGCP, Region: "northamerica-northeast2", Zone: "northamerica-northeast2-a"}},
+		{" eastus2 ", envconfig.Placement{Provider: envconfig.ProviderAzure, Region: "eastus2"}},
+		{"WestEurope", envconfig.Placement{Provider: envconfig.ProviderAzure, Region: "westeurope"}},
+	}
+
+	for _, tc := range testCases {
+		var p envconfig.Placement
+		err := p.Unmarshal(tc.in)
+		require.Nil(t, err, tc.in)
+		require.Equal(t, tc.exp, p, tc.in)
+	}
+
+	for _, in := range []string{"", "us-east", "moon-west-1", "europe-west1-bb", "eastus9"} {
+		var p envconfig.Placement
+		err := p.Unmarshal(in)
+		require.NotNil(t, err, in)
+	}
+
+	var conf struct {
+		Zone   envconfig.Placement
+		Region *envconfig.Placement
+	}
+	source := envconfig.MapSource{"ZONE": "europe-west1-b", "REGION": "eastus"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "europe-west1-b", conf.Zone.String())
+	require.Equal(t, "europe-west1", conf.Zone.Region)
+	require.Equal(t, "eastus", conf.Region.String())
+
+	values, err := envconfig.Marshal(conf)
+	require.Nil(t, err)
+	require.Equal(t, "europe-west1-b", values["ZONE"])
+
+	source["ZONE"] = "mars-1"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Contains(t, err.Error(), `"mars-1" is not a known region or zone of AWS, GCP or Azure`)
+}
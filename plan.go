@@ -0,0 +1,251 @@
+package envconfig
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Plan is the field plan of a config struct type: the envconfig tags of its fields, parsed ahead of time.
+// It is written as Go code by WritePlan and registered by the generated code with RegisterPlan, so that Init
+// reads the tags from the plan instead of parsing the struct tags. It only skips the parsing of the tags:
+// the struct is still walked with reflection, and the values are still looked up and parsed.
+//
+// The plan is checked against the struct tags the first time it is used, and ignored if it is out of date,
+// so that an edited tag is never overridden. The plans aren't used with Options.Variant either, since the tags
+// of the variants aren't part of them.
+type Plan []PlanField
+
+// PlanField is the parsed envconfig tag of a field of a Plan, by path like Redis.Addr.
+type PlanField struct {
+	Path string
+	Tag  Tag
+}
+
+// plans are the registered plans, by struct type.
+var plans sync.Map
+
+// registered is a registered plan.
+type registered struct {
+	// tags are the tags of the plan by path.
+	tags map[string]Tag
+
+	// check checks the plan against the struct tags once, stale are the paths of the fields out of date.
+	check sync.Once
+	stale []string
+}
+
+// RegisterPlan registers the plan of the struct type of conf, a pointer to a struct. It is called by the code
+// generated by WritePlan and replaces the plan registered before, if any.
+func RegisterPlan(conf interface{}, plan Plan) {
+	tags := make(map[string]Tag, len(plan))
+	for _, f := range plan {
+		tags[f.Path] = f.Tag
+	}
+	plans.Store(structType(reflect.TypeOf(conf)), &registered{tags: tags})
+}
+
+// registeredPlan returns the plan registered for the struct type typ, nil if there is none.
+func registeredPlan(typ reflect.Type) *registered {
+	r, ok := plans.Load(typ)
+	if !ok {
+		return nil
+	}
+	return r.(*registered)
+}
+
+// planTags returns the tags of the plan registered for the struct type typ by path, nil if there is none
+// or if it is out of date. The plan is checked against the fields read with opts the first time.
+func planTags(typ reflect.Type, opts Options) map[string]Tag {
+	r := registeredPlan(typ)
+	if r == nil {
+		return nil
+	}
+	r.check.Do(func() {
+		var plan Plan
+		planStruct(&plan, typ, "", opts)
+		r.stale = staleFields(r.tags, plan)
+	})
+	if len(r.stale) > 0 {
+		if opts.Logger != nil {
+			opts.Logger.Warn("envconfig: plan out of date ignored", "type", typ.String(), "fields", strings.Join(r.stale, ","))
+		}
+		return nil
+	}
+	return r.tags
+}
+
+// staleFields returns the sorted paths of the fields whose tags differ between the registered tags and plan,
+// or which are only in one of them.
+func staleFields(tags map[string]Tag, plan Plan) []string {
+	var stale []string
+	paths := make(map[string]bool, len(plan))
+	for _, f := range plan {
+		paths[f.Path] = true
+		if t, ok := tags[f.Path]; !ok || !reflect.DeepEqual(t, f.Tag) {
+			stale = append(stale, f.Path)
+		}
+	}
+	for path := range tags {
+		if !paths[path] {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// structType returns the struct type pointed to by typ, which may be a pointer to a pointer.
+func structType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// NewPlan returns the plan of the conf object, with the fields read by InitWithOptions with opts.
+func NewPlan(conf interface{}, opts Options) (Plan, error) {
+	opts = withProvidedOptions(conf, opts)
+	typ := structType(reflect.TypeOf(conf))
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, ErrInvalidValueKind
+	}
+
+	var plan Plan
+	planStruct(&plan, typ, "", opts)
+	return plan, nil
+}
+
+// planStruct adds the fields of the struct type typ to plan, like readStruct reads them.
+func planStruct(plan *Plan, typ reflect.Type, path string, opts Options) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() && !isEmbeddedStruct(field) {
+			continue
+		}
+		t := ParseTag(field.Tag.Get("envconfig"))
+		if t.Skip {
+			continue
+		}
+
+		fieldPath := combineName(path, field.Name)
+		*plan = append(*plan, PlanField{Path: fieldPath, Tag: t})
+
+		fieldType := field.Type
+		for {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			} else if isSecretType(fieldType) {
+				fieldType = secretElem(fieldType)
+			} else {
+				break
+			}
+		}
+		if t.Rest || fieldType.Kind() != reflect.Struct || isUnmarshaler(fieldType) {
+			continue
+		}
+		if m := opts.mapping(fieldType); m != nil {
+			if m.err == nil {
+				planStruct(plan, m.fields, fieldPath, opts)
+			}
+			continue
+		}
+		planStruct(plan, fieldType, fieldPath, opts)
+	}
+}
+
+// WritePlan writes the plan of the conf object and opts to w, as the Go source of the package pkg registering it.
+// The type of conf must be a struct type declared in pkg.
+//
+// It is meant to be called by a generator run by go generate, see GeneratePlan.
+func WritePlan(w io.Writer, conf interface{}, opts Options, pkg string) error {
+	typ := structType(reflect.TypeOf(conf))
+	if typ == nil || typ.Name() == "" {
+		return fmt.Errorf("envconfig: unable to write the plan of %v, it is not a named type", typ)
+	}
+	plan, err := NewPlan(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by envconfig. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import \"github.com/vrischmann/envconfig\"\n\n")
+	fmt.Fprintf(&buf, "func init() {\n\tenvconfig.RegisterPlan(&%s{}, envconfig.Plan{\n", typ.Name())
+	for _, f := range plan {
+		fmt.Fprintf(&buf, "\t\t{Path: %q, Tag: envconfig.Tag{%s}},\n", f.Path, tagLiteral(f.Tag))
+	}
+	fmt.Fprintf(&buf, "\t})\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// tagLiteral returns the non-zero fields of t as the elements of a Go composite literal.
+func tagLiteral(t Tag) string {
+	v := reflect.ValueOf(t)
+	var elems []string
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); !f.IsZero() {
+			elems = append(elems, fmt.Sprintf("%s: %#v", v.Type().Field(i).Name, f.Interface()))
+		}
+	}
+	return strings.Join(elems, ", ")
+}
+
+// GeneratePlan writes the plan of the conf object and opts to the file at path, as the Go source of the package pkg
+// registering it. The file is left untouched if it is up to date.
+//
+// It is meant to be called by a generator run by go generate, next to the config struct:
+//
+//	// gen/main.go
+//	func main() {
+//		if err := envconfig.GeneratePlan("config_plan.go", &config.Config{}, envconfig.Options{}, "config"); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+//	// config.go
+//	//go:generate go run ./gen
+//
+// Run CheckPlan in a test to catch a plan left out of date by a change of the struct.
+func GeneratePlan(path string, conf interface{}, opts Options, pkg string) error {
+	var buf bytes.Buffer
+	if err := WritePlan(&buf, conf, opts, pkg); err != nil {
+		return err
+	}
+
+	if data, err := os.ReadFile(path); err == nil && bytes.Equal(data, buf.Bytes()) {
+		return nil
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// CheckPlan returns an error if the plan registered for the struct type of conf doesn't match its struct tags,
+// or if there is none.
+func CheckPlan(conf interface{}, opts Options) error {
+	typ := structType(reflect.TypeOf(conf))
+	r := registeredPlan(typ)
+	if r == nil {
+		return fmt.Errorf("envconfig: no plan registered for %v", typ)
+	}
+	plan, err := NewPlan(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	if stale := staleFields(r.tags, plan); len(stale) > 0 {
+		return fmt.Errorf("envconfig: plan of %v is out of date (fields %s), generate it again", typ, strings.Join(stale, ","))
+	}
+	return nil
+}
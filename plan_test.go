@@ -0,0 +1,86 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type plannedConfig struct {
+	Addr  string `envconfig:"ADDR|ADDRESS,default=:8080"`
+	Redis struct {
+		Host  string
+		Hosts []string `envconfig:",optional,iso3166"`
+	} `envconfig:"prefix=CACHE"`
+	Ignored int `envconfig:"-"`
+}
+
+func TestWritePlan(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, envconfig.WritePlan(&buf, &plannedConfig{}, envconfig.Options{}, "config"))
+	require.Equal(t, `// Code generated by envconfig. DO NOT EDIT.
+
+package config
+
+import "github.com/vrischmann/envconfig"
+
+func init() {
+	envconfig.RegisterPlan(&plannedConfig{}, envconfig.Plan{
+		{Path: "Addr", Tag: envconfig.Tag{Name: "ADDR|ADDRESS", Default: ":8080"}},
+		{Path: "Redis", Tag: envconfig.Tag{Prefix: "CACHE"}},
+		{Path: "Redis.Host", Tag: envconfig.Tag{}},
+		{Path: "Redis.Hosts", Tag: envconfig.Tag{Optional: true, Validators: []string{"iso3166"}}},
+	})
+}
+`, buf.String())
+
+	var conf struct{ Addr string }
+	err := envconfig.WritePlan(&buf, &conf, envconfig.Options{}, "config")
+	require.Equal(t, "envconfig: unable to write the plan of struct { Addr string }, it is not a named type", err.Error())
+
+	path := filepath.Join(t.TempDir(), "config_plan.go")
+	require.Nil(t, envconfig.GeneratePlan(path, &plannedConfig{}, envconfig.Options{}, "config"))
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Contains(t, string(data), `envconfig.RegisterPlan(&plannedConfig{}`)
+}
+
+func TestRegisterPlan(t *testing.T) {
+	type unplannedConfig struct{ Addr string }
+	err := envconfig.CheckPlan(&unplannedConfig{}, envconfig.Options{})
+	require.Equal(t, "envconfig: no plan registered for envconfig_test.unplannedConfig", err.Error())
+
+	plan, err := envconfig.NewPlan(&plannedConfig{}, envconfig.Options{})
+	require.Nil(t, err)
+	envconfig.RegisterPlan(&plannedConfig{}, plan)
+	require.Nil(t, envconfig.CheckPlan(&plannedConfig{}, envconfig.Options{}))
+
+	source := envconfig.MapSource{"ADDRESS": ":9090", "CACHE_HOST": "localhost", "CACHE_OTHERHOST": "otherhost"}
+	var conf plannedConfig
+	var logger recordLogger
+	require.Nil(t, envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Logger: &logger}))
+	require.Equal(t, ":9090", conf.Addr)
+	require.Equal(t, "localhost", conf.Redis.Host)
+	require.NotContains(t, strings.Join(logger.lines, "\n"), "plan")
+
+	// a plan out of date is ignored, the struct tags are read instead
+	plan[2].Tag.Name = "CACHE_OTHERHOST"
+	plan = append(plan, envconfig.PlanField{Path: "Removed"})
+	envconfig.RegisterPlan(&plannedConfig{}, plan)
+	logger.lines = nil
+	require.Nil(t, envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Logger: &logger}))
+	require.Equal(t, "localhost", conf.Redis.Host)
+	require.Contains(t, logger.lines, "WARN envconfig: plan out of date ignored type=envconfig_test.plannedConfig fields=Redis.Host,Removed")
+
+	err = envconfig.CheckPlan(&plannedConfig{}, envconfig.Options{})
+	require.Equal(t, "envconfig: plan of envconfig_test.plannedConfig is out of date (fields Redis.Host,Removed), generate it again", err.Error())
+
+	// the plans aren't used with a variant
+	require.Nil(t, envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Variant: "test"}))
+	require.Equal(t, "localhost", conf.Redis.Host)
+}
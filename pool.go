@@ -0,0 +1,54 @@
+package envconfig
+
+import (
+	"bytes"
+	"sync"
+)
+
+// scratch are the buffers of a read, reused across the reads if Options.ReuseBuffers is set.
+type scratch struct {
+	// keys are the keys looked up, see state.
+	keys map[string]struct{}
+	// runes, buf, buf2 and names are the buffers of makeAllPossibleKeys.
+	runes []rune
+	buf   bytes.Buffer
+	buf2  bytes.Buffer
+	names map[string]struct{}
+}
+
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		return newScratch()
+	},
+}
+
+func newScratch() *scratch {
+	return &scratch{
+		keys:  make(map[string]struct{}),
+		names: make(map[string]struct{}),
+	}
+}
+
+// release empties the buffers and puts them back into the pool.
+func (s *scratch) release() {
+	for k := range s.keys {
+		delete(s.keys, k)
+	}
+	scratchPool.Put(s)
+}
+
+// keyBuffers returns the empty buffers of makeAllPossibleKeys, the ones of the read if they are reused.
+func (ctx *fieldContext) keyBuffers() *scratch {
+	if ctx.state == nil || ctx.scratch == nil {
+		return newScratch()
+	}
+
+	s := ctx.scratch
+	s.runes = s.runes[:0]
+	s.buf.Reset()
+	s.buf2.Reset()
+	for k := range s.names {
+		delete(s.names, k)
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package envconfig_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type pooledConfig struct {
+	Addr    string
+	Timeout time.Duration `envconfig:"default=5s"`
+	Tenant  struct {
+		ID         int
+		MaxUploads int  `envconfig:"optional"`
+		Debug      bool `envconfig:"optional"`
+	}
+	Hosts []string
+}
+
+var pooledSource = envconfig.MapSource{
+	"ADDR":              ":8080",
+	"TENANT_ID":         "42",
+	"TENANT_MAXUPLOADS": "10",
+	"HOSTS":             "a,b,c",
+}
+
+func TestReuseBuffers(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		var conf pooledConfig
+		err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: pooledSource, ReuseBuffers: true})
+		require.Nil(t, err)
+		require.Equal(t, ":8080", conf.Addr)
+		require.Equal(t, 5*time.Second, conf.Timeout)
+		require.Equal(t, 42, conf.Tenant.ID)
+		require.Equal(t, 10, conf.Tenant.MaxUploads)
+		require.Equal(t, []string{"a", "b", "c"}, conf.Hosts)
+	}
+
+	// the keys looked up by a previous read are unknown to the next one
+	source := envconfig.MapSource{"APP_ADDR": ":8080", "APP_HOSTS": "a"}
+	opts := envconfig.Options{Prefix: "APP", Source: source, Strict: true, ReuseBuffers: true}
+	var conf struct {
+		Addr  string
+		Hosts []string
+	}
+	require.Nil(t, envconfig.InitWithOptions(&conf, opts))
+
+	var other struct {
+		Addr string
+	}
+	err := envconfig.InitWithOptions(&other, opts)
+	require.Equal(t, "envconfig: unknown key APP_HOSTS", err.Error())
+}
+
+func benchmarkInit(b *testing.B, opts envconfig.Options) {
+	opts.Source = pooledSource
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var conf pooledConfig
+		if err := envconfig.InitWithOptions(&conf, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInit(b *testing.B) {
+	benchmarkInit(b, envconfig.Options{})
+}
+
+func BenchmarkInitReuseBuffers(b *testing.B) {
+	benchmarkInit(b, envconfig.Options{ReuseBuffers: true})
+}
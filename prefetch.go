@@ -0,0 +1,119 @@
+package envconfig
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// lookupResult is the result of a lookup done ahead of the walk of the config struct.
+type lookupResult struct {
+	value string
+	// origin is the source which provided the value, nil if it is empty.
+	origin Source
+	err    error
+	// failures are the errors of the members of a Chain which were skipped, see Options.TolerateSourceFailures.
+	failures []*SourceError
+}
+
+// prefetch looks up the keys of the fields of conf with opts.Parallelism workers, so that the walk of the config
+// struct finds them in the state instead of reading them one at a time. The keys of a field are looked up in order
+// until one is set, like readValue does, and the keys of a serial field while no other field is looked up.
+// It gives up when the context of the state is done, the keys not looked up yet are then read by the walk,
+// which reports the error of the context.
+func (s *state) prefetch(conf interface{}) {
+	fields, err := describe(conf, s.opts)
+	if err != nil {
+		// the walk reports the error
+		return
+	}
+
+	ctx := s.lookupCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	source := s.source()
+
+	s.prefetched = make(map[cacheKey]lookupResult)
+
+	jobs := make(chan fieldInfo)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	// the lookups of a serial field hold exclusive, the other ones share it
+	var exclusive sync.RWMutex
+	for i := 0; i < s.opts.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				if f.serial {
+					exclusive.Lock()
+					s.prefetchField(ctx, source, f)
+					exclusive.Unlock()
+				} else {
+					exclusive.RLock()
+					s.prefetchField(ctx, source, f)
+					exclusive.RUnlock()
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(done)
+		defer wg.Wait()
+		defer close(jobs)
+		for _, f := range fields {
+			if f.rest {
+				continue
+			}
+			select {
+			case jobs <- f:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// prefetchField looks up the keys of the field f until one is set.
+func (s *state) prefetchField(ctx context.Context, source Source, f fieldInfo) {
+	lookup := originLookup(ctx, source, f.tag, s.failed)
+	for _, key := range f.keys {
+		if ctx.Err() != nil {
+			return
+		}
+
+		start := time.Now()
+		res := lookup(key)
+		if s.opts.Metrics != nil {
+			s.opts.Metrics.Lookup(sourceName(source), time.Since(start), res.err)
+		}
+
+		s.prefetchMu.Lock()
+		s.prefetched[cacheKey{key: key, tag: f.tag}] = res
+		s.prefetchMu.Unlock()
+
+		if res.err != nil || res.value != "" {
+			return
+		}
+	}
+}
+
+// prefetchedLookup returns the result of the lookup of key done by prefetch, if any.
+func (s *state) prefetchedLookup(key string, tag reflect.StructTag) (lookupResult, bool) {
+	if s.prefetched == nil {
+		return lookupResult{}, false
+	}
+
+	s.prefetchMu.Lock()
+	defer s.prefetchMu.Unlock()
+
+	res, ok := s.prefetched[cacheKey{key: key, tag: tag}]
+	return res, ok
+}
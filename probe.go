@@ -0,0 +1,186 @@
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	probeDNS  = "dns"
+	probeTCP  = "tcp"
+	probeHTTP = "http"
+	probeDSN  = "dsn"
+)
+
+// probers are the probes which can be applied to fields with the probe option, by name.
+var probers = map[string]func(ctx context.Context, value string) error{
+	probeDNS:  probeResolve,
+	probeTCP:  probeDial,
+	probeHTTP: probeGet,
+	// the dsn probe is Options.DSNProbe
+	probeDSN: func(ctx context.Context, value string) error {
+		return errors.New("no DSN probe, see Options.DSNProbe")
+	},
+}
+
+// defaultProbeTimeout is the timeout of a probe when Options.ProbeTimeout is zero.
+const defaultProbeTimeout = 5 * time.Second
+
+// probe is a probe to run once the fields are read.
+type probe struct {
+	name  string
+	field string
+	key   string
+	value string
+	// display is the value to show in errors, redacted for a secret.
+	display string
+	secret  bool
+	// serial is set if the probe must not run concurrently with others.
+	serial bool
+}
+
+// runProbes runs the probes in parallel, each one with the timeout of the options, and returns their outcomes in order.
+// A serial probe runs while no other probe does.
+func runProbes(ctx context.Context, probes []probe, opts Options) Checks {
+	timeout := opts.ProbeTimeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+
+	checks := make(Checks, len(probes))
+
+	// the serial probes hold exclusive, the other ones share it
+	var exclusive sync.RWMutex
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func(i int, p probe) {
+			defer wg.Done()
+
+			if p.serial {
+				exclusive.Lock()
+				defer exclusive.Unlock()
+			} else {
+				exclusive.RLock()
+				defer exclusive.RUnlock()
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			fn := probers[p.name]
+			if p.name == probeDSN && opts.DSNProbe != nil {
+				fn = opts.DSNProbe
+			}
+
+			start := time.Now()
+			checks[i] = Check{Name: p.name, Field: p.field, Key: p.key}
+			if err := fn(ctx, p.value); err != nil {
+				checks[i].Err = &ProbeError{Field: p.field, Key: p.key, Value: p.display, Probe: p.name, Secret: p.secret, Err: err}
+			}
+			checks[i].Duration = time.Since(start)
+		}(i, p)
+	}
+	wg.Wait()
+
+	return checks
+}
+
+// probeAddress returns the host and the port of a value, which is either a URL like https://example.com,
+// an address like example.com:443 or a host. The port is empty if it is unknown.
+func probeAddress(value string) (host, port string, err error) {
+	if strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return "", "", err
+		}
+		host, port = u.Hostname(), u.Port()
+		if port == "" {
+			switch u.Scheme {
+			case "http", "ws":
+				port = "80"
+			case "https", "wss":
+				port = "443"
+			}
+		}
+	} else if h, p, err := net.SplitHostPort(value); err == nil {
+		host, port = h, p
+	} else {
+		host = value
+	}
+
+	if host == "" {
+		return "", "", errors.New("no host")
+	}
+	return host, port, nil
+}
+
+// probeResolve checks that the host of the value can be resolved.
+func probeResolve(ctx context.Context, value string) error {
+	host, _, err := probeAddress(value)
+	if err != nil {
+		return err
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	_, err = net.DefaultResolver.LookupHost(ctx, host)
+	return err
+}
+
+// probeDial checks that a TCP connection can be opened to the value.
+func probeDial(ctx context.Context, value string) error {
+	host, port, err := probeAddress(value)
+	if err != nil {
+		return err
+	}
+	if port == "" {
+		return errors.New("no port")
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeGet checks that the value is an http or https URL which doesn't respond with a server error.
+func probeGet(ctx context.Context, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("not an http URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, value, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
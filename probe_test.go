@@ -0,0 +1,143 @@
+package envconfig_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestProbes(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	// a free port, with nothing listening on it
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	closed := l.Addr().String()
+	l.Close()
+
+	var conf struct {
+		Host     string   `envconfig:"probe=dns"`
+		Backends []string `envconfig:"probe=tcp"`
+		API      string   `envconfig:"probe=http"`
+	}
+
+	source := envconfig.MapSource{
+		"HOST":     "localhost",
+		"BACKENDS": up.Listener.Addr().String() + "," + up.URL,
+		"API":      up.URL,
+	}
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+
+	source["BACKENDS"] = up.Listener.Addr().String() + "," + closed
+	source["API"] = down.URL
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.NotNil(t, err)
+
+	var probeErr *envconfig.ProbeError
+	require.ErrorAs(t, err, &probeErr)
+	require.Equal(t, "Backends", probeErr.Field)
+	require.Equal(t, "tcp", probeErr.Probe)
+	require.Contains(t, err.Error(), "envconfig: http probe of API failed (field API): 503 Service Unavailable")
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, SkipProbes: true})
+	require.Nil(t, err)
+}
+
+func TestDSNProbe(t *testing.T) {
+	var conf struct {
+		Database string `envconfig:",secret,probe=dsn"`
+		Cache    string `envconfig:"probe=dsn"`
+	}
+
+	source := envconfig.MapSource{
+		"DATABASE": "postgres://app:wrong@db/app",
+		"CACHE":    "redis://cache:6379",
+	}
+	opts := envconfig.Options{
+		Source: source,
+		DSNProbe: func(ctx context.Context, dsn string) error {
+			if strings.Contains(dsn, "wrong") {
+				return errors.New("password authentication failed for user app")
+			}
+			return nil
+		},
+	}
+
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.NotNil(t, err)
+	require.Equal(t, "envconfig: dsn probe of DATABASE failed (field Database)", err.Error())
+
+	source["DATABASE"] = "postgres://app:secret@db/app"
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+
+	opts.DSNProbe = nil
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "envconfig: dsn probe of CACHE failed (field Cache): no DSN probe, see Options.DSNProbe")
+}
+
+func TestSerialProbes(t *testing.T) {
+	var conf struct {
+		Database string `envconfig:"probe=dsn,serial"`
+		Cache    string `envconfig:"probe=dsn,serial"`
+	}
+
+	var (
+		mu                    sync.Mutex
+		inFlight, maxInFlight int
+	)
+	opts := envconfig.Options{
+		Source: envconfig.MapSource{"DATABASE": "postgres://db/app", "CACHE": "redis://cache"},
+		DSNProbe: func(ctx context.Context, dsn string) error {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	checks, err := envconfig.InitWithChecks(&conf, opts)
+	require.Nil(t, err)
+	require.Len(t, checks, 2)
+	require.Equal(t, 1, maxInFlight)
+}
+
+func TestUnknownProbe(t *testing.T) {
+	var conf struct {
+		Addr string `envconfig:"default=localhost,probe=icmp"`
+	}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{}})
+	require.NotNil(t, err)
+	require.Equal(t, `envconfig: unknown tag probe "icmp" (field Addr)`, err.Error())
+
+	_, err = envconfig.Marshal(&conf)
+	require.NotNil(t, err)
+	err = envconfig.WriteMarkdown(new(strings.Builder), &conf, envconfig.Options{})
+	require.NotNil(t, err)
+}
@@ -0,0 +1,106 @@
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Load returns a configuration of type T read with opts. It is handy to write the providers of
+// dependency injection frameworks like google/wire:
+//
+//	func NewConfig() (Config, error) {
+//		return envconfig.Load[Config](envconfig.Options{Prefix: "APP"})
+//	}
+func Load[T any](opts Options) (T, error) {
+	var conf T
+	err := InitWithOptions(&conf, opts)
+	return conf, err
+}
+
+// Provide returns a constructor of a configuration of type T read with the prefix,
+// for dependency injection frameworks like uber/fx:
+//
+//	fx.Provide(envconfig.Provide[Config]("APP"))
+func Provide[T any](prefix string) func() (T, error) {
+	return ProvideWithOptions[T](Options{Prefix: prefix})
+}
+
+// ProvideWithOptions is like Provide with options.
+func ProvideWithOptions[T any](opts Options) func() (T, error) {
+	return func() (T, error) {
+		return Load[T](opts)
+	}
+}
+
+// ProvideStore returns a constructor of a store of configurations of type T, see NewStore.
+func ProvideStore[T any](opts StoreOptions) func() (*Store[T], error) {
+	return func() (*Store[T], error) {
+		return NewStore[T](opts)
+	}
+}
+
+// Hooks returns the functions starting and stopping Watch with the interval, to be registered
+// as lifecycle hooks of dependency injection frameworks like uber/fx:
+//
+//	fx.Invoke(func(lc fx.Lifecycle, store *envconfig.Store[Config]) {
+//		onStart, onStop := store.Hooks(time.Minute)
+//		lc.Append(fx.Hook{OnStart: onStart, OnStop: onStop})
+//	})
+//
+// onStart returns an error if Watch is already started. onStop waits for Watch to return, or for its context
+// to be done, and returns the error which stopped Watch before, if any, like a reload error with the StopWatching
+// strategy.
+func (s *Store[T]) Hooks(interval time.Duration) (onStart, onStop func(ctx context.Context) error) {
+	var (
+		mu     sync.Mutex
+		cancel context.CancelFunc
+		// done receives the error which stopped Watch, if any
+		done chan error
+	)
+
+	onStart = func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if cancel != nil {
+			return errors.New("envconfig: the store is already watched")
+		}
+
+		// the context of the start hook is only valid during the start
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		done = make(chan error, 1)
+
+		go func(done chan error) {
+			err := s.Watch(ctx, interval)
+			if ctx.Err() != nil {
+				err = nil
+			}
+			done <- err
+		}(done)
+
+		return nil
+	}
+
+	onStop = func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if cancel == nil {
+			return nil
+		}
+		cancel()
+		cancel = nil
+
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return onStart, onStop
+}
@@ -0,0 +1,74 @@
+package envconfig_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type provideConfig struct {
+	Name string
+}
+
+func TestProvide(t *testing.T) {
+	os.Setenv("APP_NAME", "foobar")
+
+	newConfig := envconfig.Provide[provideConfig]("APP")
+	conf, err := newConfig()
+	require.Nil(t, err)
+	require.Equal(t, "foobar", conf.Name)
+
+	newStore := envconfig.ProvideStore[provideConfig](envconfig.StoreOptions{Options: envconfig.Options{Prefix: "APP"}})
+	store, err := newStore()
+	require.Nil(t, err)
+
+	onStart, onStop := store.Hooks(time.Millisecond)
+	require.Nil(t, onStart(context.Background()))
+	require.NotNil(t, onStart(context.Background()))
+
+	os.Setenv("APP_NAME", "barbaz")
+	require.Eventually(t, func() bool { return store.Get().Name == "barbaz" }, time.Second, time.Millisecond)
+
+	require.Nil(t, onStop(context.Background()))
+	require.Nil(t, onStop(context.Background()))
+
+	os.Setenv("APP_NAME", "")
+	_, err = newConfig()
+	require.NotNil(t, err)
+}
+
+func TestHooksWatchError(t *testing.T) {
+	source := envconfig.MapSource{"NAME": "foo"}
+	store, err := envconfig.NewStore[provideConfig](envconfig.StoreOptions{
+		Options:  envconfig.Options{Source: source},
+		Strategy: envconfig.StopWatching,
+	})
+	require.Nil(t, err)
+
+	reloaded := make(chan struct{}, 1)
+	store.AddCanary(func(provideConfig) error {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+		return errors.New("unreachable")
+	})
+
+	onStart, onStop := store.Hooks(time.Millisecond)
+	require.Nil(t, onStart(context.Background()))
+	<-reloaded
+
+	// Watch stopped on the reload error, which is returned by onStop
+	err = onStop(context.Background())
+	var canaryErr *envconfig.CanaryError
+	require.True(t, errors.As(err, &canaryErr))
+
+	// the store can be watched again
+	require.Nil(t, onStart(context.Background()))
+	require.Nil(t, onStop(context.Background()))
+}
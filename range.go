@@ -0,0 +1,82 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RangeBound is the constraint of the bounds of a Range: integers, floats and time.Duration.
+type RangeBound interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Range is an interval of values from Min to Max included, like a port range, a jitter window
+// or a sampling band. It is parsed from MIN-MAX, like 8000-8080 or 100ms-2s, or from a single value
+// for a range holding only this value. The bounds are parsed like the fields of their type and
+// Min must not be greater than Max.
+type Range[T RangeBound] struct {
+	Min, Max T
+}
+
+// DocValue implements DocValuer.
+func (Range[T]) DocValue() string {
+	return "MIN-MAX"
+}
+
+// Unmarshal implements Unmarshaler.
+func (r *Range[T]) Unmarshal(s string) error {
+	lo := strings.TrimSpace(s)
+	hi := lo
+	if i := rangeSeparator(lo); i >= 0 {
+		lo, hi = lo[:i], lo[i+1:]
+	}
+
+	var res Range[T]
+	if err := parseValue(reflect.ValueOf(&res.Min).Elem(), strings.TrimSpace(lo), &fieldContext{}); err != nil {
+		return fmt.Errorf("invalid range %q: %v", s, err)
+	}
+	if err := parseValue(reflect.ValueOf(&res.Max).Elem(), strings.TrimSpace(hi), &fieldContext{}); err != nil {
+		return fmt.Errorf("invalid range %q: %v", s, err)
+	}
+	if res.Min > res.Max {
+		return fmt.Errorf("invalid range %q, the minimum is greater than the maximum", s)
+	}
+
+	*r = res
+	return nil
+}
+
+// rangeSeparator returns the index of the dash separating the bounds of a range, or -1.
+// The sign of a negative bound and of the exponent of a float isn't a separator.
+func rangeSeparator(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] != '-' {
+			continue
+		}
+		switch s[i-1] {
+		case '-', 'e', 'E':
+			// the sign of the maximum or of an exponent
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// String returns the range as MIN-MAX.
+func (r Range[T]) String() string {
+	return fmt.Sprintf("%v-%v", r.Min, r.Max)
+}
+
+// Contains returns true if v is within the range, bounds included.
+func (r Range[T]) Contains(v T) bool {
+	return v >= r.Min && v <= r.Max
+}
+
+// Span returns the difference between Max and Min.
+func (r Range[T]) Span() T {
+	return r.Max - r.Min
+}
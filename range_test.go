@@ -0,0 +1,60 @@
+package envconfig_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestRange(t *testing.T) {
+	var conf struct {
+		Ports    envconfig.Range[uint16]
+		Jitter   envconfig.Range[time.Duration]
+		Offset   envconfig.Range[int]
+		Sampling *envconfig.Range[float64]
+	}
+
+	source := envconfig.MapSource{
+		"PORTS":    "8000-8080",
+		"JITTER":   "100ms - 2s",
+		"OFFSET":   "-10--5",
+		"SAMPLING": "1e-3-0.5",
+	}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, envconfig.Range[uint16]{Min: 8000, Max: 8080}, conf.Ports)
+	require.Equal(t, envconfig.Range[time.Duration]{Min: 100 * time.Millisecond, Max: 2 * time.Second}, conf.Jitter)
+	require.Equal(t, envconfig.Range[int]{Min: -10, Max: -5}, conf.Offset)
+	require.Equal(t, envconfig.Range[float64]{Min: 0.001, Max: 0.5}, *conf.Sampling)
+
+	require.True(t, conf.Ports.Contains(8080))
+	require.False(t, conf.Ports.Contains(8081))
+	require.Equal(t, uint16(80), conf.Ports.Span())
+	require.Equal(t, "100ms-2s", conf.Jitter.String())
+
+	values, err := envconfig.Marshal(conf)
+	require.Nil(t, err)
+	require.Equal(t, "8000-8080", values["PORTS"])
+	require.Equal(t, "-10--5", values["OFFSET"])
+
+	var single envconfig.Range[int]
+	require.Nil(t, single.Unmarshal("42"))
+	require.Equal(t, envconfig.Range[int]{Min: 42, Max: 42}, single)
+
+	testCases := []struct {
+		in  string
+		err string
+	}{
+		{"20-10", `invalid range "20-10", the minimum is greater than the maximum`},
+		{"10-", `invalid range "10-"`},
+		{"a-b", `invalid range "a-b"`},
+	}
+	for _, tc := range testCases {
+		var r envconfig.Range[uint16]
+		err := r.Unmarshal(tc.in)
+		require.NotNil(t, err, tc.in)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
@@ -0,0 +1,201 @@
+package envconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// FieldOrigin is where the value of a field comes from, see FieldReport.
+type FieldOrigin string
+
+// The origins of the values of the fields.
+const (
+	// FromSource is a value read from the source.
+	FromSource FieldOrigin = "source"
+	// FromFile is a value read from the file named by the key with the _FILE suffix, see Options.FileKeys.
+	FromFile FieldOrigin = "file"
+	// FromDefault is the default value of the tag.
+	FromDefault FieldOrigin = "default"
+	// FromExisting is the value the field had before Init, see Options.ExistingAsDefaults.
+	FromExisting FieldOrigin = "existing"
+	// Unset is an optional field without value.
+	Unset FieldOrigin = "unset"
+)
+
+// FieldReport describes how the value of a field was resolved by Init.
+type FieldReport struct {
+	// Path is the path of the field in the struct, like Log.Level.
+	Path string `json:"path"`
+	// Key is the key which provided the value, empty for a default or existing value.
+	Key    string      `json:"key,omitempty"`
+	Origin FieldOrigin `json:"origin"`
+	// Source is the name of the source which provided the value, the one of the member of a Chain
+	// rather than the Chain itself.
+	Source string `json:"source,omitempty"`
+	// Value is the value as read, before it is parsed. It is *** or redacted by the redact option
+	// for a secret field or a value read from a file, and empty for an existing value.
+	Value  string `json:"value"`
+	Secret bool   `json:"secret,omitempty"`
+	// Optional is true if the field could be left unset, because of the optional option or an existing value.
+	Optional bool `json:"optional,omitempty"`
+	// Keys are the keys looked up in the source, in order.
+	Keys []string `json:"keys"`
+}
+
+// Report describes how a configuration was read by Init, to find out why a field has a value.
+type Report struct {
+	// Loaded is when the configuration was read.
+	Loaded time.Time `json:"loaded"`
+	// Checksum is the SHA-256 of the values of the configuration, in hexadecimal, so that the instances
+	// running with different configurations can be spotted. It is empty if Init failed.
+	// The values of the secret fields are left out, so that they can't be guessed from the checksum.
+	Checksum string `json:"checksum,omitempty"`
+	// Resolved is the number of fields whose value was read from the source or a file,
+	// and Defaults the number of fields with their default value.
+	Resolved int `json:"resolved"`
+	Defaults int `json:"defaults"`
+	// Fields are the fields read without error, in the order of the struct.
+	Fields []FieldReport `json:"fields"`
+}
+
+// InitWithReport is like InitWithOptions, and also returns the report of how each field was read.
+// The report is returned even if Init fails, with the fields read without error.
+func InitWithReport(conf interface{}, opts Options) (*Report, error) {
+	report := &Report{Loaded: time.Now()}
+	_, err := initWithChecks(context.Background(), conf, opts, report)
+	return report, err
+}
+
+// Field returns the report of the field at path, like Log.Level, and false if the field wasn't read.
+func (r *Report) Field(path string) (FieldReport, bool) {
+	for _, f := range r.Fields {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return FieldReport{}, false
+}
+
+// complete computes the summary of the report of the configuration conf, read with opts.
+func (r *Report) complete(conf interface{}, opts Options) {
+	r.Resolved, r.Defaults = 0, 0
+	secrets := make(map[string]bool)
+	for _, f := range r.Fields {
+		if f.Secret {
+			secrets[f.Path] = true
+		}
+		switch f.Origin {
+		case FromSource, FromFile:
+			r.Resolved++
+		case FromDefault:
+			r.Defaults++
+		}
+	}
+
+	fields, err := marshalFields(conf, opts)
+	if err != nil {
+		return
+	}
+	keys := make([]string, 0, len(fields))
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.key)
+		if !f.secret && !secrets[f.path] {
+			values[f.key] = f.value
+		}
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key + "=" + values[key] + "\x00"))
+	}
+	r.Checksum = hex.EncodeToString(h.Sum(nil))
+}
+
+// LastReport holds the report of the last successful Init. Its Set method is an Options.OnLoad callback,
+// and it implements expvar.Var so that the report can be published with expvar:
+//
+//	var last envconfig.LastReport
+//	expvar.Publish("config", &last)
+//	store, err := envconfig.NewStore[Config](envconfig.StoreOptions{
+//		Options: envconfig.Options{OnLoad: last.Set},
+//	})
+//
+// The zero value is ready to use.
+type LastReport struct {
+	report atomic.Pointer[Report]
+}
+
+// Set stores the report r.
+func (l *LastReport) Set(r *Report) {
+	l.report.Store(r)
+}
+
+// Get returns the last report, nil if there is none yet.
+func (l *LastReport) Get() *Report {
+	return l.report.Load()
+}
+
+// String implements expvar.Var, it returns the last report in JSON, null if there is none yet.
+func (l *LastReport) String() string {
+	data, err := json.Marshal(l.Get())
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// reportField adds the field to the report of the state, if any, and traces and logs its resolution.
+// str is the value read and key the key which provided it.
+func (ctx *fieldContext) reportField(origin FieldOrigin, key, str string) {
+	if ctx.report == nil && ctx.opts.Trace == nil && ctx.opts.Logger == nil {
+		return
+	}
+
+	// the files named by the _FILE keys hold secrets
+	f := FieldReport{Path: ctx.path, Key: key, Origin: origin, Secret: ctx.secret || origin == FromFile, Optional: ctx.optional}
+	if (origin == FromSource || origin == FromFile) && ctx.origin != nil {
+		f.Source = sourceName(ctx.origin)
+	}
+	if origin != FromExisting {
+		f.Value = maskValue(f.Secret, ctx.redact, str)
+	}
+
+	if ctx.report != nil {
+		f.Keys = makeAllPossibleKeys(ctx)
+		ctx.report.Fields = append(ctx.report.Fields, f)
+	}
+	if ctx.opts.Trace != nil {
+		ctx.opts.Trace(TraceEvent{
+			Kind:   TraceResolve,
+			Field:  f.Path,
+			Key:    f.Key,
+			Source: f.Source,
+			Origin: f.Origin,
+			Value:  f.Value,
+			Secret: f.Secret,
+		})
+	}
+	if ctx.opts.Logger != nil {
+		ctx.logField(f)
+	}
+}
+
+// maskValue returns the value, or *** or the value redacted by the policy if it is secret.
+// An empty value is not masked.
+func maskValue(secret bool, policy, value string) string {
+	switch {
+	case !secret || value == "":
+		return value
+	case policy != "":
+		return redactValue(policy, value)
+	default:
+		return secretMask
+	}
+}
@@ -0,0 +1,125 @@
+package envconfig_test
+
+import (
+	"expvar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestInitWithReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	require.Nil(t, os.WriteFile(path, []byte("filetoken\n"), 0600))
+
+	var conf struct {
+		Name     string
+		Region   string
+		Timeout  string `envconfig:"default=10s"`
+		Password string `envconfig:",secret"`
+		Token    string
+		Proxy    string `envconfig:"optional"`
+		Port     int
+	}
+	conf.Port = 8080
+
+	env := envconfig.MapSource{"NAME": "foo", "PASSWORD": "foobar", "TOKEN_FILE": path}
+	remote := envconfig.NewCache(envconfig.MapSource{"REGION": "eu-west-1"}, envconfig.CacheOptions{})
+
+	report, err := envconfig.InitWithReport(&conf, envconfig.Options{
+		Source:             envconfig.Chain{env, remote},
+		FileKeys:           true,
+		ExistingAsDefaults: true,
+	})
+	require.Nil(t, err)
+	require.False(t, report.Loaded.IsZero())
+	require.Equal(t, []envconfig.FieldReport{
+		{Path: "Name", Key: "NAME", Origin: envconfig.FromSource, Source: "envconfig.MapSource", Value: "foo", Keys: []string{"NAME", "name"}},
+		{Path: "Region", Key: "REGION", Origin: envconfig.FromSource, Source: "cache(envconfig.MapSource)", Value: "eu-west-1", Keys: []string{"REGION", "region"}},
+		{Path: "Timeout", Origin: envconfig.FromDefault, Value: "10s", Keys: []string{"TIMEOUT", "timeout"}},
+		{Path: "Password", Key: "PASSWORD", Origin: envconfig.FromSource, Source: "envconfig.MapSource", Value: "***", Secret: true, Keys: []string{"PASSWORD", "password"}},
+		{Path: "Token", Key: "TOKEN_FILE", Origin: envconfig.FromFile, Source: "envconfig.MapSource", Value: "***", Secret: true, Keys: []string{"TOKEN", "token"}},
+		{Path: "Proxy", Origin: envconfig.Unset, Optional: true, Keys: []string{"PROXY", "proxy"}},
+		{Path: "Port", Origin: envconfig.FromExisting, Optional: true, Keys: []string{"PORT", "port"}},
+	}, report.Fields)
+
+	f, ok := report.Field("Proxy")
+	require.True(t, ok)
+	require.Equal(t, envconfig.Unset, f.Origin)
+	_, ok = report.Field("Foo")
+	require.False(t, ok)
+
+	delete(env, "NAME")
+	report, err = envconfig.InitWithReport(&conf, envconfig.Options{Source: env})
+	require.NotNil(t, err)
+	require.Equal(t, "Timeout", report.Fields[0].Path)
+}
+
+func TestReportChecksumSecrets(t *testing.T) {
+	var conf struct {
+		Name     string
+		Password string `envconfig:",secret"`
+		Token    string
+	}
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.Nil(t, os.WriteFile(path, []byte("filetoken\n"), 0600))
+	env := envconfig.MapSource{"NAME": "foo", "PASSWORD": "foobar", "TOKEN_FILE": path}
+	report, err := envconfig.InitWithReport(&conf, envconfig.Options{Source: env, FileKeys: true})
+	require.Nil(t, err)
+
+	// the checksum doesn't change with the secrets, so they can't be guessed from it
+	env["PASSWORD"] = "barbaz"
+	require.Nil(t, os.WriteFile(path, []byte("othertoken\n"), 0600))
+	other, err := envconfig.InitWithReport(&conf, envconfig.Options{Source: env, FileKeys: true})
+	require.Nil(t, err)
+	require.Equal(t, report.Checksum, other.Checksum)
+
+	env["NAME"] = "bar"
+	other, err = envconfig.InitWithReport(&conf, envconfig.Options{Source: env, FileKeys: true})
+	require.Nil(t, err)
+	require.NotEqual(t, report.Checksum, other.Checksum)
+}
+
+func TestOnLoad(t *testing.T) {
+	type config struct {
+		Name    string
+		Timeout string `envconfig:"default=10s"`
+		Retries int    `envconfig:"default=3"`
+	}
+
+	var last envconfig.LastReport
+	require.Nil(t, last.Get())
+	require.Equal(t, "null", last.String())
+	expvar.Publish("envconfig_test_config", &last)
+
+	source := envconfig.MapSource{"NAME": "foo"}
+	store, err := envconfig.NewStore[config](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: source, OnLoad: last.Set},
+	})
+	require.Nil(t, err)
+
+	report := last.Get()
+	require.NotNil(t, report)
+	require.Equal(t, 2, report.Defaults)
+	require.Len(t, report.Checksum, 64)
+	require.Contains(t, expvar.Get("envconfig_test_config").String(), `"defaults":2`)
+
+	// the checksum only changes with the configuration
+	require.Nil(t, store.Reload())
+	require.NotSame(t, report, last.Get())
+	require.Equal(t, report.Checksum, last.Get().Checksum)
+
+	source["RETRIES"] = "5"
+	require.Nil(t, store.Reload())
+	require.NotEqual(t, report.Checksum, last.Get().Checksum)
+	require.Equal(t, 1, last.Get().Defaults)
+
+	// a failed load isn't reported
+	previous := last.Get()
+	delete(source, "NAME")
+	require.NotNil(t, store.Reload())
+	require.Same(t, previous, last.Get())
+}
@@ -0,0 +1,74 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var stringMapType = reflect.TypeOf(map[string]string(nil))
+
+// restField is a field with the rest tag, it receives the variables of its struct which
+// are not consumed by other fields.
+type restField struct {
+	value reflect.Value
+	// name is the name of the struct containing the field.
+	name string
+}
+
+// keyPrefixes returns the possible prefixes of the keys of the fields of a struct.
+func keyPrefixes(name string) []string {
+	var res []string
+	for _, key := range makeAllPossibleKeys(&fieldContext{name: name}) {
+		if key == strings.ToUpper(key) {
+			res = append(res, key+"_")
+		}
+	}
+	return res
+}
+
+// trimKeyPrefix returns the key without the first prefix matching, case insensitively.
+func trimKeyPrefix(key string, prefixes []string) (string, bool) {
+	upper := strings.ToUpper(key)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return key[len(prefix):], true
+		}
+	}
+	return key, false
+}
+
+// fillRestFields fills the rest fields with the variables not looked up, the deepest fields first.
+func fillRestFields(ctx *fieldContext) error {
+	sort.SliceStable(ctx.rest, func(i, j int) bool {
+		return len(ctx.rest[i].name) > len(ctx.rest[j].name)
+	})
+
+	for _, f := range ctx.rest {
+		if f.name == "" {
+			return fmt.Errorf("envconfig: rest field requires a prefix")
+		}
+
+		prefixes := keyPrefixes(f.name)
+		m := make(map[string]string)
+
+		for _, name := range ctx.sourceKeys() {
+			if _, ok := ctx.keys[name]; ok {
+				continue
+			}
+			if key, ok := trimKeyPrefix(name, prefixes); ok {
+				value, _, err := ctx.lookup(name, "")
+				if err != nil {
+					return fmt.Errorf("envconfig: unable to look up %s: %w", name, err)
+				}
+				m[key] = value
+				ctx.keys[name] = struct{}{}
+			}
+		}
+
+		f.value.Set(reflect.ValueOf(m))
+	}
+
+	return nil
+}
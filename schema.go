@@ -0,0 +1,183 @@
+package envconfig
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsonSchema is the subset of JSON Schema used by WriteJSONSchema.
+type jsonSchema struct {
+	Schema            string                 `json:"$schema,omitempty"`
+	Type              string                 `json:"type,omitempty"`
+	Description       string                 `json:"description,omitempty"`
+	Default           string                 `json:"default,omitempty"`
+	Examples          []string               `json:"examples,omitempty"`
+	Enum              []string               `json:"enum,omitempty"`
+	Pattern           string                 `json:"pattern,omitempty"`
+	WriteOnly         bool                   `json:"writeOnly,omitempty"`
+	Properties        map[string]*jsonSchema `json:"properties,omitempty"`
+	PatternProperties map[string]*jsonSchema `json:"patternProperties,omitempty"`
+	Required          []string               `json:"required,omitempty"`
+	AnyOf             []*jsonSchema          `json:"anyOf,omitempty"`
+	AllOf             []*jsonSchema          `json:"allOf,omitempty"`
+}
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Patterns of the values in the JSON schema.
+const (
+	intPattern             = `^[-+]?[0-9]+$`
+	uintPattern            = `^[0-9]+$`
+	durationPattern        = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+	iso8601DurationPattern = `^-?P`
+	listenerPattern        = `^(tcp|tcp4|tcp6|unix|fd|systemd)://`
+)
+
+var (
+	boolValues     = []string{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"}
+	sameSiteValues = []string{"default", "lax", "strict", "none"}
+
+	countryCodeType  = reflect.TypeOf(CountryCode(""))
+	currencyCodeType = reflect.TypeOf(CurrencyCode(""))
+	sameSiteType     = reflect.TypeOf(SameSite(0))
+	listenerType     = reflect.TypeOf(Listener{})
+)
+
+// WriteJSONSchema writes a JSON Schema of the environment expected by InitWithOptions for the conf object and opts to w.
+// conf must be a pointer.
+//
+// The schema describes an object whose properties are the keys, with their description, default value and
+// the format of their value as a pattern or an enum. Enums contain the canonical values, even when
+// the parsing is case insensitive.
+// Secret keys are marked as write only. Groups are described with anyOf.
+func WriteJSONSchema(w io.Writer, conf interface{}, opts Options) error {
+	fields, err := describe(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	schema := &jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+	}
+
+	// groups of each struct, by path of the struct and in order of appearance
+	var structs []string
+	groups := make(map[string][]string)
+	groupKeys := make(map[string]map[string][]string)
+
+	for _, f := range fields {
+		if f.rest {
+			if schema.PatternProperties == nil {
+				schema.PatternProperties = make(map[string]*jsonSchema)
+			}
+			pattern := "^" + regexp.QuoteMeta(strings.TrimSuffix(f.key, "*"))
+			schema.PatternProperties[pattern] = &jsonSchema{Type: "string", Description: f.description}
+			continue
+		}
+
+		prop := valueSchema(f.typ, f.durationFormat, f.validators)
+		prop.Description = f.description
+		prop.WriteOnly = f.secret
+		prop.Default = f.defaultVal
+		if f.example != "" {
+			prop.Examples = []string{f.example}
+		}
+		schema.Properties[f.key] = prop
+
+		switch {
+		case f.optional || f.defaultVal != "":
+		case f.group != "":
+			structPath := ""
+			if i := strings.LastIndexByte(f.path, '.'); i >= 0 {
+				structPath = f.path[:i]
+			}
+			if _, ok := groupKeys[structPath]; !ok {
+				structs = append(structs, structPath)
+				groupKeys[structPath] = make(map[string][]string)
+			}
+			if _, ok := groupKeys[structPath][f.group]; !ok {
+				groups[structPath] = append(groups[structPath], f.group)
+			}
+			groupKeys[structPath][f.group] = append(groupKeys[structPath][f.group], f.key)
+		default:
+			schema.Required = append(schema.Required, f.key)
+		}
+	}
+
+	for _, structPath := range structs {
+		alternatives := &jsonSchema{}
+		for _, group := range groups[structPath] {
+			alternatives.AnyOf = append(alternatives.AnyOf, &jsonSchema{Required: groupKeys[structPath][group]})
+		}
+		schema.AllOf = append(schema.AllOf, alternatives)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(schema)
+}
+
+// valueSchema returns the schema of the value of a key of type typ.
+func valueSchema(typ reflect.Type, durationFormat string, validators []string) *jsonSchema {
+	res := &jsonSchema{Type: "string"}
+
+	switch {
+	case typ == countryCodeType:
+		res.Enum = sortedCodes(isoCountryCodes)
+		return res
+	case typ == currencyCodeType:
+		res.Enum = sortedCodes(isoCurrencyCodes)
+		return res
+	case typ == sameSiteType:
+		res.Enum = sameSiteValues
+		return res
+	case typ == listenerType:
+		res.Pattern = listenerPattern
+		return res
+	case isUnmarshaler(typ), typ == httpHeaderType:
+		return res
+	case isDurationField(typ):
+		res.Pattern = durationPattern
+		if durationFormat == durationFormatISO8601 {
+			res.Pattern = iso8601DurationPattern
+		}
+		return res
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		res.Enum = boolValues
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		res.Pattern = intPattern
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		res.Pattern = uintPattern
+	case reflect.String:
+		for _, name := range validators {
+			switch name {
+			case "iso3166":
+				res.Enum = sortedCodes(isoCountryCodes)
+			case "iso4217":
+				res.Enum = sortedCodes(isoCurrencyCodes)
+			}
+		}
+	}
+
+	return res
+}
+
+func sortedCodes(codes map[string]struct{}) []string {
+	res := make([]string, 0, len(codes))
+	for code := range codes {
+		res = append(res, code)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,47 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestWriteJSONSchema(t *testing.T) {
+	var conf struct {
+		Port     uint16        `envconfig:"default=8080,desc=Port of the HTTP server"`
+		Debug    bool          `envconfig:"optional"`
+		Timeout  time.Duration `envconfig:"duration=iso8601"`
+		SameSite envconfig.SameSite
+		Auth     struct {
+			Password string `envconfig:",secret,group=basic"`
+			Token    string `envconfig:"group=token"`
+		}
+		Extra map[string]string `envconfig:",rest"`
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteJSONSchema(&buf, &conf, envconfig.Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.JSONEq(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "APP_AUTH_PASSWORD": {"type": "string", "writeOnly": true},
+    "APP_AUTH_TOKEN": {"type": "string"},
+    "APP_DEBUG": {"type": "string", "enum": ["1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"]},
+    "APP_PORT": {"type": "string", "description": "Port of the HTTP server", "default": "8080", "pattern": "^[0-9]+$"},
+    "APP_SAMESITE": {"type": "string", "enum": ["default", "lax", "strict", "none"]},
+    "APP_TIMEOUT": {"type": "string", "pattern": "^-?P"}
+  },
+  "patternProperties": {
+    "^APP_": {"type": "string"}
+  },
+  "required": ["APP_TIMEOUT", "APP_SAMESITE"],
+  "allOf": [
+    {"anyOf": [{"required": ["APP_AUTH_PASSWORD"]}, {"required": ["APP_AUTH_TOKEN"]}]}
+  ]
+}`, buf.String())
+}
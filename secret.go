@@ -0,0 +1,88 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// secretMask replaces the value of a Secret when it is printed or encoded.
+const secretMask = "***"
+
+// Secret holds a value which is never printed: String, Format, MarshalJSON and MarshalText
+// all return *** so that the value doesn't leak into logs or dumps of the configuration.
+// The value is only returned by Value.
+//
+// A Secret field is read like a field of type T and behaves as if it had the secret tag:
+//
+//	var conf struct {
+//		DBPassword envconfig.Secret[string]
+//	}
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding v.
+func NewSecret[T any](v T) Secret[T] {
+	return Secret[T]{value: v}
+}
+
+// Value returns the real value of the secret.
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// String implements fmt.Stringer, it returns ***.
+func (s Secret[T]) String() string {
+	return secretMask
+}
+
+// GoString implements fmt.GoStringer, it returns ***.
+func (s Secret[T]) GoString() string {
+	return secretMask
+}
+
+// Format implements fmt.Formatter, the value is replaced by *** whatever the verb.
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	f.Write([]byte(secretMask))
+}
+
+// MarshalJSON implements json.Marshaler, it returns "***".
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + secretMask + `"`), nil
+}
+
+// MarshalText implements encoding.TextMarshaler, it returns ***.
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(secretMask), nil
+}
+
+func (s *Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// secretWrapper is implemented by the pointers to a Secret.
+type secretWrapper interface {
+	secretValue() reflect.Value
+}
+
+var secretWrapperType = reflect.TypeOf((*secretWrapper)(nil)).Elem()
+
+// isSecretType returns true if t is a Secret.
+func isSecretType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(secretWrapperType)
+}
+
+// secretElem returns the type of the value of the Secret t.
+func secretElem(t reflect.Type) reflect.Type {
+	return reflect.New(t).Interface().(secretWrapper).secretValue().Type()
+}
+
+// unwrapSecret returns the value of the Secret v, which can be set if v is addressable.
+func unwrapSecret(v reflect.Value) reflect.Value {
+	if !v.CanAddr() {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	return v.Addr().Interface().(secretWrapper).secretValue()
+}
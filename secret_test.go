@@ -0,0 +1,62 @@
+package envconfig_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestSecret(t *testing.T) {
+	var conf struct {
+		Password envconfig.Secret[string]
+		Timeout  *envconfig.Secret[time.Duration]
+		Ports    envconfig.Secret[[]int] `envconfig:"optional"`
+	}
+
+	source := envconfig.MapSource{"PASSWORD": "foobar", "TIMEOUT": "10s"}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foobar", conf.Password.Value())
+	require.Equal(t, 10*time.Second, conf.Timeout.Value())
+	require.Nil(t, conf.Ports.Value())
+
+	require.Equal(t, "***", conf.Password.String())
+	require.Equal(t, "*** ***", fmt.Sprintf("%v %d", conf.Password, *conf.Timeout))
+	require.Equal(t, "{Password:*** Timeout:***}", fmt.Sprintf("%+v", struct {
+		Password envconfig.Secret[string]
+		Timeout  envconfig.Secret[time.Duration]
+	}{conf.Password, *conf.Timeout}))
+
+	data, err := json.Marshal(conf)
+	require.Nil(t, err)
+	require.Equal(t, `{"Password":"***","Timeout":"***","Ports":"***"}`, string(data))
+
+	values, err := envconfig.Marshal(conf)
+	require.Nil(t, err)
+	require.Equal(t, "foobar", values["PASSWORD"])
+	require.Equal(t, "10s", values["TIMEOUT"])
+
+	source["PASSWORD"] = ""
+	source["TIMEOUT"] = "tensecs"
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.NotNil(t, err)
+	require.NotContains(t, err.Error(), "tensecs")
+}
+
+func TestSecretNew(t *testing.T) {
+	s := envconfig.NewSecret(42)
+	require.Equal(t, 42, s.Value())
+	require.Equal(t, "***", fmt.Sprintf("%#v", s))
+
+	old := struct{ Token envconfig.Secret[string] }{envconfig.NewSecret("a")}
+	new := struct{ Token envconfig.Secret[string] }{envconfig.NewSecret("b")}
+	changes, err := envconfig.Diff(old, new)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	require.True(t, changes[0].Secret)
+	require.NotEqual(t, "a", changes[0].Old)
+}
@@ -0,0 +1,256 @@
+// Package secretsmanager provides an envconfig source reading the values from AWS Secrets Manager.
+//
+// By default the value of a key is the field of the same name of the JSON secret Config.SecretID:
+//
+//	source, err := secretsmanager.New(secretsmanager.Config{SecretID: "myapp/config"})
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+//
+// A field can be mapped to an individual secret, or to a field of another JSON secret, with the secretsmanager tag:
+//
+//	var conf struct {
+//		DBPassword string `secretsmanager:"prod/db#password"`
+//		APIKey     string `secretsmanager:"prod/api-key"`
+//	}
+//
+// The region and the credentials are read from the standard AWS environment variables by default,
+// which are set in Lambda functions. The other providers of credentials, like the container credentials
+// endpoint of ECS tasks or the instance profiles of EC2, aren't supported: get the credentials with the AWS SDK
+// and set them in the Config. The secrets are cached by the source, so that a Lambda function
+// keeping the source in a global variable doesn't fetch them again on every invocation.
+//
+// The source only talks to the HTTP API of Secrets Manager, it doesn't depend on the AWS SDK.
+package secretsmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrischmann/envconfig/internal/sigv4"
+)
+
+// Config is the configuration of a Source.
+type Config struct {
+	// Region is the AWS region, AWS_REGION or AWS_DEFAULT_REGION by default.
+	Region string
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials, AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN by default.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint is the URL of the API, https://secretsmanager.REGION.amazonaws.com by default.
+	Endpoint string
+
+	// SecretID is the name or the ARN of the JSON secret holding the keys of the fields without a secretsmanager tag.
+	SecretID string
+
+	// CacheTTL is how long a secret is cached. The secrets are cached for the lifetime of the source if it is zero.
+	CacheTTL time.Duration
+
+	// HTTPClient is the client used to talk to Secrets Manager, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// Source reads the values from Secrets Manager. It implements envconfig.Source, envconfig.FieldSource and
+// envconfig.ContextSource.
+type Source struct {
+	cfg Config
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// cachedSecret is a secret fetched from Secrets Manager, found is false if it doesn't exist.
+type cachedSecret struct {
+	value   string
+	found   bool
+	fetched time.Time
+}
+
+// New returns a new source.
+func New(cfg Config) (*Source, error) {
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("secretsmanager: no region")
+	}
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("secretsmanager: no credentials, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://secretsmanager." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Source{cfg: cfg, cache: make(map[string]cachedSecret)}, nil
+}
+
+// String returns the name of the source used in errors.
+func (s *Source) String() string {
+	return "secretsmanager " + s.cfg.Region
+}
+
+// Lookup implements envconfig.Source, it reads the field key of the secret Config.SecretID.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, "")
+}
+
+// LookupField implements envconfig.FieldSource, it reads the secret of the secretsmanager tag if there is one.
+func (s *Source) LookupField(key string, tag reflect.StructTag) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, tag)
+}
+
+// LookupContext implements envconfig.ContextSource, it is LookupField aborting the request when ctx is done.
+func (s *Source) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	mapping, ok := tag.Lookup("secretsmanager")
+	if !ok {
+		if s.cfg.SecretID == "" {
+			return "", false, nil
+		}
+		return s.read(ctx, s.cfg.SecretID, key)
+	}
+
+	if i := strings.IndexByte(mapping, '#'); i >= 0 {
+		return s.read(ctx, mapping[:i], mapping[i+1:])
+	}
+	return s.get(ctx, mapping)
+}
+
+// Invalidate removes all the secrets from the cache, so that they are fetched again.
+func (s *Source) Invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedSecret)
+	s.mu.Unlock()
+}
+
+// read returns the field of the JSON secret id.
+func (s *Source) read(ctx context.Context, id, field string) (string, bool, error) {
+	secret, found, err := s.get(ctx, id)
+	if err != nil || !found {
+		return "", false, err
+	}
+
+	var data map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(secret))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return "", false, fmt.Errorf("secretsmanager: secret %s is not a JSON object", id)
+	}
+
+	v, ok := data[field]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	if str, ok := v.(string); ok {
+		return str, true, nil
+	}
+
+	// numbers and booleans are formatted as JSON, which envconfig parses
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
+
+// get returns the value of the secret id, from the cache if possible.
+func (s *Source) get(ctx context.Context, id string) (string, bool, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[id]
+	s.mu.Unlock()
+
+	if ok && (s.cfg.CacheTTL == 0 || time.Since(cached.fetched) < s.cfg.CacheTTL) {
+		return cached.value, cached.found, nil
+	}
+
+	value, found, err := s.fetch(ctx, id)
+	if err != nil {
+		return "", false, err
+	}
+
+	s.mu.Lock()
+	s.cache[id] = cachedSecret{value: value, found: found, fetched: time.Now()}
+	s.mu.Unlock()
+
+	return value, found, nil
+}
+
+// fetch calls GetSecretValue. It returns false if the secret doesn't exist.
+func (s *Source) fetch(ctx context.Context, id string) (string, bool, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.cfg.Endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	sigv4.Sign(req, body, s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.cfg.Region, "secretsmanager", time.Now())
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("secretsmanager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", false, fmt.Errorf("secretsmanager: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Type == "" {
+			return "", false, fmt.Errorf("secretsmanager: GetSecretValue %s: %s", id, resp.Status)
+		}
+
+		// the type may be prefixed with a namespace, like com.amazonaws.secretsmanager#ResourceNotFoundException
+		typ := apiErr.Type[strings.LastIndexByte(apiErr.Type, '#')+1:]
+		if typ == "ResourceNotFoundException" {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("secretsmanager: GetSecretValue %s: %s: %s", id, typ, apiErr.Message)
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return "", false, fmt.Errorf("secretsmanager: invalid response: %w", err)
+	}
+	if secret.SecretString != nil {
+		return *secret.SecretString, true, nil
+	}
+	return string(secret.SecretBinary), true, nil
+}
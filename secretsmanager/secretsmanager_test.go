@@ -0,0 +1,102 @@
+package secretsmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func newServer(t *testing.T, calls *int) *httptest.Server {
+	secrets := map[string]string{
+		"myapp/config": `{"NAME": "foo", "PORT": 8080}`,
+		"prod/db":      `{"password": "secret"}`,
+		"prod/api-key": "abcdef",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		require.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "UnrecognizedClientException", "message": "invalid token"})
+			return
+		}
+
+		var body map[string]string
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+
+		secret, ok := secrets[body["SecretId"]]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"__type": "ResourceNotFoundException", "message": "not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": secret})
+	}))
+}
+
+func TestSource(t *testing.T) {
+	var calls int
+	srv := newServer(t, &calls)
+	defer srv.Close()
+
+	source, err := New(Config{
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        srv.URL,
+		SecretID:        "myapp/config",
+	})
+	require.Nil(t, err)
+
+	var conf struct {
+		Name       string
+		Port       int
+		DBPassword string `secretsmanager:"prod/db#password"`
+		APIKey     string `secretsmanager:"prod/api-key"`
+		Debug      bool   `envconfig:"optional"`
+		Missing    string `envconfig:"optional" secretsmanager:"prod/missing"`
+	}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, 8080, conf.Port)
+	require.Equal(t, "secret", conf.DBPassword)
+	require.Equal(t, "abcdef", conf.APIKey)
+	require.Equal(t, 4, calls)
+
+	// the secrets are cached
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, 4, calls)
+
+	source.Invalidate()
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, 8, calls)
+}
+
+func TestSourceErrors(t *testing.T) {
+	var calls int
+	srv := newServer(t, &calls)
+	defer srv.Close()
+
+	source, err := New(Config{
+		Region:          "eu-west-1",
+		AccessKeyID:     "wrong",
+		SecretAccessKey: "secret",
+		Endpoint:        srv.URL,
+		SecretID:        "myapp/config",
+	})
+	require.Nil(t, err)
+
+	_, _, err = source.Lookup("NAME")
+	require.Equal(t, "secretsmanager: GetSecretValue myapp/config: UnrecognizedClientException: invalid token", err.Error())
+}
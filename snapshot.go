@@ -0,0 +1,168 @@
+package envconfig
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// snapshotVersion is the version of the snapshot format.
+const snapshotVersion = 1
+
+// snapshot is the content of a snapshot file. Exactly one of Vars and Encrypted is set.
+type snapshot struct {
+	Version int               `json:"version"`
+	Vars    map[string]string `json:"vars,omitempty"`
+	// Encrypted are the encoded vars sealed with AES-GCM, prefixed by the nonce.
+	Encrypted []byte `json:"encrypted,omitempty"`
+}
+
+// SaveSnapshot saves the values of the conf object to the file at path, so that a service can boot
+// from its last known good configuration with InitFromSnapshot. conf must be a pointer.
+//
+// The file is replaced atomically and is only readable by its owner, but it isn't encrypted:
+// use WriteSnapshot with a key if it contains secrets.
+func SaveSnapshot(path string, conf interface{}) error {
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, conf, Options{}, nil); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// InitFromSnapshot reads the configuration from the snapshot file at path, saved with SaveSnapshot,
+// and populates the conf object. conf must be a pointer.
+func InitFromSnapshot(path string, conf interface{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	source, err := ReadSnapshot(f, nil)
+	if err != nil {
+		return err
+	}
+
+	return InitWithOptions(conf, Options{Source: source})
+}
+
+// WriteSnapshot writes a snapshot of the values of the conf object with opts to w, see Marshal.
+// conf must be a pointer.
+//
+// If key is not nil, the snapshot is encrypted with AES-GCM and key must be 16, 24 or 32 bytes long.
+func WriteSnapshot(w io.Writer, conf interface{}, opts Options, key []byte) error {
+	vars, err := MarshalWithOptions(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	s := snapshot{Version: snapshotVersion, Vars: vars}
+
+	if key != nil {
+		plaintext, err := json.Marshal(vars)
+		if err != nil {
+			return err
+		}
+
+		aead, err := newSnapshotAEAD(key)
+		if err != nil {
+			return err
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+
+		s.Vars = nil
+		s.Encrypted = aead.Seal(nonce, nonce, plaintext, nil)
+	}
+
+	return json.NewEncoder(w).Encode(s)
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot, encrypted with key if it is not nil.
+// Use the returned source with InitWithOptions and the options used to write the snapshot:
+//
+//	source, err := envconfig.ReadSnapshot(f, key)
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+func ReadSnapshot(r io.Reader, key []byte) (MapSource, error) {
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("envconfig: invalid snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("envconfig: unsupported snapshot version %d", s.Version)
+	}
+
+	switch {
+	case key == nil && s.Encrypted != nil:
+		return nil, errors.New("envconfig: snapshot is encrypted")
+	case key != nil && s.Encrypted == nil:
+		return nil, errors.New("envconfig: snapshot is not encrypted")
+	case key == nil:
+		return MapSource(s.Vars), nil
+	}
+
+	aead, err := newSnapshotAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Encrypted) < aead.NonceSize() {
+		return nil, errors.New("envconfig: invalid snapshot: encrypted data is too short")
+	}
+
+	nonce, ciphertext := s.Encrypted[:aead.NonceSize()], s.Encrypted[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("envconfig: unable to decrypt snapshot, wrong key or corrupted file")
+	}
+
+	var vars map[string]string
+	if err := json.Unmarshal(plaintext, &vars); err != nil {
+		return nil, fmt.Errorf("envconfig: invalid snapshot: %w", err)
+	}
+
+	return MapSource(vars), nil
+}
+
+func newSnapshotAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envconfig: invalid snapshot key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path, then renames it to path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
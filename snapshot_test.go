@@ -0,0 +1,55 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type snapshotConfig struct {
+	Name    string
+	Timeout time.Duration
+	Ports   []int
+}
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	conf := snapshotConfig{Name: "foobar", Timeout: time.Minute, Ports: []int{80, 443}}
+	err := envconfig.SaveSnapshot(path, &conf)
+	require.Nil(t, err)
+
+	var conf2 snapshotConfig
+	err = envconfig.InitFromSnapshot(path, &conf2)
+	require.Nil(t, err)
+	require.Equal(t, conf, conf2)
+}
+
+func TestEncryptedSnapshot(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	conf := snapshotConfig{Name: "foobar", Timeout: time.Minute, Ports: []int{80}}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteSnapshot(&buf, &conf, envconfig.Options{Prefix: "APP"}, key)
+	require.Nil(t, err)
+	require.NotContains(t, buf.String(), "foobar")
+
+	_, err = envconfig.ReadSnapshot(bytes.NewReader(buf.Bytes()), nil)
+	require.Equal(t, "envconfig: snapshot is encrypted", err.Error())
+
+	_, err = envconfig.ReadSnapshot(bytes.NewReader(buf.Bytes()), bytes.Repeat([]byte{2}, 32))
+	require.Equal(t, "envconfig: unable to decrypt snapshot, wrong key or corrupted file", err.Error())
+
+	source, err := envconfig.ReadSnapshot(bytes.NewReader(buf.Bytes()), key)
+	require.Nil(t, err)
+
+	var conf2 snapshotConfig
+	err = envconfig.InitWithOptions(&conf2, envconfig.Options{Prefix: "APP", Source: source})
+	require.Nil(t, err)
+	require.Equal(t, conf, conf2)
+}
@@ -0,0 +1,61 @@
+package sops
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/vrischmann/envconfig/internal/awskms"
+)
+
+// KMSConfig is the configuration of the master key of AWS KMS.
+type KMSConfig struct {
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials, AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN by default.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Endpoint is the URL of the API of KMS, https://kms.REGION.amazonaws.com by default with the region of the key.
+	Endpoint string
+
+	// HTTPClient is the client used to talk to KMS, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// KMS is the master key of AWS KMS, it decrypts the data keys of type kms with the Decrypt API.
+// The roles and the AWS profiles of the keys aren't supported, the credentials must give access to the keys.
+type KMS struct {
+	client *awskms.Client
+}
+
+// NewKMS returns the master key of AWS KMS.
+func NewKMS(cfg KMSConfig) (*KMS, error) {
+	client, err := awskms.New(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken, cfg.Endpoint, cfg.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+
+	return &KMS{client: client}, nil
+}
+
+// Type implements MasterKey.
+func (k *KMS) Type() string {
+	return "kms"
+}
+
+// Decrypt implements MasterKey.
+func (k *KMS) Decrypt(ctx context.Context, key Key) ([]byte, error) {
+	region := awskms.Region(key.ID)
+	if region == "" {
+		return nil, fmt.Errorf("invalid ARN %q", key.ID)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(key.Enc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted key: %w", err)
+	}
+
+	return k.client.Decrypt(ctx, region, key.ID, ciphertext, key.Context)
+}
@@ -0,0 +1,525 @@
+// Package sops reads the dotenv, YAML and JSON files encrypted with SOPS, so that the encrypted configuration
+// committed along with the code is read by envconfig without decrypting it first:
+//
+//	kms, err := sops.NewKMS(sops.KMSConfig{})
+//	...
+//	source, err := sops.ReadFile(ctx, "config/prod.enc.yaml", kms)
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+//
+// The documents are flattened into keys like the ones of the httpjson package: nested maps are joined with
+// underscores and the names are upper cased, the lists are comma-separated lists. The keys of a dotenv file
+// are kept as they are.
+//
+// The data key of a file is decrypted by one of its master keys, with the MasterKey of the same type.
+// NewKMS returns the master key of AWS KMS, which only talks to the HTTP API of KMS. The other types,
+// like age or GCP KMS, are supported with KeyFunc and the library of their choice, for example filippo.io/age:
+//
+//	age := sops.KeyFunc("age", func(ctx context.Context, key sops.Key) ([]byte, error) {
+//		r, err := age.Decrypt(armor.NewReader(strings.NewReader(key.Enc)), identity)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return io.ReadAll(r)
+//	})
+//
+// The key groups of Shamir's secret sharing aren't supported.
+package sops
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vrischmann/envconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of an encrypted file.
+type Format int
+
+const (
+	// YAML is the format of the YAML files, and of the JSON files which are valid YAML.
+	YAML Format = iota
+	// Dotenv is the format of the .env files.
+	Dotenv
+)
+
+// Key is the data key of a file encrypted with a master key.
+type Key struct {
+	// Type is the type of the master key, like kms or age.
+	Type string
+	// ID identifies the master key: the ARN of a KMS key, the recipient of an age key, and so on.
+	ID string
+	// Enc is the encrypted data key.
+	Enc string
+	// Context is the encryption context of a KMS key.
+	Context map[string]string
+}
+
+// MasterKey decrypts the data keys encrypted with the master keys of a type.
+type MasterKey interface {
+	// Type is the type of the master keys, like kms or age.
+	Type() string
+	// Decrypt returns the decrypted data key.
+	Decrypt(ctx context.Context, key Key) ([]byte, error)
+}
+
+// KeyFunc returns a master key of type typ decrypting the data keys with fn.
+func KeyFunc(typ string, fn func(ctx context.Context, key Key) ([]byte, error)) MasterKey {
+	return keyFunc{typ: typ, fn: fn}
+}
+
+type keyFunc struct {
+	typ string
+	fn  func(ctx context.Context, key Key) ([]byte, error)
+}
+
+func (k keyFunc) Type() string {
+	return k.typ
+}
+
+func (k keyFunc) Decrypt(ctx context.Context, key Key) ([]byte, error) {
+	return k.fn(ctx, key)
+}
+
+// idFields are the fields identifying the master keys of each type in the metadata.
+var idFields = map[string]string{
+	"kms":      "arn",
+	"gcp_kms":  "resource_id",
+	"azure_kv": "vault_url",
+	"hc_vault": "vault_address",
+	"age":      "recipient",
+	"pgp":      "fp",
+}
+
+// ReadFile reads the encrypted file at path, in the dotenv format if its extension is .env and in the YAML format
+// otherwise, and decrypts it with one of the master keys.
+func ReadFile(ctx context.Context, path string, keys ...MasterKey) (envconfig.MapSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	format := YAML
+	if filepath.Ext(path) == ".env" {
+		format = Dotenv
+	}
+
+	return Read(ctx, f, format, keys...)
+}
+
+// Read reads an encrypted file in the format from r and decrypts it with one of the master keys.
+// It returns an error if the file was modified after its encryption.
+func Read(ctx context.Context, r io.Reader, format Format, keys ...MasterKey) (envconfig.MapSource, error) {
+	var (
+		doc *document
+		err error
+	)
+	switch format {
+	case YAML:
+		doc, err = parseYAML(r)
+	case Dotenv:
+		doc, err = parseDotenv(r)
+	default:
+		return nil, fmt.Errorf("sops: unknown format %d", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := doc.dataKey(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.decrypt(dataKey)
+}
+
+// document is an encrypted file.
+type document struct {
+	// values are the values in the order of the file.
+	values []value
+
+	keys             []Key
+	keyGroups        bool
+	lastModified     string
+	mac              string
+	macOnlyEncrypted bool
+}
+
+// value is a value of a document.
+type value struct {
+	key string
+	// path is the path of the value in the document, authenticated along with the encrypted values.
+	path []string
+	raw  string
+	// plain is the value as it is authenticated by the MAC if it is not encrypted.
+	plain string
+}
+
+// dataKey returns the data key of the document decrypted with the first master key which can decrypt it.
+func (d *document) dataKey(ctx context.Context, masterKeys []MasterKey) ([]byte, error) {
+	if d.keyGroups {
+		return nil, errors.New("sops: key groups are not supported")
+	}
+
+	var (
+		types []string
+		errs  []string
+	)
+	for _, key := range d.keys {
+		types = append(types, key.Type)
+		for _, mk := range masterKeys {
+			if mk.Type() != key.Type {
+				continue
+			}
+			dataKey, err := mk.Decrypt(ctx, key)
+			if err == nil {
+				return dataKey, nil
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs = append(errs, fmt.Sprintf("%s %s: %v", key.Type, key.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("sops: unable to decrypt the data key: %s", strings.Join(errs, ", "))
+	}
+	return nil, fmt.Errorf("sops: no master key to decrypt the data key, the file is encrypted with %s", strings.Join(dedup(types), ", "))
+}
+
+// decrypt decrypts the values with the data key and checks the MAC of the document.
+func (d *document) decrypt(dataKey []byte) (envconfig.MapSource, error) {
+	hash := sha512.New()
+	res := make(envconfig.MapSource)
+
+	for _, v := range d.values {
+		str := v.plain
+		encrypted := strings.HasPrefix(v.raw, "ENC[")
+		if encrypted {
+			var err error
+			str, err = decryptValue(v.raw, dataKey, strings.Join(v.path, ":")+":")
+			if err != nil {
+				return nil, fmt.Errorf("sops: unable to decrypt %s: %w", v.key, err)
+			}
+		}
+		if encrypted || !d.macOnlyEncrypted {
+			io.WriteString(hash, str)
+		}
+
+		// the elements of a list have the same key
+		if prev, ok := res[v.key]; ok {
+			res[v.key] = prev + "," + str
+		} else {
+			res[v.key] = str
+		}
+	}
+
+	if d.mac == "" {
+		return nil, errors.New("sops: no MAC")
+	}
+	lastModified, err := time.Parse(time.RFC3339, d.lastModified)
+	if err != nil {
+		return nil, fmt.Errorf("sops: invalid lastmodified %q", d.lastModified)
+	}
+	mac, err := decryptValue(d.mac, dataKey, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("sops: unable to decrypt the MAC: %w", err)
+	}
+	if mac != fmt.Sprintf("%X", hash.Sum(nil)) {
+		return nil, errors.New("sops: the MAC doesn't match, the file was modified")
+	}
+
+	return res, nil
+}
+
+// decryptValue decrypts a value in the ENC[AES256_GCM,data:...,iv:...,tag:...,type:...] form.
+func decryptValue(raw string, dataKey []byte, additionalData string) (string, error) {
+	if !strings.HasPrefix(raw, "ENC[AES256_GCM,") || !strings.HasSuffix(raw, "]") {
+		return "", errors.New("invalid encrypted value")
+	}
+
+	parts := make(map[string][]byte)
+	for _, field := range strings.Split(raw[len("ENC[AES256_GCM,"):len(raw)-1], ",") {
+		name, v, ok := strings.Cut(field, ":")
+		if !ok {
+			return "", errors.New("invalid encrypted value")
+		}
+		if name == "type" {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %w", name, err)
+		}
+		parts[name] = b
+	}
+	if len(parts["iv"]) == 0 || len(parts["tag"]) == 0 {
+		return "", errors.New("invalid encrypted value")
+	}
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(parts["iv"]))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, parts["iv"], append(parts["data"], parts["tag"]...), []byte(additionalData))
+	if err != nil {
+		return "", errors.New("authentication failed, wrong data key or modified value")
+	}
+
+	return string(plaintext), nil
+}
+
+// parseYAML parses a YAML document, the sops key holding the metadata.
+func parseYAML(r io.Reader) (*document, error) {
+	var root yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("sops: invalid document: %w", err)
+	}
+	if len(root.Content) != 1 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("sops: invalid document, it must be a map")
+	}
+
+	doc := &document{}
+	var meta *yaml.Node
+	content := root.Content[0].Content
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Value == "sops" {
+			meta = content[i+1]
+			continue
+		}
+		if err := doc.walkYAML(content[i+1], []string{content[i].Value}); err != nil {
+			return nil, err
+		}
+	}
+	if meta == nil {
+		return nil, errors.New("sops: no metadata, the file is not encrypted")
+	}
+
+	var m map[string]interface{}
+	if err := meta.Decode(&m); err != nil {
+		return nil, fmt.Errorf("sops: invalid metadata: %w", err)
+	}
+
+	doc.mac, _ = m["mac"].(string)
+	switch lastModified := m["lastmodified"].(type) {
+	case string:
+		doc.lastModified = lastModified
+	case time.Time:
+		doc.lastModified = lastModified.Format(time.RFC3339)
+	}
+	doc.macOnlyEncrypted, _ = m["mac_only_encrypted"].(bool)
+	groups, _ := m["key_groups"].([]interface{})
+	doc.keyGroups = len(groups) > 0
+
+	var types []string
+	for typ := range idFields {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		entries, _ := m[typ].([]interface{})
+		for _, entry := range entries {
+			fields, _ := entry.(map[string]interface{})
+			key := Key{Type: typ}
+			key.ID, _ = fields[idFields[typ]].(string)
+			key.Enc, _ = fields["enc"].(string)
+			if encContext, ok := fields["context"].(map[string]interface{}); ok {
+				key.Context = make(map[string]string, len(encContext))
+				for k, v := range encContext {
+					key.Context[k] = fmt.Sprint(v)
+				}
+			}
+			doc.keys = append(doc.keys, key)
+		}
+	}
+
+	return doc, nil
+}
+
+// walkYAML adds the values of the node at path. The elements of a list have the path of the list.
+func (d *document) walkYAML(node *yaml.Node, path []string) error {
+	key := strings.ToUpper(strings.Join(path, "_"))
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			child := append(path[:len(path):len(path)], node.Content[i].Value)
+			if err := d.walkYAML(node.Content[i+1], child); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, el := range node.Content {
+			if el.Kind != yaml.ScalarNode {
+				return fmt.Errorf("sops: invalid value of %s, lists must contain scalars", key)
+			}
+			if err := d.walkYAML(el, path); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		d.values = append(d.values, value{key: key, path: path, raw: node.Value, plain: yamlScalar(node)})
+	case yaml.AliasNode:
+		return d.walkYAML(node.Alias, path)
+	default:
+		return fmt.Errorf("sops: invalid value of %s", key)
+	}
+
+	return nil
+}
+
+// yamlScalar returns the value of an unencrypted scalar as authenticated by the MAC,
+// which formats the booleans and the numbers like sops does.
+func yamlScalar(node *yaml.Node) string {
+	switch node.ShortTag() {
+	case "!!null":
+		return ""
+	case "!!bool":
+		var b bool
+		if node.Decode(&b) == nil {
+			if b {
+				return "True"
+			}
+			return "False"
+		}
+	case "!!int":
+		var i int64
+		if node.Decode(&i) == nil {
+			return strconv.FormatInt(i, 10)
+		}
+	case "!!float":
+		var f float64
+		if node.Decode(&f) == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return node.Value
+}
+
+// parseDotenv parses a dotenv document, the keys starting with sops_ holding the metadata.
+func parseDotenv(r io.Reader) (*document, error) {
+	doc := &document{}
+	// keys are the fields of the master keys by type and index
+	keys := make(map[string]map[int]map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("sops: invalid line %q", line)
+		}
+		v = strings.ReplaceAll(v, `\n`, "\n")
+
+		meta, ok := strings.CutPrefix(name, "sops_")
+		if !ok {
+			doc.values = append(doc.values, value{key: name, path: []string{name}, raw: v, plain: v})
+			continue
+		}
+
+		switch meta {
+		case "mac":
+			doc.mac = v
+		case "lastmodified":
+			doc.lastModified = v
+		case "mac_only_encrypted":
+			doc.macOnlyEncrypted = v == "true"
+		default:
+			// the master keys are flattened like kms__list_0__map_arn
+			typ, rest, ok := strings.Cut(meta, "__list_")
+			if !ok {
+				continue
+			}
+			if typ == "key_groups" {
+				doc.keyGroups = true
+				continue
+			}
+			index, field, ok := strings.Cut(rest, "__map_")
+			i, err := strconv.Atoi(index)
+			if !ok || err != nil {
+				continue
+			}
+			if keys[typ] == nil {
+				keys[typ] = make(map[int]map[string]string)
+			}
+			if keys[typ][i] == nil {
+				keys[typ][i] = make(map[string]string)
+			}
+			keys[typ][i][field] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	if doc.mac == "" && len(keys) == 0 {
+		return nil, errors.New("sops: no metadata, the file is not encrypted")
+	}
+
+	var types []string
+	for typ := range keys {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	for _, typ := range types {
+		var indexes []int
+		for i := range keys[typ] {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+
+		for _, i := range indexes {
+			fields := keys[typ][i]
+			key := Key{Type: typ, ID: fields[idFields[typ]], Enc: fields["enc"]}
+			for field, v := range fields {
+				if name, ok := strings.CutPrefix(field, "context__map_"); ok {
+					if key.Context == nil {
+						key.Context = make(map[string]string)
+					}
+					key.Context[name] = v
+				}
+			}
+			doc.keys = append(doc.keys, key)
+		}
+	}
+
+	return doc, nil
+}
+
+// dedup returns the strings without the duplicates, in order.
+func dedup(strs []string) []string {
+	seen := make(map[string]struct{})
+	var res []string
+	for _, s := range strs {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			res = append(res, s)
+		}
+	}
+	return res
+}
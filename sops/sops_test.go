@@ -0,0 +1,189 @@
+package sops_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/sops"
+)
+
+const lastModified = "2024-03-01T10:00:00Z"
+
+var dataKey = []byte("0123456789abcdef0123456789abcdef")
+
+// encrypt encrypts a value like sops does.
+func encrypt(t *testing.T, plaintext, additionalData, typ string) string {
+	block, err := aes.NewCipher(dataKey)
+	require.Nil(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	require.Nil(t, err)
+
+	iv := make([]byte, 32)
+	_, err = rand.Read(iv)
+	require.Nil(t, err)
+
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), []byte(additionalData))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(data), base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag), typ)
+}
+
+// mac returns the encrypted MAC of the values.
+func mac(t *testing.T, values ...string) string {
+	hash := sha512.New()
+	for _, v := range values {
+		hash.Write([]byte(v))
+	}
+	return encrypt(t, fmt.Sprintf("%X", hash.Sum(nil)), lastModified, "str")
+}
+
+var ageKey = sops.KeyFunc("age", func(ctx context.Context, key sops.Key) ([]byte, error) {
+	if key.Enc != "encrypted data key" {
+		return nil, fmt.Errorf("unknown key")
+	}
+	return dataKey, nil
+})
+
+func yamlDocument(t *testing.T, name string) string {
+	return `name: ` + encrypt(t, name, "name:", "str") + `
+db:
+    port: ` + encrypt(t, "5432", "db:port:", "int") + `
+    password: ` + encrypt(t, "secret", "db:password:", "str") + `
+tags:
+    - ` + encrypt(t, "a", "tags:", "str") + `
+    - ` + encrypt(t, "b", "tags:", "str") + `
+debug_unencrypted: true
+sops:
+    age:
+        - recipient: age1example
+          enc: encrypted data key
+    lastmodified: "` + lastModified + `"
+    mac: ` + mac(t, "foo", "5432", "secret", "a", "b", "True") + `
+    version: 3.8.1
+`
+}
+
+func TestReadYAML(t *testing.T) {
+	source, err := sops.Read(context.Background(), strings.NewReader(yamlDocument(t, "foo")), sops.YAML, ageKey)
+	require.Nil(t, err)
+	require.Equal(t, envconfig.MapSource{
+		"NAME":              "foo",
+		"DB_PORT":           "5432",
+		"DB_PASSWORD":       "secret",
+		"TAGS":              "a,b",
+		"DEBUG_UNENCRYPTED": "True",
+	}, source)
+
+	var conf struct {
+		Name string
+		DB   struct {
+			Port     int
+			Password string
+		}
+		Tags []string
+	}
+	require.Nil(t, envconfig.InitWithOptions(&conf, envconfig.Options{Source: source}))
+	require.Equal(t, 5432, conf.DB.Port)
+	require.Equal(t, []string{"a", "b"}, conf.Tags)
+
+	// the value of name is replaced by a value encrypted with the same key
+	modified := yamlDocument(t, "bar")
+	_, err = sops.Read(context.Background(), strings.NewReader(modified), sops.YAML, ageKey)
+	require.Equal(t, "sops: the MAC doesn't match, the file was modified", err.Error())
+
+	// the value of name is moved to another key
+	modified = strings.Replace(yamlDocument(t, "foo"), "name:", "nam:", 1)
+	_, err = sops.Read(context.Background(), strings.NewReader(modified), sops.YAML, ageKey)
+	require.Equal(t, "sops: unable to decrypt NAM: authentication failed, wrong data key or modified value", err.Error())
+}
+
+func TestReadFileDotenv(t *testing.T) {
+	doc := "# comment\n" +
+		"NAME=" + encrypt(t, "foo", "NAME:", "str") + "\n" +
+		"PORT=" + encrypt(t, "80", "PORT:", "str") + "\n" +
+		"sops_age__list_0__map_enc=encrypted data key\n" +
+		"sops_age__list_0__map_recipient=age1example\n" +
+		"sops_lastmodified=" + lastModified + "\n" +
+		"sops_mac=" + mac(t, "foo", "80") + "\n" +
+		"sops_version=3.8.1\n"
+
+	path := filepath.Join(t.TempDir(), "prod.env")
+	require.Nil(t, os.WriteFile(path, []byte(doc), 0600))
+
+	source, err := sops.ReadFile(context.Background(), path, ageKey)
+	require.Nil(t, err)
+	require.Equal(t, envconfig.MapSource{"NAME": "foo", "PORT": "80"}, source)
+
+	_, err = sops.ReadFile(context.Background(), path)
+	require.Equal(t, "sops: no master key to decrypt the data key, the file is encrypted with age", err.Error())
+
+	_, err = sops.Read(context.Background(), strings.NewReader("NAME=foo\n"), sops.Dotenv, ageKey)
+	require.Equal(t, "sops: no metadata, the file is not encrypted", err.Error())
+}
+
+func TestKMS(t *testing.T) {
+	arn := "arn:aws:kms:eu-west-1:123456789012:key/1234abcd"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "TrentService.Decrypt", r.Header.Get("X-Amz-Target"))
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		require.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request")
+
+		var req struct {
+			CiphertextBlob    string
+			EncryptionContext map[string]string
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.CiphertextBlob != base64.StdEncoding.EncodeToString([]byte("blob")) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "InvalidCiphertextException", "message": "invalid ciphertext"}`))
+			return
+		}
+		require.Equal(t, map[string]string{"app": "myapp"}, req.EncryptionContext)
+
+		json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": dataKey})
+	}))
+	defer srv.Close()
+
+	kms, err := sops.NewKMS(sops.KMSConfig{AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: srv.URL})
+	require.Nil(t, err)
+
+	doc := `name: ` + encrypt(t, "foo", "name:", "str") + `
+sops:
+    kms:
+        - arn: ` + arn + `
+          enc: ` + base64.StdEncoding.EncodeToString([]byte("invalid")) + `
+        - arn: ` + arn + `
+          enc: ` + base64.StdEncoding.EncodeToString([]byte("blob")) + `
+          context:
+            app: myapp
+    lastmodified: "` + lastModified + `"
+    mac: ` + mac(t, "foo") + `
+`
+
+	source, err := sops.Read(context.Background(), strings.NewReader(doc), sops.YAML, kms)
+	require.Nil(t, err)
+	require.Equal(t, envconfig.MapSource{"NAME": "foo"}, source)
+
+	doc = strings.Replace(doc, base64.StdEncoding.EncodeToString([]byte("blob")), "", 1)
+	_, err = sops.Read(context.Background(), strings.NewReader(doc), sops.YAML, kms)
+	require.Equal(t, "sops: unable to decrypt the data key: "+
+		"kms "+arn+": Decrypt: InvalidCiphertextException: invalid ciphertext, "+
+		"kms "+arn+": Decrypt: InvalidCiphertextException: invalid ciphertext", err.Error())
+}
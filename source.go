@@ -0,0 +1,406 @@
+package envconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source is where the values of the keys are read from. The default source is the environment.
+type Source interface {
+	// Lookup returns the value of the key and whether it is set.
+	// An empty value is the same as an unset key.
+	Lookup(key string) (string, bool, error)
+}
+
+// Lister is the interface implemented by sources which can list their keys.
+// The keys are needed for rest fields, the option Strict and the suggestions of the error messages,
+// without them these features don't see any key of the source.
+type Lister interface {
+	Keys() []string
+}
+
+// FieldSource is the interface implemented by sources which use the struct tag of a field to find its value,
+// for example to map a field to a path in a secret store. LookupField is used instead of Lookup for the fields.
+type FieldSource interface {
+	Source
+	LookupField(key string, tag reflect.StructTag) (string, bool, error)
+}
+
+// ContextSource is the interface implemented by sources which can abort a lookup when a context is done,
+// see InitContext. LookupContext is used instead of Lookup and LookupField, tag is empty for the keys
+// which are not read for a field.
+type ContextSource interface {
+	Source
+	LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error)
+}
+
+// HealthChecker is the interface implemented by sources with background components, like watchers, lease renewals
+// or caches revalidating their values. Health returns the error which currently prevents the source from staying
+// up to date, nil if it is healthy, so that the health endpoint of an application can report the health
+// of its configuration backend apart from its own.
+type HealthChecker interface {
+	Health() error
+}
+
+// SourceHealth returns the health of source if it implements HealthChecker, nil otherwise.
+func SourceHealth(source Source) error {
+	if hc, ok := source.(HealthChecker); ok {
+		return hc.Health()
+	}
+	return nil
+}
+
+// EnvSource is the source reading the environment variables.
+type EnvSource struct{}
+
+// Lookup implements Source.
+func (EnvSource) Lookup(key string) (string, bool, error) {
+	value, ok := os.LookupEnv(key)
+	return value, ok, nil
+}
+
+// Keys implements Lister.
+func (EnvSource) Keys() []string {
+	return environNames()
+}
+
+// MapSource is a source reading the keys from a map.
+type MapSource map[string]string
+
+// Lookup implements Source.
+func (m MapSource) Lookup(key string) (string, bool, error) {
+	value, ok := m[key]
+	return value, ok, nil
+}
+
+// Keys implements Lister.
+func (m MapSource) Keys() []string {
+	var res []string
+	for key, value := range m {
+		if value != "" {
+			res = append(res, key)
+		}
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// Chain is a source resolving each key through several sources in order of priority:
+// the value of a key is the first non-empty value found. The default values of the tags apply last.
+//
+//	envconfig.Chain{envconfig.EnvSource{}, dotenv, remote}
+type Chain []Source
+
+// Lookup implements Source.
+func (c Chain) Lookup(key string) (string, bool, error) {
+	return c.lookup(func(s Source) (string, bool, error) {
+		return s.Lookup(key)
+	})
+}
+
+// LookupField implements FieldSource, the tag is passed to the sources implementing FieldSource.
+func (c Chain) LookupField(key string, tag reflect.StructTag) (string, bool, error) {
+	return c.lookup(func(s Source) (string, bool, error) {
+		if fs, ok := s.(FieldSource); ok {
+			return fs.LookupField(key, tag)
+		}
+		return s.Lookup(key)
+	})
+}
+
+// LookupContext implements ContextSource, the context is passed to the sources implementing ContextSource.
+func (c Chain) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	return c.lookup(func(s Source) (string, bool, error) {
+		switch s := s.(type) {
+		case ContextSource:
+			return s.LookupContext(ctx, key, tag)
+		case FieldSource:
+			if tag != "" {
+				return s.LookupField(key, tag)
+			}
+		}
+		return s.Lookup(key)
+	})
+}
+
+func (c Chain) lookup(fn func(s Source) (string, bool, error)) (string, bool, error) {
+	var found bool
+	for _, s := range c {
+		value, ok, err := fn(s)
+		if err != nil {
+			return "", false, err
+		}
+		if value != "" {
+			return value, true, nil
+		}
+		found = found || ok
+	}
+	return "", found, nil
+}
+
+// Keys implements Lister, it returns the keys of all the sources implementing Lister.
+func (c Chain) Keys() []string {
+	seen := make(map[string]struct{})
+	var res []string
+	for _, s := range c {
+		lister, ok := s.(Lister)
+		if !ok {
+			continue
+		}
+		for _, key := range lister.Keys() {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				res = append(res, key)
+			}
+		}
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// Health implements HealthChecker, it returns the errors of the unhealthy sources prefixed by their names.
+func (c Chain) Health() error {
+	var errs []error
+	for _, s := range c {
+		if err := SourceHealth(s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sourceName(s), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String returns the names of the sources.
+func (c Chain) String() string {
+	names := make([]string, len(c))
+	for i, s := range c {
+		names[i] = sourceName(s)
+	}
+	return "chain(" + strings.Join(names, ", ") + ")"
+}
+
+// source returns the source of the options, the environment if there is none.
+func (s *state) source() Source {
+	if s.opts.Source == nil {
+		return EnvSource{}
+	}
+	return s.opts.Source
+}
+
+// lookup returns the value of the key in the source and the source which provided it, tag is the struct tag
+// of the field if any. The errors of the members of a Chain which were skipped are added to the failures of the state.
+// If the context of the state is done, it gives up and returns a *DeadlineError if the deadline is exceeded,
+// or the error of the context otherwise.
+func (s *state) lookup(key string, tag reflect.StructTag) (value string, origin Source, err error) {
+	source := s.source()
+
+	if s.abortErr != nil {
+		return "", nil, s.abortErr
+	}
+
+	ctx := s.lookupCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if res, ok := s.prefetchedLookup(key, tag); ok {
+		if res.err != nil && ctx.Err() != nil {
+			return "", nil, s.abort(key, source)
+		}
+		s.failures = append(s.failures, res.failures...)
+		return res.value, res.origin, res.err
+	}
+
+	if s.opts.Metrics != nil {
+		start := time.Now()
+		defer func() {
+			s.opts.Metrics.Lookup(sourceName(source), time.Since(start), err)
+		}()
+	}
+
+	lookup := originLookup(ctx, source, tag, s.failed)
+
+	if ctx.Done() == nil {
+		res := lookup(key)
+		s.failures = append(s.failures, res.failures...)
+		return res.value, res.origin, res.err
+	}
+
+	if ctx.Err() != nil {
+		return "", nil, s.abort(key, source)
+	}
+
+	ch := make(chan lookupResult, 1)
+	go func() {
+		ch <- lookup(key)
+	}()
+
+	select {
+	case res := <-ch:
+		if res.err != nil && ctx.Err() != nil {
+			return "", nil, s.abort(key, source)
+		}
+		s.failures = append(s.failures, res.failures...)
+		return res.value, res.origin, res.err
+	case <-ctx.Done():
+		return "", nil, s.abort(key, source)
+	}
+}
+
+// sourceLookup returns the function looking up the keys of a field in source: with ctx if it is a ContextSource,
+// and with the struct tag of the field if it is a FieldSource.
+func sourceLookup(ctx context.Context, source Source, tag reflect.StructTag) func(key string) (string, bool, error) {
+	switch src := source.(type) {
+	case ContextSource:
+		return func(key string) (string, bool, error) {
+			return src.LookupContext(ctx, key, tag)
+		}
+	case FieldSource:
+		if tag != "" {
+			return func(key string) (string, bool, error) {
+				return src.LookupField(key, tag)
+			}
+		}
+	}
+	return source.Lookup
+}
+
+// originLookup is like sourceLookup, and also returns the source which provided the value:
+// the member of a Chain which provided it rather than the Chain itself. The errors of the members of a Chain
+// are *SourceError, skipped and recorded in failed if it isn't nil and the context isn't done.
+func originLookup(ctx context.Context, source Source, tag reflect.StructTag, failed *failedSources) func(key string) lookupResult {
+	return memberLookup(ctx, source, tag, failed, "")
+}
+
+// memberLookup is originLookup for the source at the position pos in the Chain of the options,
+// like 1.0 for the first member of its second member, empty for the source of the options itself.
+func memberLookup(ctx context.Context, source Source, tag reflect.StructTag, failed *failedSources, pos string) func(key string) lookupResult {
+	chain, ok := source.(Chain)
+	if !ok {
+		lookup := sourceLookup(ctx, source, tag)
+		return func(key string) lookupResult {
+			value, _, err := lookup(key)
+			if err != nil || value == "" {
+				return lookupResult{err: err}
+			}
+			return lookupResult{value: value, origin: source}
+		}
+	}
+
+	lookups := make([]func(key string) lookupResult, len(chain))
+	positions := make([]string, len(chain))
+	for i, s := range chain {
+		positions[i] = combineName(pos, strconv.Itoa(i))
+		lookups[i] = memberLookup(ctx, s, tag, failed, positions[i])
+	}
+	return func(key string) lookupResult {
+		var failures []*SourceError
+		for i, lookup := range lookups {
+			// a member which failed isn't looked up again during the same Init call
+			if srcErr := failed.get(positions[i]); srcErr != nil {
+				failures = append(failures, srcErr)
+				continue
+			}
+
+			res := lookup(key)
+			if res.err != nil {
+				srcErr, ok := res.err.(*SourceError)
+				if !ok {
+					srcErr = &SourceError{Source: sourceName(chain[i]), Err: res.err}
+				}
+				if failed == nil || ctx.Err() != nil {
+					res.err = srcErr
+					return res
+				}
+				failed.add(positions[i], srcErr)
+				failures = append(failures, srcErr)
+				continue
+			}
+			res.failures = append(failures, res.failures...)
+			if res.value != "" {
+				return res
+			}
+			failures = res.failures
+		}
+		return lookupResult{failures: failures}
+	}
+}
+
+// failedSources are the members of the Chain of the options which failed during an Init call, by position,
+// with Options.TolerateSourceFailures. The nil value records nothing.
+type failedSources struct {
+	mu      sync.Mutex
+	members map[string]*SourceError
+}
+
+// get returns the error of the member at pos, nil if it didn't fail.
+func (f *failedSources) get(pos string) *SourceError {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.members[pos]
+}
+
+// add records the error of the member at pos, the first one is kept.
+func (f *failedSources) add(pos string, err *SourceError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.members == nil {
+		f.members = make(map[string]*SourceError)
+	}
+	if _, ok := f.members[pos]; !ok {
+		f.members[pos] = err
+	}
+}
+
+// abort records the error of the context of the state, which is done while looking up key, and returns it.
+func (s *state) abort(key string, source Source) error {
+	if s.lookupCtx.Err() == context.DeadlineExceeded {
+		s.abortErr = &DeadlineError{
+			Resolved: s.resolved,
+			Pending:  key,
+			Source:   sourceName(source),
+		}
+	} else {
+		s.abortErr = s.lookupCtx.Err()
+	}
+	return s.abortErr
+}
+
+// sourceName returns the name of the source, its String method if it has one or its type otherwise.
+func sourceName(source Source) string {
+	if s, ok := source.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", source)
+}
+
+// sourceKeys returns the sorted keys of the source which are not empty,
+// or nothing if it doesn't implement Lister.
+func (s *state) sourceKeys() []string {
+	lister, ok := s.source().(Lister)
+	if !ok {
+		return nil
+	}
+
+	keys := lister.Keys()
+	if !sort.StringsAreSorted(keys) {
+		keys = append([]string(nil), keys...)
+		sort.Strings(keys)
+	}
+
+	return keys
+}
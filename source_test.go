@@ -0,0 +1,361 @@
+package envconfig_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestMapSource(t *testing.T) {
+	var conf struct {
+		Name  string
+		Port  int
+		Extra map[string]string `envconfig:",rest"`
+	}
+
+	source := envconfig.MapSource{"APP_NAME": "foobar", "APP_PORT": "80", "APP_FOO": "bar"}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foobar", conf.Name)
+	require.Equal(t, 80, conf.Port)
+	require.Equal(t, map[string]string{"FOO": "bar"}, conf.Extra)
+
+	source = envconfig.MapSource{"APP_NAM": "foobar", "APP_PORT": "80"}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Prefix: "APP", Source: source})
+	require.Equal(t, "envconfig: keys APP_NAME, app_name not found (field Name), did you mean APP_NAM?", err.Error())
+}
+
+type failingSource struct{}
+
+var errUnreachable = errors.New("unreachable")
+
+func (failingSource) Lookup(key string) (string, bool, error) {
+	return "", false, errUnreachable
+}
+
+func TestSourceError(t *testing.T) {
+	var conf struct {
+		Name string
+	}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: failingSource{}})
+	require.True(t, errors.Is(err, errUnreachable))
+	require.Equal(t, "envconfig: unable to look up NAME (field Name): unreachable", err.Error())
+}
+
+type slowSource struct {
+	envconfig.MapSource
+	slowKey string
+}
+
+func (s slowSource) Lookup(key string) (string, bool, error) {
+	if key == s.slowKey {
+		time.Sleep(time.Second)
+	}
+	return s.MapSource.Lookup(key)
+}
+
+func (s slowSource) String() string {
+	return "slow source"
+}
+
+func TestTimeout(t *testing.T) {
+	var conf struct {
+		Name     string
+		Password string
+		Port     int
+	}
+
+	source := slowSource{envconfig.MapSource{"NAME": "foo", "PASSWORD": "bar", "PORT": "80"}, "PASSWORD"}
+
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Timeout: 10 * time.Millisecond})
+	require.Equal(t, "envconfig: deadline exceeded while looking up PASSWORD in slow source (resolved: NAME)", err.Error())
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	var deadlineErr *envconfig.DeadlineError
+	require.True(t, errors.As(err, &deadlineErr))
+	require.Equal(t, "PASSWORD", deadlineErr.Pending)
+
+	source.slowKey = ""
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Timeout: time.Second})
+	require.Nil(t, err)
+}
+
+// delayedSource is a countingSource taking some time to look up each key.
+type delayedSource struct {
+	*countingSource
+	delay time.Duration
+}
+
+func (s delayedSource) Lookup(key string) (string, bool, error) {
+	time.Sleep(s.delay)
+	return s.countingSource.Lookup(key)
+}
+
+func TestParallelism(t *testing.T) {
+	var conf struct {
+		A, B, C, D, E, F, G, H string
+		Port                   int `envconfig:"optional"`
+	}
+
+	source := delayedSource{newCountingSource(envconfig.MapSource{
+		"A": "a", "B": "b", "C": "c", "D": "d", "E": "e", "F": "f", "G": "g", "H": "h",
+	}), 50 * time.Millisecond}
+
+	start := time.Now()
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Parallelism: 9})
+	require.Nil(t, err)
+	require.Less(t, time.Since(start), 400*time.Millisecond)
+	require.Equal(t, "a", conf.A)
+	require.Equal(t, "h", conf.H)
+	// each key is looked up once
+	require.Equal(t, 1, source.count("A"))
+	require.Equal(t, 1, source.count("PORT"))
+	require.Equal(t, 1, source.count("port"))
+
+	source.delay = time.Second
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Parallelism: 4, Timeout: 10 * time.Millisecond})
+	var deadlineErr *envconfig.DeadlineError
+	require.True(t, errors.As(err, &deadlineErr))
+	require.Equal(t, "A", deadlineErr.Pending)
+}
+
+// concurrencySource records whether the serial keys are looked up concurrently with other keys.
+type concurrencySource struct {
+	envconfig.MapSource
+	serial map[string]bool
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	serialBusy  bool
+	overlap     bool
+}
+
+func (s *concurrencySource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxInFlight {
+		s.maxInFlight = s.inFlight
+	}
+	if s.serialBusy || s.serial[key] && s.inFlight > 1 {
+		s.overlap = true
+	}
+	if s.serial[key] {
+		s.serialBusy = true
+	}
+	s.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	if s.serial[key] {
+		s.serialBusy = false
+	}
+	s.mu.Unlock()
+
+	return s.MapSource.Lookup(key)
+}
+
+func TestSerialLookups(t *testing.T) {
+	var conf struct {
+		A, B, C, D string
+		Lock       string `envconfig:",serial"`
+		E, F       string
+	}
+
+	values := envconfig.MapSource{"A": "a", "B": "b", "C": "c", "D": "d", "LOCK": "lock", "E": "e", "F": "f"}
+	source := &concurrencySource{MapSource: values, serial: map[string]bool{"LOCK": true, "lock": true}}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Parallelism: 4})
+	require.Nil(t, err)
+	require.Equal(t, "lock", conf.Lock)
+	require.False(t, source.overlap)
+	require.Greater(t, source.maxInFlight, 1)
+
+	source = &concurrencySource{MapSource: values}
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source, Parallelism: 4, Serial: true})
+	require.Nil(t, err)
+	require.Equal(t, 1, source.maxInFlight)
+}
+
+func TestChain(t *testing.T) {
+	var conf struct {
+		Name    string
+		Port    int
+		Debug   bool   `envconfig:"default=true"`
+		Region  string `envconfig:"default=eu-west-1"`
+		Timeout string `envconfig:"optional"`
+	}
+
+	env := envconfig.MapSource{"NAME": "env", "PORT": ""}
+	dotenv := envconfig.MapSource{"NAME": "dotenv", "PORT": "80"}
+	remote := envconfig.MapSource{"PORT": "8080", "REGION": "us-east-1"}
+
+	source := envconfig.Chain{env, dotenv, remote}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "env", conf.Name)
+	require.Equal(t, 80, conf.Port)
+	require.True(t, conf.Debug)
+	require.Equal(t, "us-east-1", conf.Region)
+	require.Equal(t, []string{"NAME", "PORT", "REGION"}, source.Keys())
+	require.Equal(t, "chain(envconfig.MapSource, envconfig.MapSource, envconfig.MapSource)", source.String())
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.Chain{env, failingSource{}}})
+	require.True(t, errors.Is(err, errUnreachable))
+	require.Contains(t, err.Error(), "envconfig: unable to look up PORT (field Port): envconfig_test.failingSource: unreachable")
+
+	var srcErr *envconfig.SourceError
+	require.True(t, errors.As(err, &srcErr))
+	require.Equal(t, "envconfig_test.failingSource", srcErr.Source)
+}
+
+func TestTolerateSourceFailures(t *testing.T) {
+	var conf struct {
+		Name    string
+		Port    int    `envconfig:"default=8080"`
+		Timeout string `envconfig:"optional"`
+	}
+
+	var failures []envconfig.SourceFailure
+	opts := envconfig.Options{
+		Source:                 envconfig.Chain{failingSource{}, envconfig.MapSource{"NAME": "foo"}},
+		TolerateSourceFailures: true,
+		OnSourceFailure: func(f envconfig.SourceFailure) {
+			failures = append(failures, f)
+		},
+	}
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, 8080, conf.Port)
+	require.Len(t, failures, 3)
+	require.Equal(t, "envconfig_test.failingSource skipped for field Port: unreachable", failures[1].String())
+
+	// a required field which isn't found fails with the error of the source
+	opts.Source = envconfig.Chain{envconfig.MapSource{}, failingSource{}}
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Equal(t, "envconfig: unable to look up NAME (field Name): envconfig_test.failingSource: unreachable", err.Error())
+	require.True(t, errors.Is(err, errUnreachable))
+
+	// the failures of the prefetched lookups are tolerated too
+	failures = nil
+	opts.Source = envconfig.Chain{failingSource{}, envconfig.MapSource{"NAME": "foo"}}
+	opts.Parallelism = 2
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Nil(t, err)
+	require.Len(t, failures, 3)
+
+	// the missing key is still reported
+	opts.Source = envconfig.Chain{failingSource{}}
+	opts.Parallelism = 0
+	err = envconfig.InitWithOptions(&conf, opts)
+	require.Equal(t, "envconfig: unable to look up NAME (field Name): envconfig_test.failingSource: unreachable", err.Error())
+	var missing *envconfig.MissingKeyError
+	require.True(t, errors.As(err, &missing))
+	require.Equal(t, "Name", missing.Field)
+	require.Equal(t, "export NAME=<string>\n", envconfig.MissingExports(err))
+}
+
+// downSource is a source which is down, counting the lookups.
+type downSource struct {
+	lookups int
+}
+
+func (s *downSource) Lookup(key string) (string, bool, error) {
+	s.lookups++
+	return "", false, errUnreachable
+}
+
+func TestTolerateSourceFailuresSkip(t *testing.T) {
+	var conf struct {
+		Name    string
+		Port    int    `envconfig:"default=8080"`
+		Timeout string `envconfig:"optional"`
+	}
+
+	// a member which failed isn't looked up again during the same Init call
+	down := &downSource{}
+	var failures []envconfig.SourceFailure
+	opts := envconfig.Options{
+		Source:                 envconfig.Chain{down, envconfig.MapSource{"NAME": "foo"}},
+		TolerateSourceFailures: true,
+		OnSourceFailure: func(f envconfig.SourceFailure) {
+			failures = append(failures, f)
+		},
+	}
+	require.Nil(t, envconfig.InitWithOptions(&conf, opts))
+	require.Equal(t, 1, down.lookups)
+	require.Len(t, failures, 3)
+	require.Equal(t, "Timeout", failures[2].Field)
+
+	// it is looked up again by the next one
+	require.Nil(t, envconfig.InitWithOptions(&conf, opts))
+	require.Equal(t, 2, down.lookups)
+}
+
+type contextSource struct {
+	envconfig.MapSource
+}
+
+func (s contextSource) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	if key == "PASSWORD" {
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}
+	return s.MapSource.Lookup(key)
+}
+
+func TestInitContext(t *testing.T) {
+	var conf struct {
+		Name     string
+		Password string
+	}
+
+	source := contextSource{envconfig.MapSource{"NAME": "foo", "PASSWORD": "bar"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := envconfig.InitContext(ctx, &conf, envconfig.Options{Source: source})
+	require.Equal(t, "envconfig: deadline exceeded while looking up PASSWORD in envconfig_test.contextSource (resolved: NAME)", err.Error())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	err = envconfig.InitContext(ctx, &conf, envconfig.Options{Source: source})
+	require.Equal(t, context.Canceled, err)
+}
+
+// healthSource is a MapSource reporting its health.
+type healthSource struct {
+	envconfig.MapSource
+	err error
+}
+
+func (s healthSource) Health() error {
+	return s.err
+}
+
+func TestChainHealth(t *testing.T) {
+	chain := envconfig.Chain{envconfig.EnvSource{}, healthSource{}}
+	require.Nil(t, chain.Health())
+	require.Nil(t, envconfig.SourceHealth(envconfig.EnvSource{}))
+
+	chain = append(chain, envconfig.NewCache(healthSource{err: errUnreachable}, envconfig.CacheOptions{}))
+	err := envconfig.SourceHealth(chain)
+	require.NotNil(t, err)
+	require.Equal(t, "cache(envconfig_test.healthSource): unreachable", err.Error())
+	require.True(t, errors.Is(err, errUnreachable))
+}
@@ -0,0 +1,262 @@
+package envconfig
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vrischmann/envconfig/internal/lifecycle"
+)
+
+// ReloadStrategy decides which configuration a Store serves when a reload fails.
+type ReloadStrategy int
+
+const (
+	// KeepLastGood keeps serving the last configuration loaded successfully. It is the default.
+	KeepLastGood ReloadStrategy = iota
+	// KeepInitial goes back to the configuration loaded when the store was created.
+	KeepInitial
+	// StopWatching keeps serving the last configuration loaded successfully and stops Watch,
+	// which returns the error.
+	StopWatching
+)
+
+// StoreOptions are the options of a Store.
+type StoreOptions struct {
+	// Options are the options used to read the configuration.
+	Options Options
+
+	// Strategy decides what happens when a reload fails.
+	Strategy ReloadStrategy
+
+	// OnReloadError is called when a reload fails, with the error and the number of consecutive failures.
+	// Use it to log the error or update a metric.
+	OnReloadError func(err error, failures int)
+}
+
+// StoreStats are statistics about the reloads of a Store, for example to export them as metrics.
+type StoreStats struct {
+	// Generation is incremented each time a new configuration is loaded, it is 1 after the store is created.
+	Generation uint64
+	// Reloads and Failures are the numbers of reloads and failed reloads.
+	Reloads  uint64
+	Failures uint64
+	// ConsecutiveFailures is the number of failed reloads since the last successful one.
+	ConsecutiveFailures int
+	// LastError is the error of the last reload, nil if it succeeded.
+	LastError error
+	// LastReload is the time of the last reload.
+	LastReload time.Time
+}
+
+// Store holds a configuration of type T which can be reloaded, safe for concurrent use.
+//
+// A reload which fails, because a key is missing or a value is invalid, never replaces the configuration:
+// the store keeps serving a configuration known to be good, according to its ReloadStrategy.
+type Store[T any] struct {
+	opts    StoreOptions
+	initial *T
+	current atomic.Pointer[T]
+
+	// reloadMu serializes the reloads.
+	reloadMu sync.Mutex
+	// mu protects stats and canaries, it is never held while calling the hooks of the options and the canaries.
+	mu       sync.Mutex
+	stats    StoreStats
+	canaries []func(candidate T) error
+
+	// notifyMu serializes the notifications of the changes, it is locked before reloadMu is unlocked
+	// so that the subscribers receive the changes in order.
+	notifyMu sync.Mutex
+	// subMu protects subscriptions, fieldHooks and closed.
+	subMu         sync.Mutex
+	subscriptions []*subscription[T]
+	fieldHooks    []*fieldHook
+	closed        bool
+
+	lifecycle lifecycle.Lifecycle
+}
+
+// NewStore returns a new store holding the configuration read with opts.Options.
+// It returns an error if the configuration can't be read.
+func NewStore[T any](opts StoreOptions) (*Store[T], error) {
+	conf := new(T)
+	if err := InitWithOptions(conf, opts.Options); err != nil {
+		return nil, err
+	}
+
+	s := &Store[T]{
+		opts:    opts,
+		initial: conf,
+	}
+	s.current.Store(conf)
+	s.stats.Generation = 1
+	s.stats.LastReload = time.Now()
+
+	return s, nil
+}
+
+// Get returns the current configuration. It must not be modified.
+func (s *Store[T]) Get() *T {
+	return s.current.Load()
+}
+
+// Stats returns the statistics of the reloads.
+func (s *Store[T]) Stats() StoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
+
+// AddCanary registers a hook evaluating each reloaded configuration before it replaces the current one,
+// for example to check that a database is reachable with the new credentials.
+// A configuration rejected by a hook is handled like an invalid one, and the error is a *CanaryError.
+func (s *Store[T]) AddCanary(hook func(candidate T) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.canaries = append(s.canaries, hook)
+}
+
+// Reload reads the configuration again and replaces the current one if it is valid and accepted by the canaries.
+// Otherwise the error is reported to OnReloadError and returned, and the store serves
+// the configuration chosen by the strategy.
+// It returns ErrShutdown once the store is shut down, and Shutdown cancels a running reload.
+// The subscribers are notified once the configuration is replaced, see Subscribe.
+func (s *Store[T]) Reload() error {
+	ctx, release, err := s.lifecycle.Start(context.Background())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.reload(ctx)
+}
+
+// reload reloads the configuration and notifies the change to the subscribers, if any.
+func (s *Store[T]) reload(ctx context.Context) error {
+	change, err := s.update(ctx)
+	if change != nil {
+		s.notify(*change)
+		s.notifyMu.Unlock()
+	}
+	return err
+}
+
+// update reloads the configuration, the reading of the values is canceled when ctx is done.
+// A canceled reload leaves the store untouched. If the configuration is replaced, it returns the change
+// with notifyMu locked.
+func (s *Store[T]) update(ctx context.Context) (*Change[T], error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	conf := new(T)
+	err := InitContext(ctx, conf, s.opts.Options)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	canaries := s.canaries
+	s.mu.Unlock()
+
+	for _, canary := range canaries {
+		if err != nil {
+			break
+		}
+		if canaryErr := canary(*conf); canaryErr != nil {
+			err = &CanaryError{Err: canaryErr}
+		}
+	}
+
+	s.mu.Lock()
+	s.stats.Reloads++
+	s.stats.LastReload = time.Now()
+	s.stats.LastError = err
+
+	var change *Change[T]
+	if err != nil {
+		s.stats.Failures++
+		s.stats.ConsecutiveFailures++
+		if previous := s.current.Load(); s.opts.Strategy == KeepInitial && previous != s.initial {
+			s.current.Store(s.initial)
+			s.stats.Generation++
+			change = s.change(previous, s.initial)
+		}
+	} else {
+		s.stats.ConsecutiveFailures = 0
+		s.stats.Generation++
+		previous := s.current.Swap(conf)
+		change = s.change(previous, conf)
+	}
+	failures := s.stats.ConsecutiveFailures
+	s.mu.Unlock()
+
+	if s.opts.Options.Metrics != nil {
+		s.opts.Options.Metrics.Reloaded(err)
+	}
+	if err != nil && s.opts.OnReloadError != nil {
+		s.opts.OnReloadError(err, failures)
+	}
+
+	if change != nil {
+		s.notifyMu.Lock()
+	}
+	return change, err
+}
+
+// change returns the change from the configuration previous to conf, mu must be locked.
+func (s *Store[T]) change(previous, conf *T) *Change[T] {
+	// the fields can't be compared only if a Marshaler fails, the change then has no fields
+	fields, _ := DiffWithOptions(previous, conf, s.opts.Options)
+	return &Change[T]{Generation: s.stats.Generation, Config: conf, Fields: fields}
+}
+
+// Watch reloads the configuration every interval until ctx is done, then returns ctx.Err().
+// With the StopWatching strategy, it returns the error of the first failed reload.
+// It returns ErrShutdown once the store is shut down, ctx and the shutdown cancel a running reload.
+func (s *Store[T]) Watch(ctx context.Context, interval time.Duration) error {
+	loopCtx, release, err := s.lifecycle.Start(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-loopCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return ErrShutdown
+		case <-ticker.C:
+			if err := s.reload(loopCtx); err != nil && loopCtx.Err() == nil && s.opts.Strategy == StopWatching {
+				return err
+			}
+		}
+	}
+}
+
+// Shutdown stops the calls to Watch and Reload and waits for them to return, or for ctx to be done.
+// Watch and Reload can't be called anymore once the store is shut down, but the store keeps serving its configuration.
+// The channels of the subscriptions are closed and the functions of OnFieldChange are unregistered.
+func (s *Store[T]) Shutdown(ctx context.Context) error {
+	s.subMu.Lock()
+	subscriptions := s.subscriptions
+	s.subscriptions, s.fieldHooks, s.closed = nil, nil, true
+	s.subMu.Unlock()
+
+	for _, sub := range subscriptions {
+		sub.close()
+	}
+
+	return s.lifecycle.Shutdown(ctx)
+}
+
+// ErrShutdown is the error returned by Watch and Reload once the store is shut down.
+var ErrShutdown = lifecycle.ErrShutdown
@@ -0,0 +1,290 @@
+package envconfig_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+type storeConfig struct {
+	Name string
+	Port int
+}
+
+func TestStore(t *testing.T) {
+	source := envconfig.MapSource{"NAME": "foo", "PORT": "80"}
+
+	var (
+		reloadErr error
+		failures  int
+	)
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: source},
+		OnReloadError: func(err error, n int) {
+			reloadErr, failures = err, n
+		},
+	})
+	require.Nil(t, err)
+	require.Equal(t, &storeConfig{Name: "foo", Port: 80}, store.Get())
+
+	source["NAME"] = "bar"
+	require.Nil(t, store.Reload())
+	require.Equal(t, &storeConfig{Name: "bar", Port: 80}, store.Get())
+
+	source["PORT"] = "foobar"
+	err = store.Reload()
+	require.NotNil(t, err)
+	require.Equal(t, err, reloadErr)
+	require.Equal(t, 1, failures)
+	require.Equal(t, &storeConfig{Name: "bar", Port: 80}, store.Get())
+
+	stats := store.Stats()
+	require.Equal(t, uint64(2), stats.Generation)
+	require.Equal(t, uint64(2), stats.Reloads)
+	require.Equal(t, uint64(1), stats.Failures)
+	require.Equal(t, err, stats.LastError)
+
+	source["PORT"] = "81"
+	require.Nil(t, store.Reload())
+	require.Equal(t, 0, store.Stats().ConsecutiveFailures)
+	require.Nil(t, store.Stats().LastError)
+}
+
+func TestStoreHooksCallStore(t *testing.T) {
+	source := envconfig.MapSource{"NAME": "foo", "PORT": "80"}
+
+	var (
+		store      *envconfig.Store[storeConfig]
+		errorStats envconfig.StoreStats
+		loadStats  envconfig.StoreStats
+	)
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{
+			Source: source,
+			OnLoad: func(*envconfig.Report) {
+				if store != nil {
+					loadStats = store.Stats()
+				}
+			},
+		},
+		OnReloadError: func(error, int) {
+			errorStats = store.Stats()
+		},
+	})
+	require.Nil(t, err)
+	store.AddCanary(func(candidate storeConfig) error {
+		store.Stats()
+		return nil
+	})
+
+	done := make(chan error)
+	go func() {
+		source["PORT"] = "foobar"
+		done <- store.Reload()
+	}()
+	select {
+	case err := <-done:
+		require.NotNil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Reload is deadlocked")
+	}
+	require.Equal(t, 1, errorStats.ConsecutiveFailures)
+
+	source["PORT"] = "81"
+	require.Nil(t, store.Reload())
+	require.Equal(t, uint64(1), loadStats.Generation)
+}
+
+func TestStoreKeepInitial(t *testing.T) {
+	source := envconfig.MapSource{"NAME": "foo", "PORT": "80"}
+
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options:  envconfig.Options{Source: source},
+		Strategy: envconfig.KeepInitial,
+	})
+	require.Nil(t, err)
+
+	source["NAME"] = "bar"
+	require.Nil(t, store.Reload())
+
+	source["NAME"] = ""
+	require.NotNil(t, store.Reload())
+	require.Equal(t, &storeConfig{Name: "foo", Port: 80}, store.Get())
+}
+
+func TestStoreWatch(t *testing.T) {
+	source := envconfig.MapSource{"NAME": "foo", "PORT": "80"}
+
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options:  envconfig.Options{Source: source},
+		Strategy: envconfig.StopWatching,
+	})
+	require.Nil(t, err)
+
+	source["PORT"] = "foobar"
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err = store.Watch(ctx, time.Millisecond)
+	var parseErr *envconfig.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, &storeConfig{Name: "foo", Port: 80}, store.Get())
+
+	_, err = envconfig.NewStore[storeConfig](envconfig.StoreOptions{Options: envconfig.Options{Source: source}})
+	require.NotNil(t, err)
+}
+
+// requireNoLeak fails the test if there are still more than n goroutines after a second.
+// It doesn't use require.Eventually, which runs its condition in another goroutine.
+func requireNoLeak(t *testing.T, n int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines left, expected %d:\n%s", runtime.NumGoroutine(), n, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStoreShutdown(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: envconfig.MapSource{"NAME": "foo", "PORT": "80"}},
+	})
+	require.Nil(t, err)
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- store.Watch(context.Background(), time.Millisecond) }()
+	}
+	require.Eventually(t, func() bool { return store.Stats().Reloads > 0 }, time.Second, time.Millisecond)
+
+	err = store.Shutdown(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, envconfig.ErrShutdown, <-done)
+	require.Equal(t, envconfig.ErrShutdown, <-done)
+
+	// Watch doesn't start anymore, and the store keeps serving its configuration
+	require.Equal(t, envconfig.ErrShutdown, store.Watch(context.Background(), time.Millisecond))
+	require.Equal(t, &storeConfig{Name: "foo", Port: 80}, store.Get())
+
+	requireNoLeak(t, goroutines)
+}
+
+func TestStoreShutdownTimeout(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: envconfig.MapSource{"NAME": "foo", "PORT": "80"}},
+	})
+	require.Nil(t, err)
+
+	reloading, block := make(chan struct{}, 1), make(chan struct{})
+	store.AddCanary(func(storeConfig) error {
+		select {
+		case reloading <- struct{}{}:
+		default:
+		}
+		<-block
+		return nil
+	})
+	go store.Watch(context.Background(), time.Millisecond)
+	<-reloading
+
+	// Watch is stuck in a reload
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = store.Shutdown(ctx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// Watch returns once the reload is over
+	close(block)
+	requireNoLeak(t, goroutines)
+}
+
+// blockingSource blocks the lookups once block is set, until their context is done.
+type blockingSource struct {
+	envconfig.MapSource
+	block   chan struct{}
+	blocked chan struct{}
+}
+
+func (s *blockingSource) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	select {
+	case <-s.block:
+	default:
+		s.blocked <- struct{}{}
+		<-ctx.Done()
+		return "", false, ctx.Err()
+	}
+	return s.Lookup(key)
+}
+
+func TestStoreShutdownReload(t *testing.T) {
+	source := &blockingSource{
+		MapSource: envconfig.MapSource{"NAME": "foo", "PORT": "80"},
+		block:     make(chan struct{}),
+		blocked:   make(chan struct{}, 1),
+	}
+	close(source.block)
+
+	var failures int
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options:       envconfig.Options{Source: source},
+		OnReloadError: func(error, int) { failures++ },
+	})
+	require.Nil(t, err)
+
+	source.block = make(chan struct{})
+	done := make(chan error)
+	go func() { done <- store.Reload() }()
+	<-source.blocked
+
+	// the shutdown cancels the reload blocked on the source, which leaves the store untouched
+	err = store.Shutdown(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, context.Canceled, <-done)
+	require.Equal(t, 0, failures)
+	require.Equal(t, uint64(1), store.Stats().Generation)
+	require.Equal(t, uint64(0), store.Stats().Reloads)
+
+	require.Equal(t, envconfig.ErrShutdown, store.Reload())
+}
+
+func TestStoreCanary(t *testing.T) {
+	source := envconfig.MapSource{"NAME": "foo", "PORT": "80"}
+
+	store, err := envconfig.NewStore[storeConfig](envconfig.StoreOptions{
+		Options: envconfig.Options{Source: source},
+	})
+	require.Nil(t, err)
+
+	errUnreachable := errors.New("port 81 is unreachable")
+	store.AddCanary(func(candidate storeConfig) error {
+		if candidate.Port == 81 {
+			return errUnreachable
+		}
+		return nil
+	})
+
+	source["PORT"] = "81"
+	err = store.Reload()
+	require.Equal(t, "envconfig: config rejected by canary: port 81 is unreachable", err.Error())
+	require.True(t, errors.Is(err, errUnreachable))
+	require.Equal(t, 80, store.Get().Port)
+	require.Equal(t, err, store.Stats().LastError)
+
+	source["PORT"] = "82"
+	require.Nil(t, store.Reload())
+	require.Equal(t, 82, store.Get().Port)
+}
@@ -0,0 +1,112 @@
+package envconfig
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// closestKey returns the candidate closest to one of keys, ignoring the candidates equal to a key.
+// It returns an empty string if no candidate is close enough.
+func closestKey(keys, candidates []string) string {
+	var (
+		best     string
+		bestDist = -1
+	)
+	for _, key := range keys {
+		normKey := normalizeKey(key)
+
+		for _, name := range candidates {
+			if name == key {
+				continue
+			}
+
+			dist := editDistance(normKey, normalizeKey(name))
+			if dist > maxSuggestDistance(normKey) {
+				continue
+			}
+			if bestDist == -1 || dist < bestDist {
+				best, bestDist = name, dist
+			}
+		}
+	}
+
+	return best
+}
+
+// environNames returns the sorted names of the environment variables which are not empty.
+func environNames() []string {
+	var names []string
+	for _, kv := range os.Environ() {
+		i := strings.IndexByte(kv, '=')
+		if i <= 0 || i == len(kv)-1 {
+			continue
+		}
+		names = append(names, kv[:i])
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// normalizeKey removes the differences which don't matter when suggesting a key:
+// the case and the separators.
+func normalizeKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(s))
+}
+
+func maxSuggestDistance(s string) int {
+	switch {
+	case len(s) < 6:
+		return 0
+	case len(s) < 10:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the optimal string alignment distance between a and b: the number of insertions,
+// deletions, substitutions and transpositions of adjacent characters needed to change a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = d[i-1][j-1] + cost
+			if v := d[i-1][j] + 1; v < d[i][j] {
+				d[i][j] = v
+			}
+			if v := d[i][j-1] + 1; v < d[i][j] {
+				d[i][j] = v
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if v := d[i-2][j-2] + 1; v < d[i][j] {
+					d[i][j] = v
+				}
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,22 @@
+package envconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditDistance(t *testing.T) {
+	require.Equal(t, 0, editDistance("LOGPATH", "LOGPATH"))
+	require.Equal(t, 1, editDistance("DATABASEURL", "DATABSEURL"))
+	require.Equal(t, 1, editDistance("PORT", "PORTS"))
+	require.Equal(t, 3, editDistance("kitten", "sitting"))
+	require.Equal(t, 1, editDistance("APPNMAE", "APPNAME"))
+	require.Equal(t, 4, editDistance("", "NAME"))
+}
+
+func TestNormalizeKey(t *testing.T) {
+	require.Equal(t, "LOGPATH", normalizeKey("log_path"))
+	require.Equal(t, "LOGPATH", normalizeKey("Log.Path"))
+	require.Equal(t, "LOGPATH", normalizeKey("LOG-PATH"))
+}
@@ -0,0 +1,235 @@
+package envconfig
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadEnvironmentFile reads a file in the format of the EnvironmentFile= setting of systemd units
+// and returns its variables, to use as the option Source.
+//
+// It follows the rules of systemd, which differ from the ones of a shell or dotenv:
+// lines starting with # or ; are comments, a backslash at the end of a line continues it,
+// single quotes keep everything literally, double quotes only unescape \", \\, \`, \$ and line breaks,
+// and the trailing whitespaces of an unquoted value are removed. Variables are never expanded.
+func ReadEnvironmentFile(r io.Reader) (MapSource, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		preKey = iota
+		inKey
+		preValue
+		inValue
+		valueEscape
+		singleQuote
+		doubleQuote
+		doubleQuoteEscape
+		comment
+		commentEscape
+	)
+
+	var (
+		res   = make(MapSource)
+		state = preKey
+		line  = 1
+
+		key, value strings.Builder
+		keyLine    int
+		// lastKeySpace and lastValueSpace are the lengths of the key and the value before their trailing whitespaces,
+		// -1 if there are none.
+		lastKeySpace   = -1
+		lastValueSpace = -1
+	)
+
+	push := func() error {
+		k := key.String()
+		if lastKeySpace >= 0 {
+			k = k[:lastKeySpace]
+		}
+		v := value.String()
+		if lastValueSpace >= 0 {
+			v = v[:lastValueSpace]
+		}
+		key.Reset()
+		value.Reset()
+
+		if !isEnvironmentFileKey(k) {
+			return fmt.Errorf("envconfig: invalid key %q at line %d", k, keyLine)
+		}
+		res[k] = v
+
+		return nil
+	}
+
+	isSpace := func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\r'
+	}
+
+	for _, c := range string(data) {
+		switch state {
+		case preKey:
+			switch {
+			case c == '#' || c == ';':
+				state = comment
+			case !isSpace(c) && c != '\n':
+				state = inKey
+				keyLine = line
+				lastKeySpace = -1
+				key.WriteRune(c)
+			}
+
+		case inKey:
+			switch {
+			case c == '\n':
+				return nil, fmt.Errorf("envconfig: missing = at line %d", line)
+			case c == '=':
+				state = preValue
+				lastValueSpace = -1
+			case isSpace(c):
+				if lastKeySpace < 0 {
+					lastKeySpace = key.Len()
+				}
+				key.WriteRune(c)
+			default:
+				lastKeySpace = -1
+				key.WriteRune(c)
+			}
+
+		case preValue:
+			switch {
+			case c == '\n':
+				state = preKey
+				if err := push(); err != nil {
+					return nil, err
+				}
+			case c == '\'':
+				state = singleQuote
+			case c == '"':
+				state = doubleQuote
+			case c == '\\':
+				state = valueEscape
+			case !isSpace(c):
+				state = inValue
+				value.WriteRune(c)
+			}
+
+		case inValue:
+			switch {
+			case c == '\n':
+				state = preKey
+				if err := push(); err != nil {
+					return nil, err
+				}
+			case c == '\\':
+				state = valueEscape
+				lastValueSpace = -1
+			case isSpace(c):
+				if lastValueSpace < 0 {
+					lastValueSpace = value.Len()
+				}
+				value.WriteRune(c)
+			default:
+				lastValueSpace = -1
+				value.WriteRune(c)
+			}
+
+		case valueEscape:
+			state = inValue
+			if c != '\n' {
+				value.WriteRune(c)
+			}
+
+		case singleQuote:
+			if c == '\'' {
+				state = preValue
+			} else {
+				value.WriteRune(c)
+			}
+
+		case doubleQuote:
+			switch c {
+			case '"':
+				state = preValue
+			case '\\':
+				state = doubleQuoteEscape
+			default:
+				value.WriteRune(c)
+			}
+
+		case doubleQuoteEscape:
+			state = doubleQuote
+			switch {
+			case strings.ContainsRune("\"\\`$", c):
+				value.WriteRune(c)
+			case c == '\n':
+			default:
+				value.WriteRune('\\')
+				value.WriteRune(c)
+			}
+
+		case comment:
+			switch c {
+			case '\\':
+				state = commentEscape
+			case '\n':
+				state = preKey
+			}
+
+		case commentEscape:
+			state = comment
+		}
+
+		if c == '\n' {
+			line++
+		}
+	}
+
+	switch state {
+	case inKey:
+		return nil, fmt.Errorf("envconfig: missing = at line %d", line)
+	case singleQuote, doubleQuote, doubleQuoteEscape:
+		return nil, fmt.Errorf("envconfig: unterminated quoted value of %s", key.String())
+	case preValue, inValue, valueEscape:
+		if err := push(); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// isEnvironmentFileKey returns true if s is a valid variable name for systemd.
+func isEnvironmentFileKey(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteEnvironmentFile writes the values of the conf object with opts to w in the format of
+// the EnvironmentFile= setting of systemd units, see ReadEnvironmentFile. conf must be a pointer.
+// The fields left empty get their default value.
+func WriteEnvironmentFile(w io.Writer, conf interface{}, opts Options) error {
+	return writeExport(w, conf, opts, func(key, value string) string {
+		return key + "=" + quoteSystemd(value)
+	})
+}
+
+// quoteSystemd quotes the value if needed to be read back by systemd.
+func quoteSystemd(s string) string {
+	if isShellSafe(s) {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`", `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
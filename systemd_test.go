@@ -0,0 +1,68 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestReadEnvironmentFile(t *testing.T) {
+	const data = `# comment
+; another comment \
+  continued
+  NAME = foo bar   
+SINGLE='it is $HOME\n'
+DOUBLE="a \"b\" \$c \d"
+MULTI="line1
+line2"
+CONTINUED=foo\
+bar
+ESCAPED=a\ b
+CONCAT='a'"b"c
+EMPTY=
+LAST=1`
+
+	vars, err := envconfig.ReadEnvironmentFile(strings.NewReader(data))
+	require.Nil(t, err)
+	require.Equal(t, envconfig.MapSource{
+		"NAME":      "foo bar",
+		"SINGLE":    `it is $HOME\n`,
+		"DOUBLE":    `a "b" $c \d`,
+		"MULTI":     "line1\nline2",
+		"CONTINUED": "foobar",
+		"ESCAPED":   "a b",
+		"CONCAT":    "abc",
+		"EMPTY":     "",
+		"LAST":      "1",
+	}, vars)
+
+	_, err = envconfig.ReadEnvironmentFile(strings.NewReader("FOO\nBAR=1"))
+	require.Equal(t, "envconfig: missing = at line 1", err.Error())
+
+	_, err = envconfig.ReadEnvironmentFile(strings.NewReader("A=1\nFOO-BAR=1"))
+	require.Equal(t, `envconfig: invalid key "FOO-BAR" at line 2`, err.Error())
+
+	_, err = envconfig.ReadEnvironmentFile(strings.NewReader(`FOO="bar`))
+	require.Equal(t, "envconfig: unterminated quoted value of FOO", err.Error())
+}
+
+func TestWriteEnvironmentFile(t *testing.T) {
+	conf := struct {
+		Addr  string `envconfig:"default=:8080"`
+		Name  string
+		Debug bool `envconfig:"optional"`
+	}{
+		Name: "a \"b\" $c\nd",
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteEnvironmentFile(&buf, &conf, envconfig.Options{Prefix: "APP"})
+	require.Nil(t, err)
+
+	vars, err := envconfig.ReadEnvironmentFile(&buf)
+	require.Nil(t, err)
+	require.Equal(t, envconfig.MapSource{"APP_ADDR": ":8080", "APP_NAME": conf.Name, "APP_DEBUG": "false"}, vars)
+}
@@ -0,0 +1,305 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tag is the parsed form of an envconfig struct tag.
+// It can be used by code generators to build correct tags:
+//
+//	envconfig.Tag{Name: "FOO", Default: "1", Optional: true}.String() // FOO,optional,default=1
+type Tag struct {
+	// Name is the custom name of the key, or alternative keys tried in order like ADDR|ADDRESS|HOST.
+	Name string
+	// Prefix replaces the prefix of the keys of the fields of a nested struct, and NoPrefix removes it.
+	// Prefixed, or noflatten, keeps the name of an embedded struct in the keys of its fields, which are
+	// flattened otherwise. Squash flattens the fields of any nested struct, even with Options.NoFlatten.
+	Prefix   string
+	NoPrefix bool
+	Prefixed bool
+	Squash   bool
+	// Skip is true for the tag -, the field is ignored.
+	Skip     bool
+	Optional bool
+	Secret   bool
+	// Redact is the partial redaction policy of a secret field, like url.
+	Redact string
+	Rest   bool
+	// FromFile is true if the value is the path of a file holding the actual value.
+	// Trim removes the leading and trailing whitespaces of the value and Base64 decodes it.
+	FromFile bool
+	Trim     bool
+	Base64   bool
+	// Encrypted is true if the value is decrypted by the Decryptor of the options.
+	Encrypted bool
+	// CSV is true if a slice of structs is read from CSV rows, the first row naming the fields.
+	CSV bool
+	// Serial is true if the lookups and the probe of the field never run concurrently with others, and Parallel
+	// if they may even with Options.Serial.
+	Serial   bool
+	Parallel bool
+	Default  string
+	// Duration is the format of durations, either empty or iso8601.
+	Duration string
+	Group    string
+	// Probe is the name of the probe checking the value once it is read, like dns, tcp or http.
+	Probe string
+	// Validators are the names of the validators, like iso3166 or mimetype.
+	Validators []string
+	// Deprecated is the former key of the field, still read if the field isn't set, see Options.OnDeprecated.
+	Deprecated string
+	// Example is an example value documenting the expected format, see VerifyExamples.
+	Example     string
+	Description string
+}
+
+// ParseTag parses the value of an envconfig struct tag the same way Init does.
+func ParseTag(s string) Tag {
+	var t Tag
+
+	tokens := strings.Split(s, ",")
+	for i, v := range tokens {
+		switch {
+		case strings.HasPrefix(v, "desc="):
+			// the description is always last so that it can contain commas
+			t.Description = strings.TrimPrefix(strings.Join(tokens[i:], ","), "desc=")
+			return t
+		case i == 0 && v != "-" && v != "optional" && !strings.Contains(v, "="):
+			// the first word is the name, as in the original tags, even if it is an option added since
+			t.Name = v
+		case v == "-":
+			t.Skip = true
+		case v == "optional":
+			t.Optional = true
+		case v == "secret":
+			t.Secret = true
+		case v == "rest":
+			t.Rest = true
+		case v == "fromFile":
+			t.FromFile = true
+		case v == "trim":
+			t.Trim = true
+		case v == "base64":
+			t.Base64 = true
+		case v == "encrypted":
+			t.Encrypted = true
+		case v == "noprefix":
+			t.NoPrefix = true
+		case v == "prefixed", v == "noflatten":
+			t.Prefixed = true
+		case v == "squash":
+			t.Squash = true
+		case strings.HasPrefix(v, "prefix="):
+			t.Prefix = strings.TrimPrefix(v, "prefix=")
+		case v == "csv":
+			t.CSV = true
+		case v == "serial":
+			t.Serial = true
+		case v == "parallel":
+			t.Parallel = true
+		case strings.HasPrefix(v, "default="):
+			t.Default = strings.TrimPrefix(v, "default=")
+		case strings.HasPrefix(v, "duration="):
+			t.Duration = strings.TrimPrefix(v, "duration=")
+		case strings.HasPrefix(v, "group="):
+			t.Group = strings.TrimPrefix(v, "group=")
+		case strings.HasPrefix(v, "redact="):
+			t.Redact = strings.TrimPrefix(v, "redact=")
+		case strings.HasPrefix(v, "probe="):
+			t.Probe = strings.TrimPrefix(v, "probe=")
+		case strings.HasPrefix(v, "deprecated="):
+			t.Deprecated = strings.TrimPrefix(v, "deprecated=")
+		case strings.HasPrefix(v, "example="):
+			t.Example = strings.TrimPrefix(v, "example=")
+		case validators[v] != nil:
+			t.Validators = append(t.Validators, v)
+		default:
+			t.Name = v
+		}
+	}
+
+	return t
+}
+
+// String returns the value of the struct tag. The tag must be valid, see Validate.
+func (t Tag) String() string {
+	if t.Skip {
+		return "-"
+	}
+
+	var tokens []string
+	if t.Name != "" {
+		tokens = append(tokens, t.Name)
+	}
+	if t.Prefix != "" {
+		tokens = append(tokens, "prefix="+t.Prefix)
+	}
+	if t.NoPrefix {
+		tokens = append(tokens, "noprefix")
+	}
+	if t.Prefixed {
+		tokens = append(tokens, "prefixed")
+	}
+	if t.Squash {
+		tokens = append(tokens, "squash")
+	}
+	if t.Optional {
+		tokens = append(tokens, "optional")
+	}
+	if t.Secret {
+		tokens = append(tokens, "secret")
+	}
+	if t.Redact != "" {
+		tokens = append(tokens, "redact="+t.Redact)
+	}
+	if t.Rest {
+		tokens = append(tokens, "rest")
+	}
+	if t.FromFile {
+		tokens = append(tokens, "fromFile")
+	}
+	if t.Trim {
+		tokens = append(tokens, "trim")
+	}
+	if t.Base64 {
+		tokens = append(tokens, "base64")
+	}
+	if t.Encrypted {
+		tokens = append(tokens, "encrypted")
+	}
+	if t.CSV {
+		tokens = append(tokens, "csv")
+	}
+	if t.Serial {
+		tokens = append(tokens, "serial")
+	}
+	if t.Parallel {
+		tokens = append(tokens, "parallel")
+	}
+	if t.Default != "" {
+		tokens = append(tokens, "default="+t.Default)
+	}
+	if t.Duration != "" {
+		tokens = append(tokens, "duration="+t.Duration)
+	}
+	if t.Group != "" {
+		tokens = append(tokens, "group="+t.Group)
+	}
+	if t.Probe != "" {
+		tokens = append(tokens, "probe="+t.Probe)
+	}
+	tokens = append(tokens, t.Validators...)
+	if t.Deprecated != "" {
+		tokens = append(tokens, "deprecated="+t.Deprecated)
+	}
+	if t.Example != "" {
+		tokens = append(tokens, "example="+t.Example)
+	}
+	if t.Description != "" {
+		tokens = append(tokens, "desc="+t.Description)
+	}
+	if t.Name == "" && len(tokens) > 0 && ParseTag(tokens[0]).Name != "" {
+		// an option like secret would be read as the name
+		tokens = append([]string{""}, tokens...)
+	}
+
+	return strings.Join(tokens, ",")
+}
+
+// StructTag returns the complete struct tag, like `envconfig:"FOO,optional"`.
+func (t Tag) StructTag() string {
+	return "envconfig:" + strconv.Quote(t.String())
+}
+
+// Validate returns an error if the tag can't be represented as a string and parsed back by ParseTag,
+// for example if the default value contains a comma.
+func (t Tag) Validate() error {
+	for _, v := range []struct{ field, value string }{{"name", t.Name}, {"prefix", t.Prefix}, {"default value", t.Default}, {"group", t.Group}, {"deprecated key", t.Deprecated}, {"example", t.Example}} {
+		if strings.Contains(v.value, ",") {
+			return fmt.Errorf("envconfig: invalid tag %s %q, it contains a comma", v.field, v.value)
+		}
+	}
+
+	if t.Name != "" && ParseTag(t.Name).Name != t.Name {
+		return fmt.Errorf("envconfig: invalid tag name %q, it is an option", t.Name)
+	}
+	if t.Name != "" && strings.Contains("|"+t.Name+"|", "||") {
+		return fmt.Errorf("envconfig: invalid tag name %q, it has an empty alternative", t.Name)
+	}
+
+	if t.Squash && t.Prefixed {
+		return errors.New("envconfig: invalid tag, the squash and prefixed options are exclusive")
+	}
+	if t.Serial && t.Parallel {
+		return errors.New("envconfig: invalid tag, the serial and parallel options are exclusive")
+	}
+
+	switch t.Duration {
+	case durationFormatGo, durationFormatISO8601:
+	default:
+		return fmt.Errorf("envconfig: invalid tag duration format %q", t.Duration)
+	}
+
+	if t.Redact != "" && redactors[t.Redact] == nil {
+		return fmt.Errorf("envconfig: unknown tag redaction policy %q", t.Redact)
+	}
+
+	if t.Probe != "" && probers[t.Probe] == nil {
+		return fmt.Errorf("envconfig: unknown tag probe %q", t.Probe)
+	}
+
+	for _, name := range t.Validators {
+		if validators[name] == nil {
+			return fmt.Errorf("envconfig: unknown tag validator %q", name)
+		}
+	}
+
+	return nil
+}
+
+// override returns the tag with the options of o: the options set in o replace those of t.
+func (t Tag) override(o Tag) Tag {
+	t.Skip = t.Skip || o.Skip
+	t.Optional = t.Optional || o.Optional
+	t.Secret = t.Secret || o.Secret
+	t.Rest = t.Rest || o.Rest
+	t.FromFile = t.FromFile || o.FromFile
+	t.Trim = t.Trim || o.Trim
+	t.Base64 = t.Base64 || o.Base64
+	t.Encrypted = t.Encrypted || o.Encrypted
+	t.CSV = t.CSV || o.CSV
+	if o.Serial || o.Parallel {
+		t.Serial, t.Parallel = o.Serial, o.Parallel
+	}
+	t.NoPrefix = t.NoPrefix || o.NoPrefix
+	t.Prefixed = t.Prefixed || o.Prefixed
+	t.Squash = t.Squash || o.Squash
+
+	for _, v := range []struct {
+		dst *string
+		src string
+	}{
+		{&t.Name, o.Name},
+		{&t.Prefix, o.Prefix},
+		{&t.Redact, o.Redact},
+		{&t.Default, o.Default},
+		{&t.Duration, o.Duration},
+		{&t.Group, o.Group},
+		{&t.Probe, o.Probe},
+		{&t.Deprecated, o.Deprecated},
+		{&t.Example, o.Example},
+		{&t.Description, o.Description},
+	} {
+		if v.src != "" {
+			*v.dst = v.src
+		}
+	}
+	if len(o.Validators) > 0 {
+		t.Validators = o.Validators
+	}
+
+	return t
+}
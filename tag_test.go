@@ -0,0 +1,94 @@
+package envconfig_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestTag(t *testing.T) {
+	tag := envconfig.Tag{Name: "FOO", Default: "1", Optional: true}
+	require.Nil(t, tag.Validate())
+	require.Equal(t, "FOO,optional,default=1", tag.String())
+	require.Equal(t, `envconfig:"FOO,optional,default=1"`, tag.StructTag())
+
+	tag = envconfig.Tag{
+		Secret:      true,
+		Encrypted:   true,
+		CSV:         true,
+		Duration:    "iso8601",
+		Group:       "auth",
+		Probe:       "tcp",
+		Validators:  []string{"iso3166"},
+		Deprecated:  "COUNTRY_CODE",
+		Example:     "FR",
+		Description: `Country, like "FR"`,
+	}
+	require.Nil(t, tag.Validate())
+	require.Equal(t, tag, envconfig.ParseTag(tag.String()))
+	require.Equal(t, `Country, like "FR"`, envconfig.ParseTag(reflect.StructTag(tag.StructTag()).Get("envconfig")).Description)
+
+	tag = envconfig.Tag{Prefix: "DB", Optional: true}
+	require.Equal(t, "prefix=DB,optional", tag.String())
+	require.Equal(t, tag, envconfig.ParseTag(tag.String()))
+	require.Equal(t, ",noprefix", envconfig.Tag{NoPrefix: true}.String())
+	require.Equal(t, ",prefixed", envconfig.Tag{Prefixed: true}.String())
+	require.Equal(t, ",squash", envconfig.Tag{Squash: true}.String())
+	require.Equal(t, ",serial", envconfig.Tag{Serial: true}.String())
+	require.Equal(t, envconfig.Tag{Parallel: true}, envconfig.ParseTag(",parallel"))
+	require.Equal(t, envconfig.Tag{Prefixed: true}, envconfig.ParseTag(",noflatten"))
+
+	require.Equal(t, "-", envconfig.Tag{Skip: true, Name: "FOO"}.String())
+}
+
+func TestTagValidate(t *testing.T) {
+	testCases := []struct {
+		tag envconfig.Tag
+		err string
+	}{
+		{envconfig.Tag{Name: "optional"}, `envconfig: invalid tag name "optional", it is an option`},
+		{envconfig.Tag{Name: "default=1"}, `envconfig: invalid tag name "default=1", it is an option`},
+		{envconfig.Tag{Name: "A,B"}, `envconfig: invalid tag name "A,B", it contains a comma`},
+		{envconfig.Tag{Name: "A||B"}, `envconfig: invalid tag name "A||B", it has an empty alternative`},
+		{envconfig.Tag{Default: "a,b"}, `envconfig: invalid tag default value "a,b", it contains a comma`},
+		{envconfig.Tag{Example: "a,b"}, `envconfig: invalid tag example "a,b", it contains a comma`},
+		{envconfig.Tag{Squash: true, Prefixed: true}, `envconfig: invalid tag, the squash and prefixed options are exclusive`},
+		{envconfig.Tag{Serial: true, Parallel: true}, `envconfig: invalid tag, the serial and parallel options are exclusive`},
+		{envconfig.Tag{Duration: "rfc3339"}, `envconfig: invalid tag duration format "rfc3339"`},
+		{envconfig.Tag{Probe: "icmp"}, `envconfig: unknown tag probe "icmp"`},
+		{envconfig.Tag{Validators: []string{"email"}}, `envconfig: unknown tag validator "email"`},
+	}
+
+	for _, tc := range testCases {
+		err := tc.tag.Validate()
+		require.NotNil(t, err)
+		require.Equal(t, tc.err, err.Error())
+	}
+}
+
+func TestTagKeywordName(t *testing.T) {
+	require.Equal(t, envconfig.Tag{Name: "secret"}, envconfig.ParseTag("secret"))
+	require.Equal(t, envconfig.Tag{Name: "rest", Optional: true}, envconfig.ParseTag("rest,optional"))
+	require.Equal(t, envconfig.Tag{Secret: true, Rest: true}, envconfig.ParseTag(",secret,rest"))
+	require.Equal(t, envconfig.Tag{Optional: true, Secret: true}, envconfig.ParseTag("optional,secret"))
+
+	tag := envconfig.Tag{Secret: true, Validators: []string{"iso3166"}}
+	require.Equal(t, ",secret,iso3166", tag.String())
+	require.Equal(t, tag, envconfig.ParseTag(tag.String()))
+
+	tag = envconfig.Tag{Name: "trim"}
+	require.Nil(t, tag.Validate())
+	require.Equal(t, tag, envconfig.ParseTag(tag.String()))
+
+	// the first word of a tag is the name of the key even if it is an option
+	var conf struct {
+		Password string `envconfig:"secret"`
+		Extra    string `envconfig:"rest"`
+	}
+	err := envconfig.InitWithOptions(&conf, envconfig.Options{Source: envconfig.MapSource{"secret": "foo", "rest": "bar"}})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Password)
+	require.Equal(t, "bar", conf.Extra)
+}
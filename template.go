@@ -0,0 +1,39 @@
+package envconfig
+
+import (
+	"text/template"
+)
+
+// TemplateFuncs returns functions for text/template and html/template implementing the naming rules of Init,
+// so that generators of config structs, documentation or deployment files derive exactly the same keys.
+//
+// A field is identified by its path, the names of its parent fields and its own name separated with dots,
+// starting with the prefix if any, like APP.MySQL.Address. tag is the value of its envconfig struct tag.
+//
+//	envKey path tag          the canonical key of the field, like APP_MYSQL_ADDRESS
+//	envKeys path tag         all the keys looked up for the field, sorted
+//	envTag tag               the parsed tag, see Tag
+//	envDefault tag           the default value of the field
+//	envRequired tag          whether the field must be set, ignoring Options.AllOptional
+//	envDescription tag       the description of the field
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"envKey": func(path, tag string) string {
+			return canonicalKey(&fieldContext{name: path, customName: ParseTag(tag).Name})
+		},
+		"envKeys": func(path, tag string) []string {
+			return makeAllPossibleKeys(&fieldContext{name: path, customName: ParseTag(tag).Name})
+		},
+		"envTag": ParseTag,
+		"envDefault": func(tag string) string {
+			return ParseTag(tag).Default
+		},
+		"envRequired": func(tag string) bool {
+			t := ParseTag(tag)
+			return !t.Optional && !t.Rest && !t.Skip && t.Default == "" && t.Group == ""
+		},
+		"envDescription": func(tag string) string {
+			return ParseTag(tag).Description
+		},
+	}
+}
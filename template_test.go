@@ -0,0 +1,29 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl := template.Must(template.New("").Funcs(envconfig.TemplateFuncs()).Parse(
+		`{{ range . }}{{ envKey .Path .Tag }} {{ envKeys .Path .Tag }} {{ envDefault .Tag }} {{ envRequired .Tag }} {{ envDescription .Tag }}
+{{ end }}`))
+
+	fields := []struct{ Path, Tag string }{
+		{"APP.MySQL.Address", "default=localhost,desc=Address of MySQL, like localhost"},
+		{"APP.Name", ""},
+		{"APP.Token", "API_TOKEN,optional"},
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, fields)
+	require.Nil(t, err)
+	require.Equal(t, "APP_MYSQL_ADDRESS [APP_MYSQL_ADDRESS APP_MY_SQL_ADDRESS app_my_sql_address app_mysql_address] localhost false Address of MySQL, like localhost\n"+
+		"APP_NAME [APP_NAME app_name]  true \n"+
+		"API_TOKEN [API_TOKEN]  false \n", buf.String())
+}
@@ -0,0 +1,59 @@
+package envconfig
+
+// TraceKind is the kind of a TraceEvent.
+type TraceKind string
+
+// The kinds of trace events.
+const (
+	// TraceLookup is the lookup of a key of a field in the source.
+	TraceLookup TraceKind = "lookup"
+	// TraceResolve is the resolution of the value of a field, once its keys were looked up.
+	TraceResolve TraceKind = "resolve"
+)
+
+// TraceEvent is a step of the resolution of a field, see Options.Trace.
+type TraceEvent struct {
+	Kind TraceKind
+	// Field is the path of the field in the struct, like Log.Level.
+	Field string
+	// Key is the key looked up, or the key which provided the value of the field. It is empty
+	// when the field resolves to its default value.
+	Key string
+	// Source is the name of the source which provided the value, the one of the member of a Chain
+	// rather than the Chain itself. It is empty if the key isn't set.
+	Source string
+	// Found is set for a lookup if the key is set.
+	Found bool
+	// Origin is where the value of a resolved field comes from.
+	Origin FieldOrigin
+	// Value is the value found or resolved, *** or redacted by the redact option for a secret field.
+	Value  string
+	Secret bool
+	// Err is the error of the lookup, or the error of the field if it can't be resolved,
+	// like a *MissingKeyError or a *ParseError.
+	Err error
+}
+
+// traceLookup traces the lookup of the key of the field, which returned str and err.
+func (ctx *fieldContext) traceLookup(key, str string, err error) {
+	if ctx.opts.Trace == nil {
+		return
+	}
+
+	ev := TraceEvent{Kind: TraceLookup, Field: ctx.path, Key: key, Found: str != "", Secret: ctx.secret, Err: err}
+	if ev.Found {
+		if ctx.origin != nil {
+			ev.Source = sourceName(ctx.origin)
+		}
+		ev.Value = maskValue(ctx.secret, ctx.redact, str)
+	}
+	ctx.opts.Trace(ev)
+}
+
+// traceError traces the failure of the resolution of the field: its value is missing or can't be used.
+func (ctx *fieldContext) traceError(err error) {
+	if ctx.opts.Trace == nil {
+		return
+	}
+	ctx.opts.Trace(TraceEvent{Kind: TraceResolve, Field: ctx.path, Secret: ctx.secret, Err: err})
+}
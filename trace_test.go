@@ -0,0 +1,51 @@
+package envconfig_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestTrace(t *testing.T) {
+	var conf struct {
+		Name     string
+		Timeout  string `envconfig:"default=10s"`
+		Password string `envconfig:",secret"`
+		Port     int
+	}
+
+	var events []envconfig.TraceEvent
+	opts := envconfig.Options{
+		Prefix: "APP",
+		Source: envconfig.Chain{
+			envconfig.MapSource{"APP_NAME": "foo"},
+			envconfig.MapSource{"app_password": "foobar", "APP_PORT": "http"},
+		},
+		Trace: func(ev envconfig.TraceEvent) {
+			events = append(events, ev)
+		},
+	}
+
+	err := envconfig.InitWithOptions(&conf, opts)
+	require.NotNil(t, err)
+
+	var parseErr *envconfig.ParseError
+	require.Len(t, events, 10)
+	require.True(t, errors.As(events[9].Err, &parseErr))
+	events[9].Err = nil
+
+	require.Equal(t, []envconfig.TraceEvent{
+		{Kind: envconfig.TraceLookup, Field: "Name", Key: "APP_NAME", Found: true, Source: "envconfig.MapSource", Value: "foo"},
+		{Kind: envconfig.TraceResolve, Field: "Name", Key: "APP_NAME", Source: "envconfig.MapSource", Origin: envconfig.FromSource, Value: "foo"},
+		{Kind: envconfig.TraceLookup, Field: "Timeout", Key: "APP_TIMEOUT"},
+		{Kind: envconfig.TraceLookup, Field: "Timeout", Key: "app_timeout"},
+		{Kind: envconfig.TraceResolve, Field: "Timeout", Origin: envconfig.FromDefault, Value: "10s"},
+		{Kind: envconfig.TraceLookup, Field: "Password", Key: "APP_PASSWORD", Secret: true},
+		{Kind: envconfig.TraceLookup, Field: "Password", Key: "app_password", Found: true, Source: "envconfig.MapSource", Value: "***", Secret: true},
+		{Kind: envconfig.TraceResolve, Field: "Password", Key: "app_password", Source: "envconfig.MapSource", Origin: envconfig.FromSource, Value: "***", Secret: true},
+		{Kind: envconfig.TraceLookup, Field: "Port", Key: "APP_PORT", Found: true, Source: "envconfig.MapSource", Value: "http"},
+		{Kind: envconfig.TraceResolve, Field: "Port"},
+	}, events)
+}
@@ -0,0 +1,53 @@
+package envconfig
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Usage prints the keys expected by Init for the conf object to the standard error, along with
+// their type, default value, whether they are optional and their description. conf must be a pointer.
+func Usage(conf interface{}) error {
+	return WriteUsage(os.Stderr, conf, Options{})
+}
+
+// WriteUsage writes the keys expected by InitWithOptions for the conf object and opts to w,
+// along with their type, default value, whether they are optional and their description. conf must be a pointer.
+func WriteUsage(w io.Writer, conf interface{}, opts Options) error {
+	fields, err := describe(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "KEY\tTYPE\tDEFAULT\tOPTIONAL\tDESCRIPTION")
+	for _, f := range fields {
+		optional := "no"
+		switch {
+		case f.optional || f.defaultVal != "":
+			optional = "yes"
+		case f.group != "":
+			optional = "group " + f.group
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.key, f.typ, f.defaultVal, optional, f.documentation())
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// remove the padding of the last column when there is no description
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if _, err := io.WriteString(w, strings.TrimRight(line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
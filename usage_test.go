@@ -0,0 +1,38 @@
+package envconfig_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestWriteUsage(t *testing.T) {
+	var conf struct {
+		MySQL *struct {
+			Master struct {
+				Address string `envconfig:"default=localhost"`
+			}
+		} `envconfig:"optional"`
+		Session envconfig.CookieConfig `envconfig:"-"`
+		Window  *envconfig.Window
+		Extra   map[string]string `envconfig:",rest"`
+	}
+
+	var buf bytes.Buffer
+	err := envconfig.WriteUsage(&buf, &conf, envconfig.Options{Prefix: "APP"})
+	require.Nil(t, err)
+	require.Equal(t, `KEY                       TYPE               DEFAULT    OPTIONAL  DESCRIPTION
+APP_MYSQL_MASTER_ADDRESS  string             localhost  yes
+APP_WINDOW                envconfig.Window              no        e.g. Mon-Fri 09:00-17:00 Europe/Paris
+APP_*                     map[string]string             yes
+`, buf.String())
+
+	var conf2 struct {
+		name string
+	}
+	err = envconfig.WriteUsage(&buf, &conf2, envconfig.Options{})
+	require.True(t, errors.Is(err, envconfig.ErrUnexportedField))
+}
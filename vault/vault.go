@@ -0,0 +1,254 @@
+// Package vault provides an envconfig source reading the values from the KV secrets engine of HashiCorp Vault.
+//
+// By default the value of a key is the field of the same name of the secret at Config.Path:
+//
+//	source, err := vault.New(vault.Config{
+//		Address: "https://vault:8200",
+//		Token:   token,
+//		Path:    "myapp",
+//	})
+//	...
+//	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+//
+// A field can be mapped to another secret, and to another field of the secret, with the vault tag:
+//
+//	var conf struct {
+//		DBPassword string `vault:"databases/main#password"`
+//		APIKey     string `vault:"shared/api"`
+//	}
+//
+// The source only talks to the HTTP API of Vault, it doesn't depend on the Vault client.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Config is the configuration of a Source.
+type Config struct {
+	// Address is the address of the Vault server, like https://vault:8200.
+	Address string
+	// Namespace is the Vault Enterprise namespace, if any.
+	Namespace string
+
+	// Token is the token used to authenticate. If it is empty, the source logs in with AppRole
+	// using RoleID and SecretID.
+	Token    string
+	RoleID   string
+	SecretID string
+	// AppRoleMount is the mount path of the AppRole auth method, approle by default.
+	AppRoleMount string
+
+	// Mount is the mount path of the KV secrets engine, secret by default.
+	Mount string
+	// KVVersion is the version of the KV secrets engine, 1 or 2. It is 2 by default.
+	KVVersion int
+	// Path is the path of the secret holding the keys of the fields without a vault tag.
+	Path string
+
+	// HTTPClient is the client used to talk to Vault, http.DefaultClient by default.
+	HTTPClient *http.Client
+}
+
+// Source reads the values from Vault. It implements envconfig.Source, envconfig.FieldSource and envconfig.ContextSource.
+type Source struct {
+	cfg Config
+
+	mu    sync.Mutex
+	token string
+}
+
+// New returns a new source. It logs in with AppRole if there is no token.
+func New(cfg Config) (*Source, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("vault: no address")
+	}
+	if cfg.Token == "" && (cfg.RoleID == "" || cfg.SecretID == "") {
+		return nil, errors.New("vault: no token nor AppRole credentials")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.KVVersion == 0 {
+		cfg.KVVersion = 2
+	}
+	if cfg.KVVersion != 1 && cfg.KVVersion != 2 {
+		return nil, fmt.Errorf("vault: invalid KV version %d", cfg.KVVersion)
+	}
+	if cfg.AppRoleMount == "" {
+		cfg.AppRoleMount = "approle"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	s := &Source{cfg: cfg, token: cfg.Token}
+	if s.token == "" {
+		if err := s.login(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// String returns the name of the source used in errors.
+func (s *Source) String() string {
+	return "vault " + s.cfg.Address
+}
+
+// Lookup implements envconfig.Source, it reads the field key of the secret at Config.Path.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, "")
+}
+
+// LookupField implements envconfig.FieldSource, it reads the secret of the vault tag if there is one.
+func (s *Source) LookupField(key string, tag reflect.StructTag) (string, bool, error) {
+	return s.LookupContext(context.Background(), key, tag)
+}
+
+// LookupContext implements envconfig.ContextSource, it is LookupField aborting the request when ctx is done.
+func (s *Source) LookupContext(ctx context.Context, key string, tag reflect.StructTag) (string, bool, error) {
+	mapping, ok := tag.Lookup("vault")
+	if !ok {
+		if s.cfg.Path == "" {
+			return "", false, nil
+		}
+		return s.read(ctx, s.cfg.Path, key)
+	}
+
+	path, field := mapping, key
+	if i := strings.IndexByte(mapping, '#'); i >= 0 {
+		path, field = mapping[:i], mapping[i+1:]
+	}
+
+	return s.read(ctx, path, field)
+}
+
+// read returns the field of the secret at path.
+func (s *Source) read(ctx context.Context, path, field string) (string, bool, error) {
+	apiPath := s.cfg.Mount + "/" + strings.Trim(path, "/")
+	if s.cfg.KVVersion == 2 {
+		apiPath = s.cfg.Mount + "/data/" + strings.Trim(path, "/")
+	}
+
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	found, err := s.do(ctx, http.MethodGet, apiPath, nil, &resp)
+	if err != nil {
+		return "", false, fmt.Errorf("vault: %w", err)
+	}
+	if !found {
+		return "", false, nil
+	}
+
+	data := resp.Data
+	if s.cfg.KVVersion == 2 {
+		data, _ = resp.Data["data"].(map[string]interface{})
+	}
+
+	v, ok := data[field]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	if str, ok := v.(string); ok {
+		return str, true, nil
+	}
+
+	// numbers and booleans are formatted as JSON, which envconfig parses
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", false, err
+	}
+	return string(b), true, nil
+}
+
+// login logs in with AppRole and keeps the token.
+func (s *Source) login() error {
+	body, err := json.Marshal(map[string]string{"role_id": s.cfg.RoleID, "secret_id": s.cfg.SecretID})
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if _, err := s.do(context.Background(), http.MethodPost, "auth/"+s.cfg.AppRoleMount+"/login", body, &resp); err != nil {
+		return fmt.Errorf("vault: AppRole login failed: %w", err)
+	}
+	if resp.Auth.ClientToken == "" {
+		return errors.New("vault: AppRole login failed: no token")
+	}
+
+	s.mu.Lock()
+	s.token = resp.Auth.ClientToken
+	s.mu.Unlock()
+
+	return nil
+}
+
+// do sends a request to the API of Vault and decodes the response in res, with the numbers as json.Number.
+// It returns false if the path doesn't exist.
+func (s *Source) do(ctx context.Context, method, path string, body []byte, res interface{}) (bool, error) {
+	u := strings.TrimSuffix(s.cfg.Address, "/") + "/v1/" + (&url.URL{Path: path}).EscapedPath()
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if s.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		var apiErr struct {
+			Errors []string `json:"errors"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &apiErr) == nil && len(apiErr.Errors) > 0 {
+			return false, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.Join(apiErr.Errors, ", "))
+		}
+		return false, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(res); err != nil {
+		return false, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+	"github.com/vrischmann/envconfig/vault"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	secrets := map[string]map[string]interface{}{
+		"/v1/secret/data/myapp":          {"NAME": "foo", "PORT": 8080},
+		"/v1/secret/data/databases/main": {"password": "secret"},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var body map[string]string
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"invalid role or secret ID"}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]string{"client_token": "token"}})
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+			return
+		}
+
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": secret}})
+	}))
+}
+
+func TestSource(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	source, err := vault.New(vault.Config{Address: srv.URL, RoleID: "role", SecretID: "secret", Path: "myapp"})
+	require.Nil(t, err)
+
+	var conf struct {
+		Name       string
+		Port       int
+		DBPassword string `vault:"databases/main#password"`
+		Debug      bool   `envconfig:"optional"`
+	}
+
+	err = envconfig.InitWithOptions(&conf, envconfig.Options{Source: source})
+	require.Nil(t, err)
+	require.Equal(t, "foo", conf.Name)
+	require.Equal(t, 8080, conf.Port)
+	require.Equal(t, "secret", conf.DBPassword)
+}
+
+func TestSourceErrors(t *testing.T) {
+	srv := newServer(t)
+	defer srv.Close()
+
+	_, err := vault.New(vault.Config{Address: srv.URL, RoleID: "role", SecretID: "wrong"})
+	require.Equal(t, "vault: AppRole login failed: POST auth/approle/login: 400 Bad Request: invalid role or secret ID", err.Error())
+
+	source, err := vault.New(vault.Config{Address: srv.URL, Token: "wrong", Path: "myapp"})
+	require.Nil(t, err)
+
+	_, _, err = source.Lookup("NAME")
+	require.Equal(t, "vault: GET secret/data/myapp: 403 Forbidden: permission denied", err.Error())
+}
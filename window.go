@@ -0,0 +1,181 @@
+package envconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a recurring weekly time window in a given time zone, like business hours.
+//
+// It is parsed from a string like this:
+//
+//	Mon-Fri 09:00-17:00 Europe/Paris
+//
+// The days are either a single day, a range of days or a list of both separated by '+', for example Mon+Wed-Thu.
+// The time zone is optional and defaults to UTC.
+//
+// If the end time is before the start time, the window spans midnight and the days are those on which the window starts.
+// The times are wall clock times, so that the window follows the daylight saving time changes of the time zone.
+//
+// The zero value is an empty window, which contains no time. It is formatted as an empty string.
+type Window struct {
+	// Days is indexed by time.Weekday.
+	Days [7]bool
+	// Start and End are offsets from midnight.
+	Start, End time.Duration
+	Location   *time.Location
+}
+
+// DocValue implements DocValuer.
+func (Window) DocValue() string {
+	return "Mon-Fri 09:00-17:00 Europe/Paris"
+}
+
+// Unmarshal implements Unmarshaler.
+func (w *Window) Unmarshal(s string) error {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		*w = Window{}
+		return nil
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return fmt.Errorf("invalid window %q, expected <days> <start>-<end> [<location>]", s)
+	}
+
+	var res Window
+
+	for _, part := range strings.Split(fields[0], "+") {
+		var from, to time.Weekday
+		var err error
+
+		if i := strings.IndexByte(part, '-'); i >= 0 {
+			if from, err = parseWeekday(part[:i]); err != nil {
+				return err
+			}
+			if to, err = parseWeekday(part[i+1:]); err != nil {
+				return err
+			}
+		} else {
+			if from, err = parseWeekday(part); err != nil {
+				return err
+			}
+			to = from
+		}
+
+		for d := from; ; d = (d + 1) % 7 {
+			res.Days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+
+	i := strings.IndexByte(fields[1], '-')
+	if i < 0 {
+		return fmt.Errorf("invalid window time range %q", fields[1])
+	}
+
+	var err error
+	if res.Start, err = parseClock(fields[1][:i]); err != nil {
+		return err
+	}
+	if res.End, err = parseClock(fields[1][i+1:]); err != nil {
+		return err
+	}
+
+	res.Location = time.UTC
+	if len(fields) == 3 {
+		if res.Location, err = time.LoadLocation(fields[2]); err != nil {
+			return err
+		}
+	}
+
+	*w = res
+
+	return nil
+}
+
+// Contains returns true if t is inside the window.
+func (w Window) Contains(t time.Time) bool {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+
+	// the wall clock time, not the time elapsed since midnight which differs on the days of the DST changes
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+
+	if w.Start < w.End {
+		return w.Days[t.Weekday()] && offset >= w.Start && offset < w.End
+	}
+
+	// the window spans midnight
+	if offset >= w.Start {
+		return w.Days[t.Weekday()]
+	}
+	return offset < w.End && w.Days[(t.Weekday()+6)%7]
+}
+
+// String returns the window in the format accepted by Unmarshal, or an empty string for an empty window.
+func (w Window) String() string {
+	var days []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if w.Days[d] {
+			days = append(days, d.String()[:3])
+		}
+	}
+	if len(days) == 0 {
+		return ""
+	}
+
+	loc := "UTC"
+	if w.Location != nil {
+		loc = w.Location.String()
+	}
+
+	return fmt.Sprintf("%s %s-%s %s", strings.Join(days, "+"), formatClock(w.Start), formatClock(w.End), loc)
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	d, ok := weekdays[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q", s)
+	}
+	return d, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+
+	h, err := strconv.Atoi(s[:i])
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	m, err := strconv.Atoi(s[i+1:])
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
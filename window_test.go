@@ -0,0 +1,76 @@
+package envconfig_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/envconfig"
+)
+
+func TestWindow(t *testing.T) {
+	var conf struct {
+		Business envconfig.Window
+		Night    envconfig.Window
+	}
+
+	os.Setenv("BUSINESS", "Mon-Fri 09:00-17:00 Europe/Paris")
+	os.Setenv("NIGHT", "Fri+Sat 22:00-06:00")
+
+	err := envconfig.Init(&conf)
+	require.Nil(t, err)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.Nil(t, err)
+
+	// 2016-08-29 is a monday
+	require.True(t, conf.Business.Contains(time.Date(2016, 8, 29, 9, 0, 0, 0, paris)))
+	require.True(t, conf.Business.Contains(time.Date(2016, 8, 29, 14, 0, 0, 0, time.UTC)))
+	require.False(t, conf.Business.Contains(time.Date(2016, 8, 29, 17, 0, 0, 0, paris)))
+	require.False(t, conf.Business.Contains(time.Date(2016, 8, 28, 12, 0, 0, 0, paris)))
+	require.Equal(t, "Mon+Tue+Wed+Thu+Fri 09:00-17:00 Europe/Paris", conf.Business.String())
+
+	require.True(t, conf.Night.Contains(time.Date(2016, 9, 2, 23, 0, 0, 0, time.UTC)))
+	require.True(t, conf.Night.Contains(time.Date(2016, 9, 4, 5, 59, 0, 0, time.UTC)))
+	require.False(t, conf.Night.Contains(time.Date(2016, 9, 4, 6, 0, 0, 0, time.UTC)))
+	require.False(t, conf.Night.Contains(time.Date(2016, 9, 2, 5, 0, 0, 0, time.UTC)))
+
+	os.Setenv("NIGHT", "Fri-Foo 22:00-06:00")
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse NIGHT (field Night): invalid weekday "Foo"`, err.Error())
+
+	os.Setenv("NIGHT", "Fri 22:00-25:00")
+	err = envconfig.Init(&conf)
+	require.Equal(t, `envconfig: unable to parse NIGHT (field Night): invalid time "25:00", expected HH:MM`, err.Error())
+
+	os.Setenv("BUSINESS", "")
+	os.Setenv("NIGHT", "")
+}
+
+func TestWindowDST(t *testing.T) {
+	var w envconfig.Window
+	require.Nil(t, w.Unmarshal("Sun 09:00-17:00 Europe/Paris"))
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.Nil(t, err)
+
+	// 2016-03-27 and 2016-10-30 are the sundays of the DST changes in Paris
+	for _, day := range []time.Time{time.Date(2016, 3, 27, 0, 0, 0, 0, paris), time.Date(2016, 10, 30, 0, 0, 0, 0, paris)} {
+		y, m, d := day.Date()
+		require.False(t, w.Contains(time.Date(y, m, d, 8, 30, 0, 0, paris)))
+		require.True(t, w.Contains(time.Date(y, m, d, 9, 30, 0, 0, paris)))
+		require.True(t, w.Contains(time.Date(y, m, d, 16, 30, 0, 0, paris)))
+		require.False(t, w.Contains(time.Date(y, m, d, 17, 30, 0, 0, paris)))
+	}
+}
+
+func TestWindowZero(t *testing.T) {
+	var w envconfig.Window
+	require.Equal(t, "", w.String())
+	require.False(t, w.Contains(time.Now()))
+
+	var parsed envconfig.Window
+	require.Nil(t, parsed.Unmarshal(w.String()))
+	require.Equal(t, w, parsed)
+}